@@ -48,6 +48,22 @@ var cmdVerify = &cobra.Command{
 			"warning", false,
 		)
 		vplogic.VerifHubScheduledShared, _ = cmd.Flags().GetBool("verifhub")
+		vplogic.VerifyExhaustiveShared, _ = cmd.Flags().GetBool("exhaustive")
+		vplogic.VerifyCheckpointShared, _ = cmd.Flags().GetString("checkpoint")
+		vplogic.VerifyResumeShared, _ = cmd.Flags().GetString("resume")
+		vplogic.VerifyCheckpointIntervalShared, _ = cmd.Flags().GetDuration("checkpoint-interval")
+		noCache, _ := cmd.Flags().GetBool("no-cache")
+		vplogic.VerifyCacheShared = !noCache
+		vplogic.VerifyCacheVersionShared = version
+		noPrune, _ := cmd.Flags().GetBool("no-prune")
+		vplogic.VerifyPruneShared = !noPrune
+		estimate, _ := cmd.Flags().GetBool("estimate")
+		if estimate {
+			err := vplogic.Estimate(args[0])
+			if err != nil {
+				cmdErrorFatal(err)
+			}
+		}
 		_, _, err := vplogic.Verify(args[0])
 		if err != nil {
 			cmdErrorFatal(err)
@@ -55,6 +71,27 @@ var cmdVerify = &cobra.Command{
 	},
 }
 
+var cmdEstimate = &cobra.Command{
+	Use:     "estimate [model.vp]",
+	Example: "  verifpal estimate examples/simple.vp",
+	Short:   "Estimate how long Verifpal model analysis will take",
+	Long: strings.Join([]string{
+		"`estimate` loads a Verifpal model from the given file path and reports the size of the search space",
+		"that active analysis starts out with for each principal, stage and phase, along with the values",
+		"that contribute the most to it, without analyzing the model.",
+	}, " "),
+	DisableFlagsInUseLine: true,
+	DisableFlagParsing:    true,
+	Args:                  cobra.ExactArgs(1),
+	Hidden:                false,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := vplogic.Estimate(args[0])
+		if err != nil {
+			cmdErrorFatal(err)
+		}
+	},
+}
+
 var cmdTranslate = &cobra.Command{
 	Use:     "translate [coq|go|pv] [model.vp]",
 	Example: "  verifpal translate coq examples/simple.vp",
@@ -169,6 +206,29 @@ var cmdJson = &cobra.Command{
 	},
 }
 
+var cmdServe = &cobra.Command{
+	Use:     "serve --stdio",
+	Example: "  verifpal serve --stdio",
+	Short:   "Serve Verifpal requests over JSON-RPC",
+	Long: strings.Join([]string{
+		"`serve` keeps Verifpal running as a JSON-RPC 2.0 server, so that editors and other tools",
+		"can send it many requests without starting a new process for each one.",
+		"Messages are framed with Content-Length headers, as in the Language Server Protocol.",
+	}, " "),
+	Args:   cobra.NoArgs,
+	Hidden: false,
+	Run: func(cmd *cobra.Command, args []string) {
+		stdio, _ := cmd.Flags().GetBool("stdio")
+		if !stdio {
+			cmdErrorFatal(fmt.Errorf("serve currently requires --stdio"))
+		}
+		err := vplogic.Serve(os.Stdin, os.Stdout)
+		if err != nil {
+			cmdErrorFatal(err)
+		}
+	},
+}
+
 var cmdFriends = &cobra.Command{
 	Use:                   "friends",
 	DisableFlagsInUseLine: true,
@@ -191,8 +251,16 @@ var cmdFriends = &cobra.Command{
 
 func main() {
 	cmdVerify.Flags().BoolP("verifhub", "", false, "Submit to VerifHub on Analysis Completion")
+	cmdVerify.Flags().BoolP("exhaustive", "", false, "Find Every Distinct Attack on Each Query")
+	cmdVerify.Flags().StringP("checkpoint", "", "", "Save Analysis Progress to File")
+	cmdVerify.Flags().StringP("resume", "", "", "Resume Analysis from Checkpoint File")
+	cmdVerify.Flags().DurationP("checkpoint-interval", "", vplogic.VerifyCheckpointIntervalShared, "Save Progress Within Each Stage This Often")
+	cmdVerify.Flags().BoolP("no-cache", "", false, "Do Not Use Cached Results")
+	cmdVerify.Flags().BoolP("no-prune", "", false, "Analyze Every Mutation Combination, Even If Equivalent (Swapped Substitutions Are Never Pruned)")
+	cmdVerify.Flags().BoolP("estimate", "", false, "Estimate Search Space Before Analysis")
+	cmdServe.Flags().BoolP("stdio", "", false, "Communicate over standard input and output")
 	cmdTranslate.AddCommand(cmdTranslateCoq, cmdTranslateGo, cmdTranslatePv)
-	rootCmd.AddCommand(cmdVerify, cmdTranslate, cmdPretty, cmdJson, cmdFriends)
+	rootCmd.AddCommand(cmdVerify, cmdEstimate, cmdTranslate, cmdPretty, cmdServe, cmdJson, cmdFriends)
 	// nolint:errcheck
 	rootCmd.Execute()
 }
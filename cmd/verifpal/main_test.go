@@ -5,7 +5,19 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"verifpal.com/cmd/vplogic"
@@ -145,6 +157,10 @@ var verifpalTests = []VerifpalTest{
 		Model:       "ringsign.vp",
 		ResultsCode: "a0",
 	},
+	{
+		Model:       "ringsign_four.vp",
+		ResultsCode: "a0",
+	},
 	{
 		Model:       "ringsign_substitute.vp",
 		ResultsCode: "a1a0a1a1",
@@ -193,6 +209,90 @@ var verifpalTests = []VerifpalTest{
 		Model:       "escore_old.vp",
 		ResultsCode: "c1c1",
 	},
+	{
+		Model:       "attacker_replay_only.vp",
+		ResultsCode: "c1a1",
+	},
+	{
+		Model:       "attacker_no_inject.vp",
+		ResultsCode: "c0a0",
+	},
+	{
+		Model:       "attacker_bounded_depth.vp",
+		ResultsCode: "c0a0",
+	},
+	{
+		Model:       "attacker_per_channel.vp",
+		ResultsCode: "a0a1",
+	},
+	{
+		Model:       "broadcast.vp",
+		ResultsCode: "c0a0a1",
+	},
+	{
+		Model:       "broadcast_equivocation.vp",
+		ResultsCode: "a0a0a1",
+	},
+	{
+		Model:       "broadcast_equivocation_split.vp",
+		ResultsCode: "a0a1",
+	},
+	{
+		Model:       "channels.vp",
+		ResultsCode: "c1c0c0c1a0a1a0a1",
+	},
+	{
+		Model:       "literals.vp",
+		ResultsCode: "c0a0",
+	},
+	{
+		Model:       "fragments.vp",
+		ResultsCode: "c0a0",
+	},
+	{
+		Model:       "fragments_reuse.vp",
+		ResultsCode: "c0a0",
+	},
+	{
+		Model:       "repeat.vp",
+		ResultsCode: "c0c1a1",
+	},
+	{
+		Model:       "dh_three_party.vp",
+		ResultsCode: "c0a0",
+	},
+	{
+		Model:       "xor.vp",
+		ResultsCode: "c1c1c0",
+	},
+	{
+		Model:       "kem.vp",
+		ResultsCode: "c0c0a1",
+	},
+	{
+		Model:       "kem_randomness.vp",
+		ResultsCode: "c0c1",
+	},
+	{
+		Model:       "commit.vp",
+		ResultsCode: "c0c1a0",
+	},
+	{
+		Model:       "zkp.vp",
+		ResultsCode: "c0a0a1",
+	},
+	{
+		Model:       "threshold.vp",
+		ResultsCode: "c0c1a0",
+	},
+	{
+		Model:       "threshold_forgery.vp",
+		ResultsCode: "c1a1",
+	},
+	{
+		Model:       "stream.vp",
+		ResultsCode: "a1",
+	},
 }
 
 func TestMain(t *testing.T) {
@@ -201,6 +301,23 @@ func TestMain(t *testing.T) {
 	}
 }
 
+func BenchmarkMain(b *testing.B) {
+	eventSinkSaved := vplogic.SetEventSink(vplogic.TerminalSink{Output: ioutil.Discard})
+	defer vplogic.SetEventSink(eventSinkSaved)
+	for _, v := range verifpalTests {
+		fileName := fmt.Sprintf("../../examples/test/%s", v.Model)
+		b.Run(strings.TrimSuffix(v.Model, ".vp"), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _, err := vplogic.Verify(fileName)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 func testModel(v VerifpalTest, t *testing.T) {
 	fileName := fmt.Sprintf("../../examples/test/%s", v.Model)
 	_, resultsCode, err := vplogic.Verify(fileName)
@@ -214,3 +331,323 @@ func testModel(v VerifpalTest, t *testing.T) {
 		)
 	}
 }
+
+func TestServe(t *testing.T) {
+	model, err := ioutil.ReadFile("../../examples/test/hmac_ok.vp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := []string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "verify", "params": {"model": %s}}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "prettyPrint", "params": {"model": %s}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "unknown", "params": {"model": %s}}`,
+	}
+	modelJSON, _ := json.Marshal(string(model))
+	input := &bytes.Buffer{}
+	for _, r := range requests {
+		body := fmt.Sprintf(r, modelJSON)
+		fmt.Fprintf(input, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	output := &bytes.Buffer{}
+	sink := vplogic.TerminalSink{Output: ioutil.Discard}
+	eventSinkSaved := vplogic.SetEventSink(sink)
+	err = vplogic.Serve(input, output)
+	if vplogic.SetEventSink(eventSinkSaved) != sink {
+		t.Error("expected the previous event sink to be restored")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	responses := map[string]map[string]json.RawMessage{}
+	progress := 0
+	reader := bufio.NewReader(output)
+	for reader.Buffered() > 0 || output.Len() > 0 {
+		header, err := textproto.NewReader(reader).ReadMIMEHeader()
+		if err != nil {
+			t.Fatal(err)
+		}
+		length, _ := strconv.Atoi(header.Get("Content-Length"))
+		body := make([]byte, length)
+		_, err = io.ReadFull(reader, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		message := map[string]json.RawMessage{}
+		err = json.Unmarshal(body, &message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := message["method"]; ok {
+			progress++
+			continue
+		}
+		responses[string(message["id"])] = message
+	}
+	if progress == 0 {
+		t.Error("no progress notifications sent during verify")
+	}
+	if !strings.Contains(string(responses["1"]["result"]), `"code":"c0a0"`) {
+		t.Errorf("unexpected verify response: %s", responses["1"]["result"])
+	}
+	if !strings.Contains(string(responses["2"]["result"]), "principal Alice") {
+		t.Errorf("unexpected prettyPrint response: %s", responses["2"]["result"])
+	}
+	if !strings.Contains(string(responses["3"]["error"]), "-32601") {
+		t.Errorf("unexpected unknown method response: %s", responses["3"]["error"])
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "verifpal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	vplogic.VerifyCheckpointShared = filepath.Join(dir, "state.bin")
+	defer func() {
+		vplogic.VerifyCheckpointShared = ""
+		vplogic.VerifyResumeShared = ""
+	}()
+	testModel(VerifpalTest{Model: "challengeresponse.vp", ResultsCode: "a0a1"}, t)
+	vplogic.VerifyResumeShared = vplogic.VerifyCheckpointShared
+	testModel(VerifpalTest{Model: "challengeresponse.vp", ResultsCode: "a0a1"}, t)
+	_, _, err = vplogic.Verify("../../examples/test/ok.vp")
+	if err == nil {
+		t.Error("expected resuming with a different model to fail")
+	}
+}
+
+// testEventSink keeps every message of the analysis log and, when cancel is
+// set, cancels the analysis as soon as a query is resolved.
+type testEventSink struct {
+	mu       sync.Mutex
+	cancel   context.CancelFunc
+	messages []string
+	pruned   int
+}
+
+func (s *testEventSink) Event(e vplogic.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch e := e.(type) {
+	case vplogic.EventQueryResolved:
+		if s.cancel != nil {
+			s.cancel()
+		}
+	case vplogic.EventMessage:
+		s.messages = append(s.messages, e.Message)
+	case vplogic.EventVerificationFinished:
+		s.pruned = e.Pruned
+	}
+}
+
+// TestPrune checks that models with known attacks are found to have them
+// whether or not equivalent mutation combinations are skipped.
+func TestPrune(t *testing.T) {
+	tests := []VerifpalTest{
+		{Model: "challengeresponse.vp", ResultsCode: "a0a1"},
+		{Model: "hmac_unguarded_alice.vp", ResultsCode: "c0a1"},
+		{Model: "pke_unguarded_bob.vp", ResultsCode: "c1a0"},
+		{Model: "unguarded_alice.vp", ResultsCode: "c0a1a1"},
+	}
+	eventSinkSaved := vplogic.SetEventSink(&testEventSink{})
+	defer vplogic.SetEventSink(eventSinkSaved)
+	defer func() {
+		vplogic.VerifyPruneShared = true
+	}()
+	for _, v := range tests {
+		for _, prune := range []bool{true, false} {
+			vplogic.VerifyPruneShared = prune
+			sink := &testEventSink{}
+			vplogic.SetEventSink(sink)
+			testModel(v, t)
+			if prune && sink.pruned == 0 {
+				t.Errorf("expected equivalent mutations of %s to be pruned", v.Model)
+			}
+			if !prune && sink.pruned != 0 {
+				t.Errorf("expected no mutations of %s to be pruned, got %d", v.Model, sink.pruned)
+			}
+		}
+	}
+}
+
+func TestCheckpointScan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "verifpal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := "../../examples/test/challengeresponse.vp"
+	src, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := vplogic.ParseModel(fileName, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intervalSaved := vplogic.VerifyCheckpointIntervalShared
+	vplogic.VerifyCheckpointShared = filepath.Join(dir, "state.bin")
+	vplogic.VerifyCheckpointIntervalShared = 0
+	defer func() {
+		vplogic.VerifyCheckpointShared = ""
+		vplogic.VerifyResumeShared = ""
+		vplogic.VerifyCheckpointIntervalShared = intervalSaved
+	}()
+	ctx, cancel := context.WithCancel(context.Background())
+	sink := &testEventSink{cancel: cancel}
+	eventSinkSaved := vplogic.SetEventSink(sink)
+	defer vplogic.SetEventSink(eventSinkSaved)
+	_, _, err = vplogic.VerifyModel(ctx, m)
+	if err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+	vplogic.VerifyResumeShared = vplogic.VerifyCheckpointShared
+	sink = &testEventSink{}
+	vplogic.SetEventSink(sink)
+	_, resultsCode, err := vplogic.VerifyModel(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resultsCode != "a0a1" {
+		t.Errorf("expected a0a1, got %s", resultsCode)
+	}
+	resumedScan := false
+	for _, message := range sink.messages {
+		if strings.HasPrefix(message, "Resuming the scan of") {
+			resumedScan = true
+		}
+	}
+	if !resumedScan {
+		t.Errorf("expected a scan to be resumed partway through, got %v", sink.messages)
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "verifpal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("XDG_CACHE_HOME", dir)
+	defer os.Unsetenv("XDG_CACHE_HOME")
+	if cacheDir, _ := os.UserCacheDir(); cacheDir != dir {
+		t.Skip("cache directory is not set by XDG_CACHE_HOME on this system")
+	}
+	vplogic.VerifyCacheShared = true
+	defer func() {
+		vplogic.VerifyCacheShared = false
+	}()
+	tests := []struct {
+		cache bool
+		hit   bool
+	}{
+		{true, false},
+		{true, true},
+		{false, false},
+	}
+	for i, test := range tests {
+		vplogic.VerifyCacheShared = test.cache
+		sink := &testEventSink{}
+		eventSinkSaved := vplogic.SetEventSink(sink)
+		testModel(VerifpalTest{Model: "pke_unguarded_alice.vp", ResultsCode: "c0a1"}, t)
+		vplogic.SetEventSink(eventSinkSaved)
+		hit := false
+		for _, message := range sink.messages {
+			if message == "Results for this model were found in the cache." {
+				hit = true
+			}
+		}
+		if hit != test.hit {
+			t.Errorf("run %d: expected cache hit to be %v, got %v", i, test.hit, hit)
+		}
+		cached, _ := filepath.Glob(filepath.Join(dir, "verifpal", "*.json"))
+		if len(cached) != 1 {
+			t.Fatalf("run %d: expected one cached result, got %d", i, len(cached))
+		}
+	}
+}
+
+func TestFragmentOrigin(t *testing.T) {
+	source := "import \"common/keys.vp\"\n" +
+		"attacker[active]\n" +
+		"use KeyGen(Alice, a, ga)\n" +
+		"principal Bob[]\n" +
+		"use Publish(Alice, Bob, ga)\n" +
+		"use Publish(Alice, Bob, ga)\n" +
+		"queries[confidentiality? a]\n"
+	m, err := vplogic.ParseModel("../../examples/test/origin.vp", []byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = vplogic.SanityCheck(m)
+	if err == nil {
+		t.Fatal("expected an error for a constant received twice")
+	}
+	if !strings.HasPrefix(err.Error(), "../../examples/test/origin.vp:6:1: in fragment publish: ") {
+		t.Errorf("expected the error to point to the second use of Publish: %v", err)
+	}
+}
+
+func TestModelJSON(t *testing.T) {
+	m, err := vplogic.ParseModel("model.vp", []byte(
+		"attacker[active]\nprincipal Alice[generates x]\nqueries[confidentiality? x]\n",
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kind := range []string{`"Kind":"principal"`, `"Kind":"generates"`, `"Kind":"confidentiality"`} {
+		if !strings.Contains(string(encoded), kind) {
+			t.Errorf("expected %s in %s", kind, encoded)
+		}
+	}
+	mm := vplogic.Model{}
+	err = json.Unmarshal(encoded, &mm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mm.Blocks[0].Principal.Expressions[0].Kind != vplogic.ExpressionKindGenerates ||
+		mm.Queries[0].Kind != vplogic.QueryKindConfidentiality {
+		t.Errorf("kinds were not decoded from %s", encoded)
+	}
+	if json.Unmarshal([]byte(`"secrecy"`), &mm.Queries[0].Kind) == nil {
+		t.Error("expected an error for an unknown query kind")
+	}
+}
+
+func TestParseIDs(t *testing.T) {
+	m, err := vplogic.ParseModel("model.vp", []byte(
+		"attacker[active]\nprincipal Alice[generates x\nh = HASH(x)]\n"+
+			"queries[confidentiality? x\nconfidentiality? h]\n",
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, q := range m.Queries {
+		if q.ID != i {
+			t.Errorf("expected query %d to have ID %d, got %d", i, i, q.ID)
+		}
+	}
+	h := m.Blocks[0].Principal.Expressions[1].Right.Primitive
+	if h.ID != vplogic.PrimitiveID("HASH") || h.ID < 0 {
+		t.Errorf("expected HASH to have ID %d, got %d", vplogic.PrimitiveID("HASH"), h.ID)
+	}
+}
+
+func TestParseContextualKeywords(t *testing.T) {
+	m, err := vplogic.ParseModel("model.vp", []byte(
+		"attacker[active]\nprincipal Use[generates commit, open, import\n"+
+			"c = COMMIT(commit, open)]\nprincipal Repeat[knows public fragment, use, repeat]\n"+
+			"Use -> Repeat: c\nqueries[confidentiality? commit]\n",
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Blocks[0].Principal.Name != "Use" || m.Blocks[1].Principal.Name != "Repeat" {
+		t.Errorf("expected principals Use and Repeat, got %v", m.Blocks)
+	}
+}
@@ -11,12 +11,16 @@ import (
 var attackerStateShared AttackerState
 var attackerStateMutex sync.Mutex
 
-func attackerStateInit(active bool) {
+func attackerStateInit(m Model, active bool) {
 	attackerStateMutex.Lock()
 	attackerStateShared = AttackerState{
 		Active:       active,
+		Profile:      m.Attacker,
+		Depth:        m.AttackerDepth,
+		Channels:     m.AttackerChannels,
 		CurrentPhase: 0,
 		Known:        []Value{},
+		knownIndex:   &sync.Map{},
 	}
 	attackerStateMutex.Unlock()
 }
@@ -24,7 +28,7 @@ func attackerStateInit(active bool) {
 func attackerStateAbsorbPhaseValues(valPrincipalState PrincipalState) error {
 	attackerStateMutex.Lock()
 	for i, c := range valPrincipalState.Constants {
-		cc := Value{Kind: "constant", Constant: c}
+		cc := Value{Kind: ValueKindConstant, Constant: c}
 		if c.Qualifier != "public" {
 			continue
 		}
@@ -32,14 +36,13 @@ func attackerStateAbsorbPhaseValues(valPrincipalState PrincipalState) error {
 		if err == nil && earliestPhase > attackerStateShared.CurrentPhase {
 			continue
 		}
-		if valueEquivalentValueInValues(cc, attackerStateShared.Known) < 0 {
-			attackerStateShared.Known = append(attackerStateShared.Known, cc)
-		}
+		attackerStateAppend(cc)
 	}
 	for i, c := range valPrincipalState.Constants {
-		cc := Value{Kind: "constant", Constant: c}
+		cc := Value{Kind: ValueKindConstant, Constant: c}
 		a := valPrincipalState.Assigned[i]
-		if len(valPrincipalState.Wire[i]) == 0 && !valPrincipalState.Constants[i].Leaked {
+		hidden := len(valPrincipalState.Wire[i]) == 0 || valPrincipalState.Confidential[i]
+		if hidden && !valPrincipalState.Constants[i].Leaked {
 			continue
 		}
 		if valPrincipalState.Constants[i].Qualifier != "private" {
@@ -52,13 +55,9 @@ func attackerStateAbsorbPhaseValues(valPrincipalState PrincipalState) error {
 		if earliestPhase > attackerStateShared.CurrentPhase {
 			continue
 		}
-		if valueEquivalentValueInValues(cc, attackerStateShared.Known) < 0 {
-			attackerStateShared.Known = append(attackerStateShared.Known, cc)
-		}
+		attackerStateAppend(cc)
 		aa := valueResolveValueInternalValuesFromPrincipalState(a, a, i, valPrincipalState, attackerStateShared, true)
-		if valueEquivalentValueInValues(aa, attackerStateShared.Known) < 0 {
-			attackerStateShared.Known = append(attackerStateShared.Known, aa)
-		}
+		attackerStateAppend(aa)
 	}
 	attackerStateMutex.Unlock()
 	return nil
@@ -72,16 +71,71 @@ func attackerStateGetRead() AttackerState {
 }
 
 func attackerStatePutWrite(known Value) bool {
-	written := false
-	if valueEquivalentValueInValues(known, attackerStateShared.Known) < 0 {
-		attackerStateMutex.Lock()
-		if valueEquivalentValueInValues(known, attackerStateShared.Known) < 0 {
-			attackerStateShared.Known = append(attackerStateShared.Known, known)
-			written = true
+	attackerStateMutex.Lock()
+	written := attackerStateAppend(known)
+	attackerStateMutex.Unlock()
+	return written
+}
+
+// attackerStateAppend adds a value to what Attacker knows, unless an
+// equivalent value is already known. It must be called while holding
+// attackerStateMutex.
+func attackerStateAppend(known Value) bool {
+	h := valueHash(known)
+	if attackerStateKnowsHashed(attackerStateShared, known, h) >= 0 {
+		return false
+	}
+	attackerStateShared.Known = append(attackerStateShared.Known, known)
+	attackerStateKnownIndexPut(attackerStateShared.knownIndex, h, len(attackerStateShared.Known)-1)
+	return true
+}
+
+// attackerStateKnows returns the index of the value in Attacker's knowledge
+// that is equivalent to v, or -1 if there is none. Values are first looked
+// up by their hash, so only values sharing it are compared in depth.
+func attackerStateKnows(valAttackerState AttackerState, v Value) int {
+	return attackerStateKnowsHashed(valAttackerState, v, valueHash(v))
+}
+
+func attackerStateKnowsHashed(valAttackerState AttackerState, v Value, h uint64) int {
+	if valAttackerState.knownIndex == nil {
+		return valueEquivalentValueInValues(v, valAttackerState.Known)
+	}
+	indices, ok := valAttackerState.knownIndex.Load(h)
+	if !ok {
+		return -1
+	}
+	for _, i := range indices.([]int) {
+		// The index is shared with later states of the same phase, which
+		// may know more values than this one.
+		if i >= len(valAttackerState.Known) {
+			break
+		}
+		if valueEquivalentValues(v, valAttackerState.Known[i], true) {
+			return i
 		}
-		attackerStateMutex.Unlock()
 	}
-	return written
+	return -1
+}
+
+// attackerStateKnownIndexPut records that the value at index i has the
+// hash h. Each entry is replaced rather than appended to, since states read
+// earlier may be looking it up concurrently.
+func attackerStateKnownIndexPut(knownIndex *sync.Map, h uint64, i int) {
+	indices := []int{}
+	if existing, ok := knownIndex.Load(h); ok {
+		indices = append(indices, existing.([]int)...)
+	}
+	knownIndex.Store(h, append(indices, i))
+}
+
+// attackerStateKnownIndexBuild indexes a list of known values from scratch.
+func attackerStateKnownIndexBuild(known []Value) *sync.Map {
+	knownIndex := &sync.Map{}
+	for i, k := range known {
+		attackerStateKnownIndexPut(knownIndex, valueHash(k), i)
+	}
+	return knownIndex
 }
 
 func attackerStatePutPhaseUpdate(valPrincipalState PrincipalState, phase int) error {
@@ -91,3 +145,40 @@ func attackerStatePutPhaseUpdate(valPrincipalState PrincipalState, phase int) er
 	err := attackerStateAbsorbPhaseValues(valPrincipalState)
 	return err
 }
+
+// attackerStateCanFabricate tells us whether the attacker profile allows
+// constructing new values, as opposed to only replaying observed ones.
+func attackerStateCanFabricate(valAttackerState AttackerState) bool {
+	switch valAttackerState.Profile {
+	case "replay-only":
+		return false
+	}
+	return true
+}
+
+// attackerStateCanInject tells us whether the attacker profile allows
+// injecting fabricated primitives into messages.
+func attackerStateCanInject(valAttackerState AttackerState) bool {
+	switch valAttackerState.Profile {
+	case "replay-only", "no-inject":
+		return false
+	}
+	return true
+}
+
+// attackerStateControlsChannel tells us whether the attacker may modify values
+// sent from sender to recipient under the configured attacker profile.
+func attackerStateControlsChannel(
+	valAttackerState AttackerState, sender string, recipient string,
+) bool {
+	switch valAttackerState.Profile {
+	case "per-channel":
+		for _, c := range valAttackerState.Channels {
+			if c.Sender == sender && c.Recipient == recipient {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+/* SPDX-FileCopyrightText: © 2019-2020 Nadim Kobeissi <nadim@symbolic.software>
+ * SPDX-License-Identifier: GPL-3.0-only */
+// 00000000000000000000000000000000
+
+package vplogic
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// VerifyCacheShared enables the on-disk cache of verification results, which
+// lets a model that has already been verified return its results at once.
+var VerifyCacheShared bool
+
+// VerifyCacheVersionShared is the Verifpal version that cached results are
+// tied to, so that results from other versions are never reused.
+var VerifyCacheVersionShared string
+
+type cacheEntry struct {
+	Results     []VerifyResult
+	ResultsCode string
+}
+
+// cachePath returns where the results for a model are cached. Models are
+// told apart by the hash of their pretty-printed form, so that changes in
+// formatting or comments alone do not lead to a new analysis.
+func cachePath(m Model) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	pretty, err := PrettyModel(m)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256([]byte(fmt.Sprintf(
+		"%s\n%t\n%s", VerifyCacheVersionShared, VerifyExhaustiveShared, pretty,
+	)))
+	return filepath.Join(dir, "verifpal", fmt.Sprintf(
+		"%s.json", hex.EncodeToString(h[:]),
+	)), nil
+}
+
+func cacheEnabled() bool {
+	return VerifyCacheShared && len(VerifyResumeShared) == 0
+}
+
+func cacheGet(m Model) ([]VerifyResult, string, bool) {
+	entry := cacheEntry{}
+	if !cacheEnabled() {
+		return []VerifyResult{}, "", false
+	}
+	path, err := cachePath(m)
+	if err != nil {
+		return []VerifyResult{}, "", false
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return []VerifyResult{}, "", false
+	}
+	err = json.Unmarshal(b, &entry)
+	if err != nil || len(entry.Results) != len(m.Queries) {
+		return []VerifyResult{}, "", false
+	}
+	return entry.Results, entry.ResultsCode, true
+}
+
+func cachePut(m Model, valVerifyResults []VerifyResult, resultsCode string) error {
+	if !cacheEnabled() {
+		return nil
+	}
+	path, err := cachePath(m)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(cacheEntry{
+		Results:     valVerifyResults,
+		ResultsCode: resultsCode,
+	})
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0600)
+}
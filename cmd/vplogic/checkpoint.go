@@ -0,0 +1,320 @@
+/* SPDX-FileCopyrightText: © 2019-2020 Nadim Kobeissi <nadim@symbolic.software>
+ * SPDX-License-Identifier: GPL-3.0-only */
+// 00000000000000000000000000000000
+
+package vplogic
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// VerifyCheckpointShared, when set, is the path to which the progress of
+// the analysis is saved as it goes, so that it can later be resumed.
+var VerifyCheckpointShared string
+
+// VerifyResumeShared, when set, is the path of a checkpoint from which the
+// analysis is resumed.
+var VerifyResumeShared string
+
+// VerifyCheckpointIntervalShared is how often the progress of a scan is
+// saved to the checkpoint while the scan is under way.
+var VerifyCheckpointIntervalShared = 30 * time.Second
+
+// checkpoint records how far an analysis has gone: every stage before Stage
+// in Phase has been completed, as have the scans of Stage for Principals.
+// Scans holds how far each scan that was under way had gone.
+type checkpoint struct {
+	ModelHash     string
+	Phase         int
+	Stage         int
+	Principals    []string
+	Scans         []checkpointScan
+	Known         []Value
+	Results       []VerifyResult
+	AnalysisCount int
+}
+
+// checkpointScan is the earliest mutation combination of a principal's scan
+// that had not yet been analyzed when the checkpoint was saved. Since the
+// combinations of a scan are analyzed concurrently, some of the ones that
+// follow it may have been analyzed already, and are analyzed again on resume.
+type checkpointScan struct {
+	Principal   string
+	MutationMap MutationMap
+}
+
+var checkpointShared checkpoint
+var checkpointResumeShared *checkpoint
+var checkpointMutex sync.Mutex
+
+// checkpointScansShared holds, for each principal, the combinations of its
+// scan that are being analyzed, by their position.
+var checkpointScansShared = map[string]map[int]MutationMap{}
+var checkpointScansPosition int
+var checkpointSavedAt time.Time
+
+func checkpointModelHash(m Model) (string, error) {
+	pretty, err := PrettyModel(m)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256([]byte(pretty))
+	return hex.EncodeToString(h[:]), nil
+}
+
+func checkpointInit(m Model) error {
+	checkpointMutex.Lock()
+	defer checkpointMutex.Unlock()
+	checkpointShared = checkpoint{}
+	checkpointResumeShared = nil
+	checkpointScansShared = map[string]map[int]MutationMap{}
+	checkpointScansPosition = 0
+	checkpointSavedAt = time.Now()
+	if len(VerifyCheckpointShared) == 0 && len(VerifyResumeShared) == 0 {
+		return nil
+	}
+	modelHash, err := checkpointModelHash(m)
+	if err != nil {
+		return err
+	}
+	checkpointShared.ModelHash = modelHash
+	if len(VerifyResumeShared) == 0 {
+		return nil
+	}
+	cp, err := checkpointRead(VerifyResumeShared)
+	if err != nil {
+		return err
+	}
+	if cp.ModelHash != modelHash {
+		return fmt.Errorf(
+			"checkpoint (%s) was saved for a different model", VerifyResumeShared,
+		)
+	}
+	checkpointResumeShared = &cp
+	checkpointShared = cp
+	verifyResultsMutex.Lock()
+	for i := range cp.Results {
+		for ii := range cp.Results[i].Attacks {
+			cp.Results[i].Attacks[ii].reduced = true
+		}
+	}
+	verifyResultsShared = cp.Results
+	verifyResultsMutex.Unlock()
+	for _, scan := range cp.Scans {
+		checkpointScansShared[scan.Principal] = map[int]MutationMap{
+			scan.MutationMap.Position: scan.MutationMap,
+		}
+		if scan.MutationMap.Position > checkpointScansPosition {
+			checkpointScansPosition = scan.MutationMap.Position
+		}
+	}
+	atomic.AddUint32(&verifyAnalysisCount, uint32(cp.AnalysisCount))
+	InfoMessage(fmt.Sprintf(
+		"Resuming from checkpoint at phase %d, stage %d.", cp.Phase, cp.Stage,
+	), "info", false)
+	for _, scan := range cp.Scans {
+		InfoMessage(fmt.Sprintf(
+			"Resuming the scan of %s partway through, at combination %d.",
+			scan.Principal, scan.MutationMap.Position,
+		), "info", false)
+	}
+	return nil
+}
+
+func checkpointRead(path string) (checkpoint, error) {
+	cp := checkpoint{}
+	f, err := os.Open(path)
+	if err != nil {
+		return cp, err
+	}
+	defer f.Close()
+	err = gob.NewDecoder(f).Decode(&cp)
+	if err != nil {
+		return cp, fmt.Errorf("could not read checkpoint (%s): %v", path, err)
+	}
+	return cp, nil
+}
+
+// checkpointWrite saves the checkpoint through a temporary file, so that
+// an interruption while saving never leaves a truncated checkpoint behind.
+func checkpointWrite(cp checkpoint) error {
+	if len(VerifyCheckpointShared) == 0 {
+		return nil
+	}
+	cp.Known = attackerStateGetRead().Known
+	cp.Results, _ = verifyResultsGetRead()
+	cp.AnalysisCount = verifyAnalysisCountGet()
+	tmpPath := fmt.Sprintf("%s.tmp", VerifyCheckpointShared)
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	err = gob.NewEncoder(f).Encode(cp)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, VerifyCheckpointShared)
+}
+
+// checkpointSkip tells us whether the given stage of the given phase was
+// already completed according to the checkpoint being resumed.
+func checkpointSkip(phase int, stage int) bool {
+	checkpointMutex.Lock()
+	defer checkpointMutex.Unlock()
+	cp := checkpointResumeShared
+	if cp == nil {
+		return false
+	}
+	return phase < cp.Phase || (phase == cp.Phase && stage < cp.Stage)
+}
+
+func checkpointSkipPrincipal(phase int, stage int, principal string) bool {
+	checkpointMutex.Lock()
+	defer checkpointMutex.Unlock()
+	cp := checkpointResumeShared
+	if cp == nil || phase != cp.Phase || stage != cp.Stage {
+		return false
+	}
+	return strInSlice(principal, cp.Principals)
+}
+
+// checkpointResumeKnown restores what Attacker knew when the checkpoint
+// was saved, once the phase in which it was saved has been set up again.
+func checkpointResumeKnown(phase int) {
+	checkpointMutex.Lock()
+	cp := checkpointResumeShared
+	checkpointMutex.Unlock()
+	if cp == nil || phase != cp.Phase {
+		return
+	}
+	for _, known := range cp.Known {
+		attackerStatePutWrite(known)
+	}
+}
+
+func checkpointPutStage(phase int, stage int) {
+	checkpointMutex.Lock()
+	defer checkpointMutex.Unlock()
+	if checkpointShared.Phase != phase || checkpointShared.Stage != stage {
+		checkpointShared.Phase = phase
+		checkpointShared.Stage = stage
+		checkpointShared.Principals = []string{}
+		checkpointShared.Scans = []checkpointScan{}
+		checkpointScansShared = map[string]map[int]MutationMap{}
+	}
+	checkpointSave()
+}
+
+// checkpointPutPrincipal records that a principal's scan of the current
+// stage has run to completion, unless it only stopped due to cancellation.
+func checkpointPutPrincipal(principal string) {
+	if verifyResultsContextShared.Err() != nil {
+		return
+	}
+	checkpointMutex.Lock()
+	defer checkpointMutex.Unlock()
+	checkpointShared.Principals = append(checkpointShared.Principals, principal)
+	delete(checkpointScansShared, principal)
+	checkpointSave()
+}
+
+// checkpointResumeScan returns the combination from which a principal's
+// scan of the given stage resumes, if it was under way when the checkpoint
+// being resumed was saved. The combination keeps its position, so that it
+// is recorded as analyzed once it has been.
+func checkpointResumeScan(phase int, stage int, principal string) (MutationMap, bool) {
+	checkpointMutex.Lock()
+	defer checkpointMutex.Unlock()
+	cp := checkpointResumeShared
+	if cp == nil || phase != cp.Phase || stage != cp.Stage {
+		return MutationMap{}, false
+	}
+	for _, scan := range cp.Scans {
+		if scan.Principal == principal {
+			return checkpointScanCopy(scan.MutationMap), true
+		}
+	}
+	return MutationMap{}, false
+}
+
+// checkpointScanStart records that a combination of a principal's scan is
+// about to be analyzed, and saves the checkpoint if it was last saved longer
+// than VerifyCheckpointIntervalShared ago.
+func checkpointScanStart(principal string, valMutationMap MutationMap) MutationMap {
+	if len(VerifyCheckpointShared) == 0 {
+		return valMutationMap
+	}
+	checkpointMutex.Lock()
+	defer checkpointMutex.Unlock()
+	checkpointScansPosition = checkpointScansPosition + 1
+	valMutationMap.Position = checkpointScansPosition
+	if checkpointScansShared[principal] == nil {
+		checkpointScansShared[principal] = map[int]MutationMap{}
+	}
+	checkpointScansShared[principal][valMutationMap.Position] = checkpointScanCopy(valMutationMap)
+	if time.Since(checkpointSavedAt) >= VerifyCheckpointIntervalShared {
+		checkpointSave()
+	}
+	return valMutationMap
+}
+
+// checkpointScanCopy copies the parts of a mutation map that change as its
+// scan goes on to the next combination.
+func checkpointScanCopy(valMutationMap MutationMap) MutationMap {
+	valMutationMap.Combination = append([]Value{}, valMutationMap.Combination...)
+	valMutationMap.DepthIndex = append([]int{}, valMutationMap.DepthIndex...)
+	return valMutationMap
+}
+
+// checkpointScanFinish records that a combination of a principal's scan has
+// been analyzed.
+func checkpointScanFinish(principal string, valMutationMap MutationMap) {
+	if valMutationMap.Position == 0 {
+		return
+	}
+	checkpointMutex.Lock()
+	defer checkpointMutex.Unlock()
+	delete(checkpointScansShared[principal], valMutationMap.Position)
+}
+
+// checkpointSave saves the checkpoint, unless the analysis was cancelled,
+// in which case the scans that stopped early would be recorded as complete.
+func checkpointSave() {
+	if verifyResultsContextShared.Err() != nil {
+		return
+	}
+	checkpointSavedAt = time.Now()
+	checkpointShared.Scans = []checkpointScan{}
+	for principal, started := range checkpointScansShared {
+		earliest := MutationMap{}
+		for position, valMutationMap := range started {
+			if earliest.Position == 0 || position < earliest.Position {
+				earliest = valMutationMap
+			}
+		}
+		if earliest.Position > 0 {
+			checkpointShared.Scans = append(checkpointShared.Scans, checkpointScan{
+				Principal:   principal,
+				MutationMap: earliest,
+			})
+		}
+	}
+	err := checkpointWrite(checkpointShared)
+	if err != nil {
+		InfoMessage(fmt.Sprintf(
+			"Could not save checkpoint (%v).", err,
+		), "warning", false)
+	}
+}
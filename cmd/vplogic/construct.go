@@ -4,7 +4,10 @@
 
 package vplogic
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 func constructKnowledgeMap(m Model, principals []string) (KnowledgeMap, error) {
 	var err error
@@ -44,24 +47,25 @@ func constructKnowledgeMap(m Model, principals []string) (KnowledgeMap, error) {
 			map[string]string{principal: principal},
 		)
 	}
+	valKnowledgeMap = constructKnowledgeMapRenderLiterals(valKnowledgeMap, m)
 	for _, blck := range m.Blocks {
 		switch blck.Kind {
-		case "principal":
+		case BlockKindPrincipal:
 			valKnowledgeMap, declaredAt, err = constructKnowledgeMapRenderPrincipal(
 				valKnowledgeMap, blck, declaredAt, currentPhase,
 			)
 			if err != nil {
-				return KnowledgeMap{}, err
+				return KnowledgeMap{}, fragmentOriginError(blck, err)
 			}
-		case "message":
+		case BlockKindMessage:
 			declaredAt = declaredAt + 1
 			valKnowledgeMap, err = constructKnowledgeMapRenderMessage(
 				valKnowledgeMap, blck, currentPhase,
 			)
 			if err != nil {
-				return KnowledgeMap{}, err
+				return KnowledgeMap{}, fragmentOriginError(blck, err)
 			}
-		case "phase":
+		case BlockKindPhase:
 			currentPhase = blck.Phase.Number
 		}
 	}
@@ -69,34 +73,95 @@ func constructKnowledgeMap(m Model, principals []string) (KnowledgeMap, error) {
 	return valKnowledgeMap, nil
 }
 
+func constructKnowledgeMapRenderLiterals(valKnowledgeMap KnowledgeMap, m Model) KnowledgeMap {
+	for _, blck := range m.Blocks {
+		if blck.Kind != BlockKindPrincipal {
+			continue
+		}
+		for _, expr := range blck.Principal.Expressions {
+			if expr.Kind != ExpressionKindAssignment {
+				continue
+			}
+			for _, c := range constructLiterals(expr.Right, []Constant{}) {
+				if valueGetKnowledgeMapIndexFromConstant(valKnowledgeMap, c) >= 0 {
+					continue
+				}
+				c = Constant{
+					Name:        c.Name,
+					Guard:       false,
+					Fresh:       false,
+					Leaked:      false,
+					Declaration: "knows",
+					Qualifier:   "public",
+				}
+				valKnowledgeMap.Constants = append(valKnowledgeMap.Constants, c)
+				valKnowledgeMap.Assigned = append(valKnowledgeMap.Assigned, Value{
+					Kind:     ValueKindConstant,
+					Constant: c,
+				})
+				valKnowledgeMap.Creator = append(valKnowledgeMap.Creator, valKnowledgeMap.Principals[0])
+				valKnowledgeMap.KnownBy = append(valKnowledgeMap.KnownBy, []map[string]string{})
+				valKnowledgeMap.DeclaredAt = append(valKnowledgeMap.DeclaredAt, 0)
+				valKnowledgeMap.Phase = append(valKnowledgeMap.Phase, []int{0})
+				l := len(valKnowledgeMap.Constants) - 1
+				for _, principal := range valKnowledgeMap.Principals {
+					valKnowledgeMap.KnownBy[l] = append(
+						valKnowledgeMap.KnownBy[l],
+						map[string]string{principal: principal},
+					)
+				}
+			}
+		}
+	}
+	return valKnowledgeMap
+}
+
+func constructLiterals(a Value, literals []Constant) []Constant {
+	switch a.Kind {
+	case ValueKindConstant:
+		if valueConstantIsLiteral(a.Constant) {
+			literals = append(literals, a.Constant)
+		}
+	case ValueKindPrimitive:
+		for _, aa := range a.Primitive.Arguments {
+			literals = constructLiterals(aa, literals)
+		}
+	case ValueKindEquation:
+		for _, aa := range a.Equation.Values {
+			literals = constructLiterals(aa, literals)
+		}
+	}
+	return literals
+}
+
 func constructKnowledgeMapRenderPrincipal(
 	valKnowledgeMap KnowledgeMap, blck Block, declaredAt int, currentPhase int,
 ) (KnowledgeMap, int, error) {
 	var err error
 	for _, expr := range blck.Principal.Expressions {
 		switch expr.Kind {
-		case "knows":
+		case ExpressionKindKnows:
 			valKnowledgeMap, err = constructKnowledgeMapRenderKnows(
 				valKnowledgeMap, blck, declaredAt, expr,
 			)
 			if err != nil {
 				return KnowledgeMap{}, 0, err
 			}
-		case "generates":
+		case ExpressionKindGenerates:
 			valKnowledgeMap, err = constructKnowledgeMapRenderGenerates(
 				valKnowledgeMap, blck, declaredAt, expr,
 			)
 			if err != nil {
 				return KnowledgeMap{}, 0, err
 			}
-		case "assignment":
+		case ExpressionKindAssignment:
 			valKnowledgeMap, err = constructKnowledgeMapRenderAssignment(
 				valKnowledgeMap, blck, declaredAt, expr,
 			)
 			if err != nil {
 				return KnowledgeMap{}, 0, err
 			}
-		case "leaks":
+		case ExpressionKindLeaks:
 			declaredAt = declaredAt + 1
 			valKnowledgeMap, err = constructKnowledgeMapRenderLeaks(
 				valKnowledgeMap, blck, expr, currentPhase,
@@ -142,7 +207,7 @@ func constructKnowledgeMapRenderKnows(
 		}
 		valKnowledgeMap.Constants = append(valKnowledgeMap.Constants, c)
 		valKnowledgeMap.Assigned = append(valKnowledgeMap.Assigned, Value{
-			Kind:     "constant",
+			Kind:     ValueKindConstant,
 			Constant: c,
 		})
 		valKnowledgeMap.Creator = append(valKnowledgeMap.Creator, blck.Principal.Name)
@@ -186,7 +251,7 @@ func constructKnowledgeMapRenderGenerates(
 		}
 		valKnowledgeMap.Constants = append(valKnowledgeMap.Constants, c)
 		valKnowledgeMap.Assigned = append(valKnowledgeMap.Assigned, Value{
-			Kind:     "constant",
+			Kind:     ValueKindConstant,
 			Constant: c,
 		})
 		valKnowledgeMap.Creator = append(valKnowledgeMap.Creator, blck.Principal.Name)
@@ -205,7 +270,7 @@ func constructKnowledgeMapRenderAssignment(
 		return KnowledgeMap{}, err
 	}
 	switch expr.Right.Kind {
-	case "primitive":
+	case ValueKindPrimitive:
 		err := sanityPrimitive(expr.Right.Primitive, expr.Left)
 		if err != nil {
 			return KnowledgeMap{}, err
@@ -234,6 +299,17 @@ func constructKnowledgeMapRenderAssignment(
 			)
 		}
 	}
+	switch expr.Right.Kind {
+	case ValueKindPrimitive:
+		if expr.Right.Primitive.Name == "KEM_ENCAPS" && len(expr.Right.Primitive.Arguments) == 1 {
+			valKnowledgeMap, expr.Right, err = constructKnowledgeMapRenderEncapsulation(
+				valKnowledgeMap, blck, declaredAt, expr,
+			)
+			if err != nil {
+				return KnowledgeMap{}, err
+			}
+		}
+	}
 	for i, c := range expr.Left {
 		ii := valueGetKnowledgeMapIndexFromConstant(valKnowledgeMap, c)
 		if ii >= 0 {
@@ -251,7 +327,7 @@ func constructKnowledgeMapRenderAssignment(
 			Qualifier:   "private",
 		}
 		switch expr.Right.Kind {
-		case "primitive":
+		case ValueKindPrimitive:
 			expr.Right.Primitive.Output = i
 		}
 		valKnowledgeMap.Constants = append(valKnowledgeMap.Constants, c)
@@ -264,6 +340,36 @@ func constructKnowledgeMapRenderAssignment(
 	return valKnowledgeMap, nil
 }
 
+// constructKnowledgeMapRenderEncapsulation gives a key encapsulation that
+// is not given its randomness explicitly the fresh randomness that it draws
+// internally, as its second argument. This takes the form of an internal
+// constant generated by the encapsulating principal. Without it, anyone
+// knowing the public key could recompute an honest shared secret, whereas
+// the attacker should only learn the shared secrets of encapsulations it
+// performs itself.
+func constructKnowledgeMapRenderEncapsulation(
+	valKnowledgeMap KnowledgeMap, blck Block, declaredAt int, expr Expression,
+) (KnowledgeMap, Value, error) {
+	r := Constant{
+		Name: fmt.Sprintf("#encaps_%s", expr.Left[0].Name),
+	}
+	valKnowledgeMap, err := constructKnowledgeMapRenderGenerates(
+		valKnowledgeMap, blck, declaredAt, Expression{
+			Kind:      ExpressionKindGenerates,
+			Constants: []Constant{r},
+		},
+	)
+	if err != nil {
+		return KnowledgeMap{}, Value{}, err
+	}
+	i := valueGetKnowledgeMapIndexFromConstant(valKnowledgeMap, r)
+	p := expr.Right.Primitive
+	arguments := make([]Value, len(p.Arguments), len(p.Arguments)+1)
+	copy(arguments, p.Arguments)
+	p.Arguments = append(arguments, valKnowledgeMap.Assigned[i])
+	return valKnowledgeMap, Value{Kind: ValueKindPrimitive, Primitive: p}, nil
+}
+
 func constructKnowledgeMapRenderLeaks(
 	valKnowledgeMap KnowledgeMap, blck Block, expr Expression, currentPhase int,
 ) (KnowledgeMap, error) {
@@ -301,19 +407,29 @@ func constructKnowledgeMapRenderLeaks(
 func constructKnowledgeMapRenderMessage(
 	valKnowledgeMap KnowledgeMap, blck Block, currentPhase int,
 ) (KnowledgeMap, error) {
+	recipients := []string{}
+	for _, recipient := range constructMessageRecipients(blck.Message) {
+		var err error
+		recipients, err = appendUniqueString(recipients, recipient)
+		if err != nil {
+			return valKnowledgeMap, fmt.Errorf(
+				"%s sends a message to %s more than once",
+				blck.Message.Sender, recipient,
+			)
+		}
+	}
 	for _, c := range blck.Message.Constants {
 		i := valueGetKnowledgeMapIndexFromConstant(valKnowledgeMap, c)
 		if i < 0 {
 			return valKnowledgeMap, fmt.Errorf(fmt.Sprintf(
 				"%s sends unknown constant to %s (%s)",
 				blck.Message.Sender,
-				blck.Message.Recipient,
+				strings.Join(recipients, ", "),
 				prettyConstant(c),
 			))
 		}
 		c = valKnowledgeMap.Constants[i]
 		senderKnows := false
-		recipientKnows := false
 		if valKnowledgeMap.Creator[i] == blck.Message.Sender {
 			senderKnows = true
 		}
@@ -322,33 +438,36 @@ func constructKnowledgeMapRenderMessage(
 				senderKnows = true
 			}
 		}
-		if valKnowledgeMap.Creator[i] == blck.Message.Recipient {
-			recipientKnows = true
-		}
-		for _, m := range valKnowledgeMap.KnownBy[i] {
-			if _, ok := m[blck.Message.Recipient]; ok {
-				recipientKnows = true
-			}
-		}
-		switch {
-		case !senderKnows:
+		if !senderKnows {
 			return valKnowledgeMap, fmt.Errorf(
 				"%s is sending constant (%s) despite not knowing it",
 				blck.Message.Sender,
 				prettyConstant(c),
 			)
-		case recipientKnows:
-			return valKnowledgeMap, fmt.Errorf(
-				"%s is receiving constant (%s) despite already knowing it",
-				blck.Message.Recipient,
-				prettyConstant(c),
+		}
+		for _, recipient := range recipients {
+			recipientKnows := false
+			if valKnowledgeMap.Creator[i] == recipient {
+				recipientKnows = true
+			}
+			for _, m := range valKnowledgeMap.KnownBy[i] {
+				if _, ok := m[recipient]; ok {
+					recipientKnows = true
+				}
+			}
+			if recipientKnows {
+				return valKnowledgeMap, fmt.Errorf(
+					"%s is receiving constant (%s) despite already knowing it",
+					recipient,
+					prettyConstant(c),
+				)
+			}
+			valKnowledgeMap.KnownBy[i] = append(
+				valKnowledgeMap.KnownBy[i], map[string]string{
+					recipient: blck.Message.Sender,
+				},
 			)
 		}
-		valKnowledgeMap.KnownBy[i] = append(
-			valKnowledgeMap.KnownBy[i], map[string]string{
-				blck.Message.Recipient: blck.Message.Sender,
-			},
-		)
 		valKnowledgeMap.Phase[i], _ = appendUniqueInt(
 			valKnowledgeMap.Phase[i], currentPhase,
 		)
@@ -356,6 +475,15 @@ func constructKnowledgeMapRenderMessage(
 	return valKnowledgeMap, nil
 }
 
+// constructMessageRecipients returns every recipient of a message,
+// which may be more than one in the case of a broadcast.
+func constructMessageRecipients(msg Message) []string {
+	if len(msg.Recipients) > 0 {
+		return msg.Recipients
+	}
+	return []string{msg.Recipient}
+}
+
 func constructPrincipalStates(m Model, valKnowledgeMap KnowledgeMap) []PrincipalState {
 	valPrincipalStates := []PrincipalState{}
 	for _, principal := range valKnowledgeMap.Principals {
@@ -375,7 +503,9 @@ func constructPrincipalStates(m Model, valKnowledgeMap KnowledgeMap) []Principal
 			MutatableTo:   [][]string{},
 			BeforeMutate:  []Value{},
 			Phase:         [][]int{},
+			Confidential:  []bool{},
 			Lock:          0,
+			AttackerKnown: 0,
 		}
 		for i, c := range valKnowledgeMap.Constants {
 			wire := []string{}
@@ -414,6 +544,9 @@ func constructPrincipalStates(m Model, valKnowledgeMap KnowledgeMap) []Principal
 			valPrincipalState.MutatableTo = append(valPrincipalState.MutatableTo, mutatableTo)
 			valPrincipalState.BeforeMutate = append(valPrincipalState.BeforeMutate, assigned)
 			valPrincipalState.Phase = append(valPrincipalState.Phase, valKnowledgeMap.Phase[i])
+			valPrincipalState.Confidential = append(valPrincipalState.Confidential,
+				constructPrincipalStatesGetValueConfidentiality(c, m.Blocks),
+			)
 		}
 		valPrincipalStates = append(valPrincipalStates, valPrincipalState)
 	}
@@ -425,27 +558,60 @@ func constructPrincipalStatesGetValueMutatability(
 	wire []string, guard bool, mutatableTo []string,
 ) ([]string, bool, []string) {
 	switch blck.Kind {
-	case "message":
-		ir := (blck.Message.Recipient == principal)
+	case BlockKindMessage:
+		recipients := constructMessageRecipients(blck.Message)
+		ir := strInSlice(principal, recipients)
 		ic := (creator == principal)
+		authenticated := constructMessageChannelAuthenticated(blck.Message)
 		for _, cc := range blck.Message.Constants {
 			if c.Name != cc.Name {
 				continue
 			}
-			wire, _ = appendUniqueString(wire, blck.Message.Recipient)
+			for _, recipient := range recipients {
+				wire, _ = appendUniqueString(wire, recipient)
+			}
+			guarded := cc.Guard || authenticated
 			if !guard {
-				guard = cc.Guard && (ir || ic)
+				guard = guarded && (ir || ic)
 			}
-			if !cc.Guard {
-				mutatableTo, _ = appendUniqueString(
-					mutatableTo, blck.Message.Recipient,
-				)
+			if guarded {
+				continue
+			}
+			for _, recipient := range recipients {
+				mutatableTo, _ = appendUniqueString(mutatableTo, recipient)
 			}
 		}
 	}
 	return wire, guard, mutatableTo
 }
 
+func constructPrincipalStatesGetValueConfidentiality(c Constant, blocks []Block) bool {
+	sent := false
+	for _, blck := range blocks {
+		if blck.Kind != BlockKindMessage {
+			continue
+		}
+		for _, cc := range blck.Message.Constants {
+			if c.Name != cc.Name {
+				continue
+			}
+			if !constructMessageChannelConfidential(blck.Message) {
+				return false
+			}
+			sent = true
+		}
+	}
+	return sent
+}
+
+func constructMessageChannelAuthenticated(msg Message) bool {
+	return msg.Channel == "auth" || msg.Channel == "secure"
+}
+
+func constructMessageChannelConfidential(msg Message) bool {
+	return msg.Channel == "conf" || msg.Channel == "secure"
+}
+
 func constructPrincipalStateClone(valPrincipalState PrincipalState, purify bool) PrincipalState {
 	valPrincipalStateClone := PrincipalState{
 		Name:          valPrincipalState.Name,
@@ -463,7 +629,9 @@ func constructPrincipalStateClone(valPrincipalState PrincipalState, purify bool)
 		MutatableTo:   make([][]string, len(valPrincipalState.MutatableTo)),
 		BeforeMutate:  make([]Value, len(valPrincipalState.BeforeMutate)),
 		Phase:         make([][]int, len(valPrincipalState.Phase)),
+		Confidential:  make([]bool, len(valPrincipalState.Confidential)),
 		Lock:          valPrincipalState.Lock,
+		AttackerKnown: valPrincipalState.AttackerKnown,
 	}
 	copy(valPrincipalStateClone.Constants, valPrincipalState.Constants)
 	if purify {
@@ -487,5 +655,6 @@ func constructPrincipalStateClone(valPrincipalState PrincipalState, purify bool)
 	copy(valPrincipalStateClone.MutatableTo, valPrincipalState.MutatableTo)
 	copy(valPrincipalStateClone.BeforeMutate, valPrincipalState.BeforeMutate)
 	copy(valPrincipalStateClone.Phase, valPrincipalState.Phase)
+	copy(valPrincipalStateClone.Confidential, valPrincipalState.Confidential)
 	return valPrincipalStateClone
 }
@@ -62,7 +62,7 @@ func coqModel(m Model, valKnowledgeMap KnowledgeMap) (string, error) {
 			i, i))
 		for _, q := range m.Queries {
 			switch q.Kind {
-			case "confidentiality":
+			case QueryKindConfidentiality:
 				for _, qc := range q.Constants {
 					crc, err := coqResolveConstant(qc, valKnowledgeMap)
 					if err != nil {
@@ -88,7 +88,7 @@ func coqBlockByPhase(valKnowledgeMap KnowledgeMap, phase []Block, output []strin
 	var err error
 	for _, block := range phase {
 		switch block.Kind {
-		case "principal":
+		case BlockKindPrincipal:
 			cpb, err = coqPrincipalBlock(block, valKnowledgeMap)
 			if err != nil {
 				return []string{}, err
@@ -97,7 +97,8 @@ func coqBlockByPhase(valKnowledgeMap KnowledgeMap, phase []Block, output []strin
 				"\t\tpblock (PRINCIPAL\"%s\"[%s]);",
 				block.Principal.Name, cpb,
 			))
-		case "message":
+		case BlockKindMessage:
+			authenticated := constructMessageChannelAuthenticated(block.Message)
 			for _, x := range block.Message.Constants {
 				crc, err = coqResolveConstant(x, valKnowledgeMap)
 				if err != nil {
@@ -105,7 +106,7 @@ func coqBlockByPhase(valKnowledgeMap KnowledgeMap, phase []Block, output []strin
 				}
 				output = append(output, fmt.Sprintf(
 					"\t\tmblock(MSG %s (%s));",
-					coqGuard(x.Guard), crc,
+					coqGuard(x.Guard || authenticated), crc,
 				))
 			}
 		default:
@@ -131,7 +132,7 @@ func coqPrincipalBlock(block Block, valKnowledgeMap KnowledgeMap) (string, error
 	expressions := []string{""}
 	for i, expression := range block.Principal.Expressions {
 		switch expression.Kind {
-		case "knows":
+		case ExpressionKindKnows:
 			switch expression.Qualifier {
 			case "password":
 				for _, c := range expression.Constants {
@@ -152,7 +153,7 @@ func coqPrincipalBlock(block Block, valKnowledgeMap KnowledgeMap) (string, error
 					))
 				}
 			}
-		case "generates":
+		case ExpressionKindGenerates:
 			for _, c := range expression.Constants {
 				crv, err := coqResolveConstant(c, valKnowledgeMap)
 				if err != nil {
@@ -162,7 +163,7 @@ func coqPrincipalBlock(block Block, valKnowledgeMap KnowledgeMap) (string, error
 					"EXP generation private %s unleaked;", crv,
 				))
 			}
-		case "leaks":
+		case ExpressionKindLeaks:
 			for _, c := range expression.Constants {
 				crc, err := coqResolveConstant(c, valKnowledgeMap)
 				if err != nil {
@@ -172,7 +173,7 @@ func coqPrincipalBlock(block Block, valKnowledgeMap KnowledgeMap) (string, error
 					"EXP knows public %s leaked;", crc,
 				))
 			}
-		case "assignment":
+		case ExpressionKindAssignment:
 			cae, err := coqAssignemntExpression(expression, valKnowledgeMap)
 			if err != nil {
 				return "", err
@@ -192,7 +193,7 @@ func coqPrincipalBlock(block Block, valKnowledgeMap KnowledgeMap) (string, error
 func coqAssignemntExpression(expression Expression, valKnowledgeMap KnowledgeMap) ([]string, error) {
 	expressions := []string{}
 	switch expression.Right.Kind {
-	case "equation":
+	case ValueKindEquation:
 		cre, err := coqResolveEquation(expression.Right.Equation, valKnowledgeMap)
 		if err != nil {
 			return []string{}, err
@@ -200,7 +201,7 @@ func coqAssignemntExpression(expression Expression, valKnowledgeMap KnowledgeMap
 		expressions = append(expressions, fmt.Sprintf(
 			"EXP assignment private %s unleaked;", cre,
 		))
-	case "primitive":
+	case ValueKindPrimitive:
 		switch expression.Right.Primitive.Name {
 		case "HASH", "PW_HASH", "CONCAT":
 			exp := fmt.Sprintf(
@@ -255,7 +256,7 @@ func coqGuard(guard bool) string {
 
 func coqResolveConstant(c Constant, valKnowledgeMap KnowledgeMap) (string, error) {
 	a, _ := valueResolveValueInternalValuesFromKnowledgeMap(Value{
-		Kind:     "constant",
+		Kind:     ValueKindConstant,
 		Constant: c,
 	}, valKnowledgeMap)
 	return coqPrintValue(a)
@@ -263,7 +264,7 @@ func coqResolveConstant(c Constant, valKnowledgeMap KnowledgeMap) (string, error
 
 func coqResolvePrimitive(p Primitive, valKnowledgeMap KnowledgeMap) (string, error) {
 	a, _ := valueResolveValueInternalValuesFromKnowledgeMap(Value{
-		Kind:      "primitive",
+		Kind:      ValueKindPrimitive,
 		Primitive: p,
 	}, valKnowledgeMap)
 	return coqPrintValue(a)
@@ -271,7 +272,7 @@ func coqResolvePrimitive(p Primitive, valKnowledgeMap KnowledgeMap) (string, err
 
 func coqResolveEquation(e Equation, valKnowledgeMap KnowledgeMap) (string, error) {
 	a, _ := valueResolveValueInternalValuesFromKnowledgeMap(Value{
-		Kind:     "equation",
+		Kind:     ValueKindEquation,
 		Equation: e,
 	}, valKnowledgeMap)
 	return coqPrintValue(a)
@@ -279,11 +280,11 @@ func coqResolveEquation(e Equation, valKnowledgeMap KnowledgeMap) (string, error
 
 func coqResolveValue(v Value, valKnowledgeMap KnowledgeMap) (string, error) {
 	switch v.Kind {
-	case "constant":
+	case ValueKindConstant:
 		return coqResolveConstant(v.Constant, valKnowledgeMap)
-	case "primitive":
+	case ValueKindPrimitive:
 		return coqResolvePrimitive(v.Primitive, valKnowledgeMap)
-	case "equation":
+	case ValueKindEquation:
 		return coqResolveEquation(v.Equation, valKnowledgeMap)
 	}
 	return "", fmt.Errorf("invalid value kind")
@@ -291,11 +292,11 @@ func coqResolveValue(v Value, valKnowledgeMap KnowledgeMap) (string, error) {
 
 func coqPrintValue(a Value) (string, error) {
 	switch a.Kind {
-	case "constant":
+	case ValueKindConstant:
 		return coqPrintConstant(a.Constant)
-	case "primitive":
+	case ValueKindPrimitive:
 		return coqPrintPrimitive(a.Primitive)
-	case "equation":
+	case ValueKindEquation:
 		return coqPrintEquation(a.Equation)
 	}
 	return "", fmt.Errorf("invalid value kind")
@@ -304,7 +305,7 @@ func coqPrintValue(a Value) (string, error) {
 func coqPrintConstant(c Constant) (string, error) {
 	return fmt.Sprintf(
 		"(const (cnstn \"%s\"))",
-		c.Name), nil
+		strings.ReplaceAll(c.Name, "\"", "\"\"")), nil
 }
 
 func coqPrintPrimitive(p Primitive) (string, error) {
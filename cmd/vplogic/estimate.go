@@ -0,0 +1,246 @@
+/* SPDX-FileCopyrightText: © 2019-2020 Nadim Kobeissi <nadim@symbolic.software>
+ * SPDX-License-Identifier: GPL-3.0-only */
+// 00000000000000000000000000000000
+
+package vplogic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Estimate loads a Verifpal model from a file and reports how large a
+// search space its active analysis starts out with, without analyzing it.
+func Estimate(filePath string) error {
+	m, err := libpegParseModel(filePath, false)
+	if err != nil {
+		return err
+	}
+	valEstimate, err := EstimateModel(m)
+	if err != nil {
+		return err
+	}
+	estimateInfo(m, valEstimate)
+	return nil
+}
+
+// EstimateModel computes the size of the mutation maps that active analysis
+// of the model would begin each principal's scan with, at every phase and
+// stage. Attacker only learns more as analysis goes on, so these sizes are
+// a lower bound, but they grow together with the time that analysis takes.
+func EstimateModel(m Model) (EstimateResult, error) {
+	valEstimate := EstimateResult{
+		Stages:     []EstimateStage{},
+		Primitives: []EstimatePrimitive{},
+		Dominant:   []EstimateValue{},
+	}
+	valKnowledgeMap, valPrincipalStates, err := sanity(m)
+	if err != nil {
+		return valEstimate, err
+	}
+	valEstimate.Primitives = estimatePrimitives(valKnowledgeMap)
+	if m.Attacker == "passive" {
+		valEstimate.Cost = estimateCost(0)
+		return valEstimate, nil
+	}
+	verifyResultsInit(context.Background(), m)
+	eventSinkSaved := eventSinkShared
+	eventSinkShared = eventSinkDiscard{}
+	defer func() {
+		eventSinkShared = eventSinkSaved
+	}()
+	dominant := map[string]EstimateValue{}
+	for phase := 0; phase <= valKnowledgeMap.MaxPhase; phase++ {
+		attackerStateInit(m, true)
+		err = attackerStatePutPhaseUpdate(valPrincipalStates[0], phase)
+		if err != nil {
+			return valEstimate, err
+		}
+		valAttackerState := attackerStateGetRead()
+		for stage := 1; stage <= 4; stage++ {
+			for _, valPrincipalState := range valPrincipalStates {
+				valMutationMap := mutationMapInit(
+					valKnowledgeMap, valPrincipalState, valAttackerState, stage,
+				)
+				valEstimateStage := EstimateStage{
+					Phase:        phase,
+					Stage:        stage,
+					Principal:    valPrincipalState.Name,
+					Values:       len(valMutationMap.Constants),
+					Combinations: 0,
+				}
+				if len(valMutationMap.Constants) > 0 {
+					valEstimateStage.Combinations = 1
+				}
+				for i, c := range valMutationMap.Constants {
+					mutations := len(valMutationMap.Mutations[i])
+					valEstimateStage.Combinations *= float64(mutations)
+					key := fmt.Sprintf("%s/%s", valPrincipalState.Name, c.Name)
+					if mutations > dominant[key].Mutations {
+						dominant[key] = EstimateValue{
+							Principal: valPrincipalState.Name,
+							Constant:  c,
+							Phase:     phase,
+							Stage:     stage,
+							Mutations: mutations,
+						}
+					}
+				}
+				valEstimate.Combinations += valEstimateStage.Combinations
+				valEstimate.Stages = append(valEstimate.Stages, valEstimateStage)
+			}
+		}
+	}
+	for _, v := range dominant {
+		valEstimate.Dominant = append(valEstimate.Dominant, v)
+	}
+	sort.Slice(valEstimate.Dominant, func(i int, ii int) bool {
+		a, b := valEstimate.Dominant[i], valEstimate.Dominant[ii]
+		if a.Mutations != b.Mutations {
+			return a.Mutations > b.Mutations
+		}
+		if a.Principal != b.Principal {
+			return a.Principal < b.Principal
+		}
+		return a.Constant.Name < b.Constant.Name
+	})
+	valEstimate.Cost = estimateCost(valEstimate.Combinations)
+	return valEstimate, nil
+}
+
+// estimatePrimitives counts the injectable primitives used in the model,
+// along with the number of arguments that they are given.
+func estimatePrimitives(valKnowledgeMap KnowledgeMap) []EstimatePrimitive {
+	primitives := []EstimatePrimitive{}
+	var walk func(a Value)
+	walk = func(a Value) {
+		switch a.Kind {
+		case ValueKindPrimitive:
+			for _, aa := range a.Primitive.Arguments {
+				walk(aa)
+			}
+			if !estimatePrimitiveInjectable(a.Primitive) {
+				return
+			}
+			arity := len(a.Primitive.Arguments)
+			for i, p := range primitives {
+				if p.Name == a.Primitive.Name && p.Arity == arity {
+					primitives[i].Count = p.Count + 1
+					return
+				}
+			}
+			primitives = append(primitives, EstimatePrimitive{
+				Name: a.Primitive.Name, Arity: arity, Count: 1,
+			})
+		case ValueKindEquation:
+			for _, aa := range a.Equation.Values {
+				walk(aa)
+			}
+		}
+	}
+	for _, a := range valKnowledgeMap.Assigned {
+		walk(a)
+	}
+	sort.Slice(primitives, func(i int, ii int) bool {
+		if primitives[i].Name != primitives[ii].Name {
+			return primitives[i].Name < primitives[ii].Name
+		}
+		return primitives[i].Arity < primitives[ii].Arity
+	})
+	return primitives
+}
+
+func estimatePrimitiveInjectable(p Primitive) bool {
+	if primitiveIsCorePrim(p.Name) {
+		prim, err := primitiveCoreGet(p)
+		return err == nil && prim.Injectable
+	}
+	prim, err := primitiveGet(p)
+	return err == nil && prim.Injectable
+}
+
+// estimateCost sorts a number of mutation combinations into a rough class
+// of how long analysis will take on a typical laptop.
+func estimateCost(combinations float64) string {
+	switch {
+	case combinations < 1e4:
+		return "seconds"
+	case combinations < 1e6:
+		return "minutes"
+	case combinations < 1e8:
+		return "hours"
+	}
+	return "days or longer"
+}
+
+func estimateCount(f float64) string {
+	if f < 1e9 {
+		return fmt.Sprintf("%.0f", f)
+	}
+	return fmt.Sprintf("%.2e", f)
+}
+
+func estimatePlural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func estimateInfo(m Model, valEstimate EstimateResult) {
+	InfoMessage(fmt.Sprintf(
+		"Estimating the search space of '%s'.", m.FileName,
+	), "verifpal", false)
+	if len(valEstimate.Primitives) > 0 {
+		primitives := []string{}
+		for _, p := range valEstimate.Primitives {
+			primitives = append(primitives, fmt.Sprintf(
+				"%s with %s (%s)", p.Name,
+				estimatePlural(p.Arity, "argument"), estimatePlural(p.Count, "use"),
+			))
+		}
+		InfoMessage(fmt.Sprintf(
+			"Injectable primitives: %s.", strings.Join(primitives, ", "),
+		), "info", false)
+	}
+	if m.Attacker == "passive" {
+		InfoMessage("Attacker is configured as passive, so no values will be mutated.", "info", false)
+	}
+	phaseCombinations := map[int]float64{}
+	maxPhase := -1
+	for _, s := range valEstimate.Stages {
+		phaseCombinations[s.Phase] += s.Combinations
+		if s.Phase > maxPhase {
+			maxPhase = s.Phase
+		}
+		if s.Values == 0 {
+			continue
+		}
+		InfoMessage(fmt.Sprintf(
+			"Phase %d, stage %d: %s starts with %s in %s combinations.",
+			s.Phase, s.Stage, s.Principal,
+			estimatePlural(s.Values, "mutable value"), estimateCount(s.Combinations),
+		), "info", false)
+	}
+	for phase := 0; phase <= maxPhase; phase++ {
+		InfoMessage(fmt.Sprintf(
+			"Phase %d: %s combinations projected.",
+			phase, estimateCount(phaseCombinations[phase]),
+		), "info", false)
+	}
+	for i, v := range valEstimate.Dominant {
+		if i == 3 || v.Mutations < 3 {
+			break
+		}
+		InfoMessage(fmt.Sprintf(
+			"%s, as received by %s, can be replaced with %d other values (phase %d, stage %d).",
+			prettyConstant(v.Constant), v.Principal, v.Mutations-1, v.Phase, v.Stage,
+		), "info", false)
+	}
+	InfoMessage(fmt.Sprintf(
+		"Estimated cost: %s (%s combinations).",
+		valEstimate.Cost, estimateCount(valEstimate.Combinations),
+	), "verifpal", false)
+}
@@ -0,0 +1,64 @@
+/* SPDX-FileCopyrightText: © 2019-2020 Nadim Kobeissi <nadim@symbolic.software>
+ * SPDX-License-Identifier: GPL-3.0-only */
+// 00000000000000000000000000000000
+
+package vplogic
+
+import (
+	"os"
+)
+
+var eventSinkShared EventSink = TerminalSink{Output: os.Stdout}
+
+// SetEventSink sets where the events emitted during parsing and analysis
+// are sent, and returns the sink that they were sent to until then, so
+// that it may be restored. By default, events are rendered to standard
+// output by a TerminalSink. It should not be called while a model is being
+// verified.
+func SetEventSink(s EventSink) EventSink {
+	eventSinkSaved := eventSinkShared
+	eventSinkShared = s
+	return eventSinkSaved
+}
+
+func eventEmit(e Event) {
+	eventSinkShared.Event(e)
+}
+
+type eventSinkDiscard struct{}
+
+func (eventSinkDiscard) Event(e Event) {}
+
+func (EventParseStarted) eventName() string {
+	return "parseStarted"
+}
+
+func (EventPhaseStarted) eventName() string {
+	return "phaseStarted"
+}
+
+func (EventStageStarted) eventName() string {
+	return "stageStarted"
+}
+
+func (EventAnalysis) eventName() string {
+	return "analysis"
+}
+
+func (EventQueryResolved) eventName() string {
+	return "queryResolved"
+}
+
+func (EventVerificationFinished) eventName() string {
+	return "verificationFinished"
+}
+
+func (EventMessage) eventName() string {
+	return "message"
+}
+
+// EventName returns the name under which an event is reported outside of
+// Go, such as in the notifications sent by Serve.
+func EventName(e Event) string {
+	return e.eventName()
+}
@@ -0,0 +1,353 @@
+/* SPDX-FileCopyrightText: © 2019-2020 Nadim Kobeissi <nadim@symbolic.software>
+ * SPDX-License-Identifier: GPL-3.0-only */
+// 00000000000000000000000000000000
+
+package vplogic
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// fragmentResolve loads every file imported by the model, gathers the
+// fragments they declare and expands each `use` block into the blocks of
+// the fragment it refers to, so that the rest of Verifpal only ever sees
+// principal, message and phase blocks.
+func fragmentResolve(m Model, filePath string) (Model, error) {
+	switch {
+	case len(m.Attacker) == 0:
+		return Model{}, errors.New("no `attacker` block defined")
+	case len(m.Queries) == 0:
+		return Model{}, errors.New("no `queries` block defined")
+	}
+	fragments := map[string]Fragment{}
+	imported := map[string]bool{filepath.Clean(filePath): true}
+	err := fragmentCollect(filePath, m.Imports, m.Blocks, fragments, imported)
+	if err != nil {
+		return Model{}, err
+	}
+	blocks, err := fragmentExpand(filePath, m.Blocks, fragments, []string{}, nil)
+	if err != nil {
+		return Model{}, err
+	}
+	m.Imports = []Import{}
+	m.Blocks = blocks
+	return m, nil
+}
+
+func fragmentCollect(
+	filePath string, imports []Import, blocks []Block,
+	fragments map[string]Fragment, imported map[string]bool,
+) error {
+	for _, blck := range blocks {
+		if blck.Kind != BlockKindFragment {
+			continue
+		}
+		f := blck.Fragment
+		f.File = filePath
+		if ff, ok := fragments[f.Name]; ok {
+			return fmt.Errorf(
+				"%s: fragment declared more than once (%s), first declared at %s",
+				fragmentPosition(filePath, f.Line, f.Column), f.Name,
+				fragmentPosition(ff.File, ff.Line, ff.Column),
+			)
+		}
+		for _, b := range f.Blocks {
+			if b.Kind == BlockKindFragment {
+				return fmt.Errorf(
+					"%s: fragment declared inside another fragment (%s)",
+					fragmentPosition(filePath, b.Fragment.Line, b.Fragment.Column),
+					b.Fragment.Name,
+				)
+			}
+		}
+		fragments[f.Name] = f
+	}
+	for _, imp := range imports {
+		importPath := filepath.Clean(filepath.Join(filepath.Dir(filePath), imp.Path))
+		if imported[importPath] {
+			continue
+		}
+		imported[importPath] = true
+		parsed, err := ParseFile(importPath)
+		if err != nil {
+			return fmt.Errorf(
+				"%s: cannot import (%s): %v",
+				fragmentPosition(filePath, imp.Line, imp.Column), imp.Path, err,
+			)
+		}
+		im := parsed.(Model)
+		if len(im.Attacker) > 0 || len(im.Queries) > 0 {
+			return fmt.Errorf(
+				"%s: imported file must only declare fragments (%s)",
+				fragmentPosition(filePath, imp.Line, imp.Column), imp.Path,
+			)
+		}
+		for _, b := range im.Blocks {
+			if b.Kind != BlockKindFragment {
+				return fmt.Errorf(
+					"%s: imported file must only declare fragments (%s)",
+					fragmentPosition(filePath, imp.Line, imp.Column), imp.Path,
+				)
+			}
+		}
+		err = fragmentCollect(importPath, im.Imports, im.Blocks, fragments, imported)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fragmentExpand expands the `use` blocks among blocks. Every block that
+// comes out of a fragment records, as its origin, the `use` block that it
+// was expanded from, so that errors can point back to it.
+func fragmentExpand(
+	filePath string, blocks []Block,
+	fragments map[string]Fragment, stack []string, origin *BlockOrigin,
+) ([]Block, error) {
+	expanded := []Block{}
+	for _, blck := range blocks {
+		switch blck.Kind {
+		case BlockKindFragment:
+			continue
+		case BlockKindRepeat:
+			rb, err := fragmentExpand(filePath, blck.Repeat.Blocks, fragments, stack, origin)
+			if err != nil {
+				return []Block{}, err
+			}
+			blck.Repeat.Blocks = rb
+			blck.Origin = origin
+			expanded = append(expanded, blck)
+		case BlockKindUse:
+			u := blck.Use
+			f, ok := fragments[u.Name]
+			if !ok {
+				return []Block{}, fmt.Errorf(
+					"%s: use of unknown fragment (%s)",
+					fragmentPosition(filePath, u.Line, u.Column), u.Name,
+				)
+			}
+			if strInSlice(u.Name, stack) {
+				return []Block{}, fmt.Errorf(
+					"%s: fragment uses itself (%s)",
+					fragmentPosition(filePath, u.Line, u.Column), u.Name,
+				)
+			}
+			if len(u.Arguments) != len(f.Parameters) {
+				return []Block{}, fmt.Errorf(
+					"%s: fragment (%s) expects %d arguments, got %d",
+					fragmentPosition(filePath, u.Line, u.Column), u.Name,
+					len(f.Parameters), len(u.Arguments),
+				)
+			}
+			args := map[string]string{}
+			for i, p := range f.Parameters {
+				args[p] = u.Arguments[i]
+			}
+			locals := fragmentLocals(f, u.Arguments)
+			blocks := make([]Block, len(f.Blocks))
+			for i, b := range f.Blocks {
+				blocks[i] = fragmentSubstituteBlock(b, args, locals)
+			}
+			fb, err := fragmentExpand(f.File, blocks, fragments, append(stack, u.Name), &BlockOrigin{
+				Fragment: u.Name,
+				File:     filePath,
+				Line:     u.Line,
+				Column:   u.Column,
+				Parent:   origin,
+			})
+			if err != nil {
+				return []Block{}, err
+			}
+			expanded = append(expanded, fb...)
+		default:
+			blck.Origin = origin
+			expanded = append(expanded, blck)
+		}
+	}
+	return expanded, nil
+}
+
+func fragmentPosition(filePath string, line int, column int) string {
+	return fmt.Sprintf("%s:%d:%d", filePath, line, column)
+}
+
+// fragmentOriginError prefixes an error raised by a block that was expanded
+// from a fragment with the positions of the `use` blocks that it was
+// expanded through, starting from the outermost one.
+func fragmentOriginError(blck Block, err error) error {
+	if err == nil || blck.Origin == nil {
+		return err
+	}
+	uses := []string{}
+	for o := blck.Origin; o != nil; o = o.Parent {
+		uses = append([]string{fmt.Sprintf(
+			"%s: in fragment %s", fragmentPosition(o.File, o.Line, o.Column), o.Fragment,
+		)}, uses...)
+	}
+	return fmt.Errorf("%s: %v", strings.Join(uses, ": "), err)
+}
+
+// fragmentSubstituteBlock returns a copy of a fragment block in which
+// every principal name and constant name matching one of the fragment's
+// parameters is replaced by the corresponding argument, and every constant
+// declared by the fragment itself is given its name for this use.
+func fragmentSubstituteBlock(blck Block, args map[string]string, locals map[string]string) Block {
+	return fragmentMapBlock(blck, func(name string) string {
+		if a, ok := args[strings.ToLower(name)]; ok {
+			return strings.Title(a)
+		}
+		return name
+	}, func(name string) string {
+		if a, ok := args[name]; ok {
+			return a
+		}
+		if l, ok := locals[name]; ok {
+			return l
+		}
+		return name
+	})
+}
+
+// fragmentLocals names the constants that a fragment declares itself,
+// rather than taking as parameters, for one use of the fragment. Each is
+// suffixed with the arguments of the use, so that `generates sk` within a
+// fragment used as `use KeyGen(Alice)` declares sk_alice, and a fragment
+// can be used once for each of several principals. Constants that are to
+// be shared with the rest of the model must therefore be parameters.
+func fragmentLocals(f Fragment, arguments []string) map[string]string {
+	locals := map[string]string{}
+	suffix := strings.Join(append([]string{""}, arguments...), "_")
+	var walk func(blocks []Block)
+	walk = func(blocks []Block) {
+		for _, b := range blocks {
+			switch b.Kind {
+			case BlockKindPrincipal:
+				for _, expr := range b.Principal.Expressions {
+					declared := expr.Constants
+					switch expr.Kind {
+					case ExpressionKindLeaks:
+						continue
+					case ExpressionKindAssignment:
+						declared = expr.Left
+					}
+					for _, c := range declared {
+						if strInSlice(c.Name, f.Parameters) || strings.HasPrefix(c.Name, "unnamed_") {
+							continue
+						}
+						locals[c.Name] = c.Name + suffix
+					}
+				}
+			case BlockKindRepeat:
+				walk(b.Repeat.Blocks)
+			}
+		}
+	}
+	walk(f.Blocks)
+	return locals
+}
+
+// fragmentMapBlock returns a copy of a block with every principal name
+// passed through principal and every constant name passed through constant.
+func fragmentMapBlock(
+	blck Block, principal func(string) string, constant func(string) string,
+) Block {
+	switch blck.Kind {
+	case BlockKindPrincipal:
+		expressions := make([]Expression, len(blck.Principal.Expressions))
+		for i, expr := range blck.Principal.Expressions {
+			expressions[i] = Expression{
+				Kind:      expr.Kind,
+				Qualifier: expr.Qualifier,
+				Constants: fragmentMapConstants(expr.Constants, constant),
+				Left:      fragmentRenameUnnamed(fragmentMapConstants(expr.Left, constant)),
+				Right:     fragmentMapValue(expr.Right, constant),
+			}
+		}
+		blck.Principal = Principal{
+			Name:        principal(blck.Principal.Name),
+			Expressions: expressions,
+		}
+	case BlockKindMessage:
+		recipients := make([]string, len(blck.Message.Recipients))
+		for i, r := range blck.Message.Recipients {
+			recipients[i] = principal(r)
+		}
+		blck.Message = Message{
+			Sender:     principal(blck.Message.Sender),
+			Recipient:  principal(blck.Message.Recipient),
+			Recipients: recipients,
+			Channel:    blck.Message.Channel,
+			Constants:  fragmentMapConstants(blck.Message.Constants, constant),
+		}
+	case BlockKindRepeat:
+		blocks := make([]Block, len(blck.Repeat.Blocks))
+		for i, b := range blck.Repeat.Blocks {
+			blocks[i] = fragmentMapBlock(b, principal, constant)
+		}
+		blck.Repeat.Blocks = blocks
+	case BlockKindUse:
+		arguments := make([]string, len(blck.Use.Arguments))
+		for i, a := range blck.Use.Arguments {
+			arguments[i] = constant(a)
+		}
+		blck.Use.Arguments = arguments
+	}
+	return blck
+}
+
+func fragmentMapConstants(c []Constant, constant func(string) string) []Constant {
+	if c == nil {
+		return nil
+	}
+	mapped := make([]Constant, len(c))
+	for i, v := range c {
+		mapped[i] = v
+		mapped[i].Name = constant(v.Name)
+	}
+	return mapped
+}
+
+func fragmentMapValue(a Value, constant func(string) string) Value {
+	switch a.Kind {
+	case ValueKindConstant:
+		a.Constant.Name = constant(a.Constant.Name)
+	case ValueKindPrimitive:
+		arguments := make([]Value, len(a.Primitive.Arguments))
+		for i, aa := range a.Primitive.Arguments {
+			arguments[i] = fragmentMapValue(aa, constant)
+		}
+		a.Primitive = Primitive{
+			Name:      a.Primitive.Name,
+			ID:        a.Primitive.ID,
+			Arguments: arguments,
+			Output:    a.Primitive.Output,
+			Check:     a.Primitive.Check,
+		}
+	case ValueKindEquation:
+		values := make([]Value, len(a.Equation.Values))
+		for i, aa := range a.Equation.Values {
+			values[i] = fragmentMapValue(aa, constant)
+		}
+		a.Equation = Equation{
+			Values: values,
+		}
+	}
+	return a
+}
+
+// fragmentRenameUnnamed gives fresh names to the unnamed constants on the
+// left-hand side of an assignment, since a block that is instantiated more
+// than once would otherwise assign the same unnamed constant twice.
+func fragmentRenameUnnamed(left []Constant) []Constant {
+	for i, c := range left {
+		if strings.HasPrefix(c.Name, "unnamed_") {
+			left[i].Name = fmt.Sprintf("unnamed_%d", libpegUnnamedCounter)
+			libpegUnnamedCounter = libpegUnnamedCounter + 1
+		}
+	}
+	return left
+}
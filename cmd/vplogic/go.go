@@ -6,6 +6,7 @@ package vplogic
 
 import (
 	"fmt"
+	"math/big"
 	"os"
 	"strings"
 )
@@ -25,6 +26,9 @@ func Go(modelFile string) error {
 }
 
 func goConstant(c Constant) string {
+	if valueConstantIsLiteral(c) {
+		return goLiteral(c)
+	}
 	if c.Guard {
 		return fmt.Sprintf("[%s]", c.Name)
 	}
@@ -34,6 +38,32 @@ func goConstant(c Constant) string {
 	return c.Name
 }
 
+func goLiteral(c Constant) string {
+	switch {
+	case strings.HasPrefix(c.Name, "\""):
+		return fmt.Sprintf("[]byte(%s)", c.Name)
+	case strings.HasPrefix(c.Name, "0x"):
+		digits := c.Name[2:]
+		if len(digits)%2 != 0 {
+			digits = "0" + digits
+		}
+		b := []string{}
+		for i := 0; i < len(digits); i = i + 2 {
+			b = append(b, fmt.Sprintf("0x%s", digits[i:i+2]))
+		}
+		return fmt.Sprintf("[]byte{%s}", strings.Join(b, ", "))
+	}
+	n, _ := new(big.Int).SetString(c.Name, 10)
+	b := []string{}
+	for _, v := range n.Bytes() {
+		b = append(b, fmt.Sprintf("0x%02x", v))
+	}
+	if len(b) == 0 {
+		b = append(b, "0x00")
+	}
+	return fmt.Sprintf("[]byte{%s}", strings.Join(b, ", "))
+}
+
 func goConstants(c []Constant) string {
 	goString := ""
 	for i, v := range c {
@@ -85,11 +115,11 @@ func goEquation(e Equation) string {
 
 func goValue(a Value) string {
 	switch a.Kind {
-	case "constant":
+	case ValueKindConstant:
 		return goConstant(a.Constant)
-	case "primitive":
+	case ValueKindPrimitive:
 		return goPrimitive(a.Primitive)
-	case "equation":
+	case ValueKindEquation:
 		return goEquation(a.Equation)
 	}
 	return ""
@@ -117,26 +147,26 @@ func goPrincipal(block Block, pc int) (string, int, error) {
 func goExpression(expression Expression) (string, error) {
 	output := ""
 	switch expression.Kind {
-	case "knows":
+	case ExpressionKindKnows:
 		output = fmt.Sprintf(
 			"%s %s %s",
 			expression.Kind,
 			expression.Qualifier,
 			goConstants(expression.Constants),
 		)
-	case "generates":
+	case ExpressionKindGenerates:
 		for _, c := range expression.Constants {
 			output = fmt.Sprintf(
 				"%s := GENERATES()\n\tif err != nil {\n\t\treturn err\n\t}",
 				c.Name,
 			)
 		}
-	case "leaks":
+	case ExpressionKindLeaks:
 		return "", fmt.Errorf("%s %s",
 			"`leaks` keywords do not make sense",
 			"in an implementation template",
 		)
-	case "assignment":
+	case ExpressionKindAssignment:
 		right := goValue(expression.Right)
 		left := []Constant{}
 		for i, c := range expression.Left {
@@ -156,9 +186,10 @@ func goExpression(expression Expression) (string, error) {
 
 func goMessage(block Block) string {
 	output := fmt.Sprintf(
-		"%s -> %s: %s\n\n",
+		"%s %s %s: %s\n\n",
 		block.Message.Sender,
-		block.Message.Recipient,
+		prettyMessageArrow(block.Message),
+		strings.Join(constructMessageRecipients(block.Message), ", "),
 		goConstants(block.Message.Constants),
 	)
 	return output
@@ -181,16 +212,16 @@ func goModel(m Model) (string, error) {
 	output := ""
 	for _, block := range m.Blocks {
 		switch block.Kind {
-		case "principal":
+		case BlockKindPrincipal:
 			p := ""
 			p, pc, err = goPrincipal(block, pc)
 			if err != nil {
 				return "", err
 			}
 			output = output + p
-		case "message":
+		case BlockKindMessage:
 			output = output + goMessage(block)
-		case "phase":
+		case BlockKindPhase:
 			output = output + goPhase(block)
 		}
 	}
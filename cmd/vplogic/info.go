@@ -6,59 +6,142 @@ package vplogic
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/logrusorgru/aurora"
 )
 
-// InfoMessage prints a Verifpal status message.
+// TerminalSink is the EventSink used by the verifpal command. It renders
+// events as the status messages shown in the terminal during analysis.
+type TerminalSink struct {
+	Output io.Writer
+}
+
+// Event renders e to the sink's output.
+func (s TerminalSink) Event(e Event) {
+	switch e := e.(type) {
+	case EventParseStarted:
+		infoMessage(s.Output, fmt.Sprintf(
+			"Parsing model '%s'...", e.FileName,
+		), "verifpal", 0)
+	case EventPhaseStarted:
+		if !e.Active {
+			break
+		}
+		infoMessage(s.Output, fmt.Sprintf(
+			"Running at phase %d.", e.Phase,
+		), "info", 0)
+	case EventAnalysis:
+		infoAnalysisRender(s.Output, e.Stage, e.Analysis)
+	case EventQueryResolved:
+		infoMessage(s.Output, fmt.Sprintf(
+			"%s: %s", prettyQuery(e.Result.Query), e.Result.Summary,
+		), "result", e.Analysis)
+	case EventVerificationFinished:
+		fmt.Fprint(s.Output, "\n\n")
+		for _, verifyResult := range e.Results {
+			if verifyResult.Resolved {
+				infoMessage(s.Output, fmt.Sprintf(
+					"%s: %s",
+					prettyQuery(verifyResult.Query),
+					verifyResult.Summary,
+				), "result", 0)
+			}
+			for i := 1; i < len(verifyResult.Attacks); i++ {
+				infoMessage(s.Output, fmt.Sprintf(
+					"%s (attack %d of %d): %s",
+					prettyQuery(verifyResult.Query), i+1, len(verifyResult.Attacks),
+					verifyResult.Attacks[i].Summary,
+				), "result", 0)
+			}
+		}
+		if e.Pruned > 0 {
+			infoMessage(s.Output, fmt.Sprintf(
+				"Skipped %d mutation combinations that led to principal states already analyzed. "+
+					"Combinations that only swap substituted values between constants are not detected as such and are still analyzed.",
+				e.Pruned,
+			), "info", 0)
+		}
+		completed := time.Now().Format("03:04:05 PM")
+		infoMessage(s.Output, fmt.Sprintf(
+			"Verification completed for '%s' at %s.", e.FileName, completed,
+		), "verifpal", 0)
+		infoMessage(s.Output, "Thank you for using Verifpal.", "verifpal", 0)
+	case EventMessage:
+		infoMessage(s.Output, e.Message, e.Kind, e.Analysis)
+	}
+}
+
+// InfoMessage emits a Verifpal status message.
 func InfoMessage(m string, t string, showAnalysis bool) {
 	analysisCount := 0
 	if showAnalysis {
 		analysisCount = verifyAnalysisCountGet()
 	}
+	eventEmit(EventMessage{
+		Kind:     t,
+		Message:  m,
+		Analysis: analysisCount,
+	})
+}
+
+func infoMessage(w io.Writer, m string, t string, analysisCount int) {
 	if colorOutputSupport() {
-		InfoMessageColor(m, t, analysisCount)
+		infoMessageColor(w, m, t, analysisCount)
 	} else {
-		InfoMessageRegular(m, t, analysisCount)
+		infoMessageRegular(w, m, t, analysisCount)
 	}
 }
 
+// InfoMessageRegular prints a Verifpal status message without color to
+// standard output.
 func InfoMessageRegular(m string, t string, analysisCount int) {
+	infoMessageRegular(os.Stdout, m, t, analysisCount)
+}
+
+// InfoMessageColor prints a Verifpal status message in color to standard
+// output.
+func InfoMessageColor(m string, t string, analysisCount int) {
+	infoMessageColor(os.Stdout, m, t, analysisCount)
+}
+
+func infoMessageRegular(w io.Writer, m string, t string, analysisCount int) {
 	infoString := ""
 	if analysisCount > 0 {
 		infoString = fmt.Sprintf("(Analysis %d)", analysisCount)
 	}
 	switch t {
 	case "verifpal":
-		fmt.Fprintf(os.Stdout,
+		fmt.Fprintf(w,
 			" Verifpal • %s %s\n", m, infoString,
 		)
 	case "info":
-		fmt.Fprintf(os.Stdout,
+		fmt.Fprintf(w,
 			"     Info • %s %s\n", m, infoString,
 		)
 	case "analysis":
-		fmt.Fprintf(os.Stdout,
+		fmt.Fprintf(w,
 			" Analysis • %s %s\n", m, infoString,
 		)
 	case "deduction":
-		fmt.Fprintf(os.Stdout,
+		fmt.Fprintf(w,
 			"Deduction • %s %s\n", m, infoString,
 		)
 	case "result":
-		fmt.Fprintf(os.Stdout,
+		fmt.Fprintf(w,
 			"   Result • %s %s\n", m, infoString,
 		)
 	case "warning":
-		fmt.Fprintf(os.Stdout,
+		fmt.Fprintf(w,
 			"  Warning • %s %s\n", m, infoString,
 		)
 	}
 }
 
-func InfoMessageColor(m string, t string, analysisCount int) {
+func infoMessageColor(w io.Writer, m string, t string, analysisCount int) {
 	infoString := ""
 	if analysisCount > 0 {
 		infoString = aurora.Faint(fmt.Sprintf(
@@ -67,32 +150,32 @@ func InfoMessageColor(m string, t string, analysisCount int) {
 	}
 	switch t {
 	case "verifpal":
-		fmt.Fprintf(os.Stdout,
+		fmt.Fprintf(w,
 			"%s%s%s %s %s\n",
 			" ", aurora.Green("Verifpal").Bold(), " •", m, infoString,
 		)
 	case "info":
-		fmt.Fprintf(os.Stdout,
+		fmt.Fprintf(w,
 			"%s%s%s %s %s\n",
 			"     ", aurora.Blue("Info").Bold(), " •", m, infoString,
 		)
 	case "analysis":
-		fmt.Fprintf(os.Stdout,
+		fmt.Fprintf(w,
 			"%s%s%s %s %s\n",
 			" ", aurora.Blue("Analysis").Bold(), " •", m, infoString,
 		)
 	case "deduction":
-		fmt.Fprintf(os.Stdout,
+		fmt.Fprintf(w,
 			"%s%s%s %s %s\n",
 			"", aurora.Magenta("Deduction").Bold(), " •", m, infoString,
 		)
 	case "result":
-		fmt.Fprintf(os.Stdout,
+		fmt.Fprintf(w,
 			"%s%s%s %s %s\n",
 			"   ", aurora.Red("Result").Bold(), " •", m, infoString,
 		)
 	case "warning":
-		fmt.Fprintf(os.Stdout,
+		fmt.Fprintf(w,
 			"%s%s%s %s %s\n",
 			"  ", aurora.Red("Warning").Bold(), " •", m, infoString,
 		)
@@ -135,12 +218,46 @@ func infoVerifyResultSummary(
 	)
 }
 
+func infoVerifyResultSummaryMinimal(summary string, minimal []string) string {
+	minimalSummary := ""
+	if len(minimal) == 0 {
+		minimalSummary = fmt.Sprintf(
+			"%sOf these, none need to be controlled by Attacker for this attack.\n",
+			"           ",
+		)
+	} else {
+		minimalSummary = fmt.Sprintf(
+			"%sOf these, controlling only the following is enough for this attack:\n",
+			"           ",
+		)
+	}
+	for _, m := range minimal {
+		minimalSummary = fmt.Sprintf(
+			"%s%s%s\n",
+			minimalSummary, "             - ", m,
+		)
+	}
+	if colorOutputSupport() {
+		return fmt.Sprintf("%s%s",
+			summary, aurora.BrightYellow(minimalSummary).Italic().String(),
+		)
+	}
+	return fmt.Sprintf("%s%s", summary, minimalSummary)
+}
+
 func infoAnalysis(stage int) {
-	a := ""
 	analysisCount := verifyAnalysisCountGet()
 	if analysisCount%500 != 0 {
 		return
 	}
+	eventEmit(EventAnalysis{
+		Stage:    stage,
+		Analysis: analysisCount,
+	})
+}
+
+func infoAnalysisRender(w io.Writer, stage int, analysisCount int) {
+	a := ""
 	if colorOutputSupport() {
 		a = aurora.Faint(fmt.Sprintf(
 			" Stage %d, Analysis %d...", stage, analysisCount,
@@ -148,8 +265,8 @@ func infoAnalysis(stage int) {
 	} else {
 		a = fmt.Sprintf(" Stage %d, Analysis %d...", stage, analysisCount)
 	}
-	fmt.Fprint(os.Stdout, a)
-	fmt.Fprint(os.Stdout, "\r \r")
+	fmt.Fprint(w, a)
+	fmt.Fprint(w, "\r \r")
 }
 
 func infoLiteralNumber(n int) string {
@@ -172,15 +289,15 @@ func infoLiteralNumber(n int) string {
 func infoOutputText(revealed Value) string {
 	outputText := prettyValue(revealed)
 	switch revealed.Kind {
-	case "constant":
+	case ValueKindConstant:
 		return outputText
-	case "primitive":
+	case ValueKindPrimitive:
 		oneOutput := false
 		if primitiveIsCorePrim(revealed.Primitive.Name) {
-			prim, _ := primitiveCoreGet(revealed.Primitive.Name)
+			prim, _ := primitiveCoreGet(revealed.Primitive)
 			oneOutput = prim.Output == 1
 		} else {
-			prim, _ := primitiveGet(revealed.Primitive.Name)
+			prim, _ := primitiveGet(revealed.Primitive)
 			oneOutput = prim.Output == 1
 		}
 		if oneOutput {
@@ -190,7 +307,7 @@ func infoOutputText(revealed Value) string {
 			strings.Title(infoLiteralNumber(revealed.Primitive.Output)),
 		)
 		return fmt.Sprintf("%s of %s", prefix, outputText)
-	case "equation":
+	case ValueKindEquation:
 		return outputText
 	default:
 		return outputText
@@ -11,18 +11,21 @@ import (
 
 func inject(
 	p Primitive, rootPrimitive Primitive, isRootPrimitive bool,
-	valPrincipalState PrincipalState, valAttackerState AttackerState, stage int,
+	valPrincipalState PrincipalState, valAttackerState AttackerState, stage int, depth int,
 ) []Value {
 	if verifyResultsAllResolved() {
 		return []Value{}
 	}
+	if injectDepthExceeded(depth, valAttackerState) {
+		return []Value{}
+	}
 	if primitiveIsCorePrim(p.Name) {
-		prim, _ := primitiveCoreGet(p.Name)
+		prim, _ := primitiveCoreGet(p)
 		if !prim.Injectable {
 			return []Value{}
 		}
 	} else {
-		prim, _ := primitiveGet(p.Name)
+		prim, _ := primitiveGet(p)
 		if !prim.Injectable {
 			return []Value{}
 		}
@@ -31,31 +34,56 @@ func inject(
 		rootPrimitive = p
 	}
 	return injectPrimitive(
-		p, rootPrimitive, valPrincipalState, valAttackerState, stage,
+		p, rootPrimitive, valPrincipalState, valAttackerState, stage, depth,
 	)
 }
 
+// injectDepthExceeded tells us whether an injected primitive at the given
+// nesting depth would exceed the bound set by a bounded-depth attacker.
+func injectDepthExceeded(depth int, valAttackerState AttackerState) bool {
+	switch valAttackerState.Profile {
+	case "bounded-depth":
+		return depth > valAttackerState.Depth
+	}
+	return false
+}
+
+func injectValueDepth(a Value) int {
+	switch a.Kind {
+	case ValueKindPrimitive:
+		depth := 0
+		for _, aa := range a.Primitive.Arguments {
+			aaDepth := injectValueDepth(aa)
+			if aaDepth > depth {
+				depth = aaDepth
+			}
+		}
+		return depth + 1
+	}
+	return 0
+}
+
 func injectValueRules(
 	k Value, arg int, p Primitive, rootPrimitive Primitive, stage int,
 ) bool {
 	if valueEquivalentValues(k, Value{
-		Kind:      "primitive",
+		Kind:      ValueKindPrimitive,
 		Primitive: p,
 	}, true) {
 		return false
 	}
 	if valueEquivalentValues(k, Value{
-		Kind:      "primitive",
+		Kind:      ValueKindPrimitive,
 		Primitive: rootPrimitive,
 	}, true) {
 		return false
 	}
 	switch k.Kind {
-	case "constant":
+	case ValueKindConstant:
 		return injectConstantRules(k.Constant, arg, p)
-	case "primitive":
+	case ValueKindPrimitive:
 		return injectPrimitiveRules(k.Primitive, arg, p, stage)
-	case "equation":
+	case ValueKindEquation:
 		return injectEquationRules(k.Equation, arg, p)
 	}
 	return true
@@ -63,7 +91,7 @@ func injectValueRules(
 
 func injectConstantRules(c Constant, arg int, p Primitive) bool {
 	switch {
-	case p.Arguments[arg].Kind != "constant":
+	case p.Arguments[arg].Kind != ValueKindConstant:
 		return false
 	case strings.ToLower(c.Name) == "g":
 		return false
@@ -73,7 +101,7 @@ func injectConstantRules(c Constant, arg int, p Primitive) bool {
 
 func injectPrimitiveRules(k Primitive, arg int, p Primitive, stage int) bool {
 	switch {
-	case p.Arguments[arg].Kind != "primitive":
+	case p.Arguments[arg].Kind != ValueKindPrimitive:
 		return false
 	case injectPrimitiveStageRestricted(k, stage):
 		return false
@@ -85,7 +113,7 @@ func injectPrimitiveRules(k Primitive, arg int, p Primitive, stage int) bool {
 
 func injectEquationRules(e Equation, arg int, p Primitive) bool {
 	switch {
-	case p.Arguments[arg].Kind != "equation":
+	case p.Arguments[arg].Kind != ValueKindEquation:
 		return false
 	case len(e.Values) != len(p.Arguments[arg].Equation.Values):
 		return false
@@ -107,10 +135,10 @@ func injectPrimitiveStageRestricted(p Primitive, stage int) bool {
 	case 2:
 		explosive := false
 		if primitiveIsCorePrim(p.Name) {
-			prim, _ := primitiveCoreGet(p.Name)
+			prim, _ := primitiveCoreGet(p)
 			explosive = prim.Explosive
 		} else {
-			prim, _ := primitiveGet(p.Name)
+			prim, _ := primitiveGet(p)
 			explosive = prim.Explosive
 		}
 		return explosive
@@ -125,21 +153,22 @@ func injectPrimitiveStageRestricted(p Primitive, stage int) bool {
 func injectPrimitiveSkeleton(p Primitive) Primitive {
 	skeleton := Primitive{
 		Name:      p.Name,
+		ID:        p.ID,
 		Arguments: make([]Value, len(p.Arguments)),
 		Output:    p.Output,
 		Check:     false,
 	}
 	for i, a := range p.Arguments {
 		switch a.Kind {
-		case "constant":
+		case ValueKindConstant:
 			skeleton.Arguments[i] = valueN
-		case "primitive":
+		case ValueKindPrimitive:
 			aa := Value{
-				Kind:      "primitive",
+				Kind:      ValueKindPrimitive,
 				Primitive: injectPrimitiveSkeleton(a.Primitive),
 			}
 			skeleton.Arguments[i] = aa
-		case "equation":
+		case ValueKindEquation:
 			skeleton.Arguments[i] = valueN
 		}
 	}
@@ -150,8 +179,8 @@ func injectMatchSkeletons(p Primitive, skeleton Primitive) bool {
 	if p.Name != skeleton.Name {
 		return false
 	}
-	pv := Value{Kind: "primitive", Primitive: injectPrimitiveSkeleton(p)}
-	sv := Value{Kind: "primitive", Primitive: skeleton}
+	pv := Value{Kind: ValueKindPrimitive, Primitive: injectPrimitiveSkeleton(p)}
+	sv := Value{Kind: ValueKindPrimitive, Primitive: skeleton}
 	return valueEquivalentValues(pv, sv, true)
 }
 
@@ -161,7 +190,7 @@ func injectMissingSkeletons(p Primitive, valAttackerState AttackerState) {
 SkeletonSearch:
 	for _, a := range valAttackerState.Known {
 		switch a.Kind {
-		case "primitive":
+		case ValueKindPrimitive:
 			if injectMatchSkeletons(a.Primitive, skeleton) {
 				matchingSkeleton = true
 				break SkeletonSearch
@@ -170,7 +199,7 @@ SkeletonSearch:
 	}
 	if !matchingSkeleton {
 		known := Value{
-			Kind:      "primitive",
+			Kind:      ValueKindPrimitive,
 			Primitive: skeleton,
 		}
 		if attackerStatePutWrite(known) {
@@ -182,7 +211,7 @@ SkeletonSearch:
 	}
 	for _, a := range p.Arguments {
 		switch a.Kind {
-		case "primitive":
+		case ValueKindPrimitive:
 			injectMissingSkeletons(a.Primitive, valAttackerState)
 		}
 	}
@@ -191,7 +220,7 @@ SkeletonSearch:
 func injectPrimitive(
 	p Primitive, rootPrimitive Primitive,
 	valPrincipalState PrincipalState, valAttackerState AttackerState,
-	stage int,
+	stage int, depth int,
 ) []Value {
 	if injectPrimitiveStageRestricted(p, stage) {
 		return []Value{}
@@ -201,7 +230,7 @@ func injectPrimitive(
 	for arg := range p.Arguments {
 		for _, k := range valAttackerState.Known {
 			switch k.Kind {
-			case "constant":
+			case ValueKindConstant:
 				i := valueGetPrincipalStateIndexFromConstant(
 					valPrincipalState, k.Constant,
 				)
@@ -211,18 +240,21 @@ func injectPrimitive(
 				continue
 			}
 			switch k.Kind {
-			case "constant":
+			case ValueKindConstant:
 				kinjectants[arg] = append(kinjectants[arg], k)
-			case "primitive":
+			case ValueKindPrimitive:
+				if injectDepthExceeded(depth+injectValueDepth(k), valAttackerState) {
+					continue
+				}
 				kinjectants[arg] = append(kinjectants[arg], k)
 				if stage <= 3 {
 					continue
 				}
 				kinjectants[arg] = append(kinjectants[arg], inject(
 					k.Primitive, rootPrimitive, false,
-					valPrincipalState, valAttackerState, stage,
+					valPrincipalState, valAttackerState, stage, depth+1,
 				)...)
-			case "equation":
+			case ValueKindEquation:
 				kinjectants[arg] = append(kinjectants[arg], k)
 			}
 		}
@@ -230,165 +262,93 @@ func injectPrimitive(
 	return injectLoopN(p, kinjectants)
 }
 
-func injectLoopN(p Primitive, kinjectants [][]Value) []Value {
-	allInjectants := []Value{}
-	uniqueInjectants := []Value{}
-	switch len(p.Arguments) {
-	case 1:
-		allInjectants = injectLoop1(p, kinjectants)
-	case 2:
-		allInjectants = injectLoop2(p, kinjectants)
-	case 3:
-		allInjectants = injectLoop3(p, kinjectants)
-	case 4:
-		allInjectants = injectLoop4(p, kinjectants)
-	case 5:
-		allInjectants = injectLoop5(p, kinjectants)
+// injectMalleable returns the ciphertexts that the attacker can derive from
+// a STREAM_ENC ciphertext it sees on the wire without knowing its key.
+// XOR-ing a known value into the ciphertext flips the same bits of its
+// plaintext, and knowing the plaintext lets the attacker swap it for any
+// other value it knows.
+func injectMalleable(
+	p Primitive, valPrincipalState PrincipalState, valAttackerState AttackerState,
+	stage int,
+) []Value {
+	injectants := []Value{}
+	if p.Name != "STREAM_ENC" || injectPrimitiveStageRestricted(p, stage) {
+		return injectants
 	}
-	for _, a := range allInjectants {
-		if valueEquivalentValueInValues(a, uniqueInjectants) < 0 {
-			uniqueInjectants = append(uniqueInjectants, a)
+	m := p.Arguments[1]
+	substitute := possibleToObtainValue(m, valAttackerState)
+	for _, k := range valAttackerState.Known {
+		if k.Kind != ValueKindConstant || valueIsGOrNil(k.Constant) {
+			continue
 		}
-	}
-	return uniqueInjectants
-}
-
-func injectLoop1(p Primitive, kinjectants [][]Value) []Value {
-	injectants := []Value{}
-	for i := range kinjectants[0] {
-		if verifyResultsAllResolved() {
-			return []Value{}
+		k = valueResolveConstant(k.Constant, valPrincipalState)
+		if k.Kind != ValueKindConstant {
+			continue
 		}
-		aa := Value{
-			Kind: "primitive",
+		plaintexts := []Value{{
+			Kind: ValueKindPrimitive,
 			Primitive: Primitive{
-				Name: p.Name,
-				Arguments: []Value{
-					kinjectants[0][i],
-				},
-				Output: p.Output,
-				Check:  p.Check,
+				Name:      "XOR",
+				ID:        primitiveIDXor,
+				Arguments: []Value{m, k},
+				Output:    0,
+				Check:     false,
 			},
+		}}
+		if substitute {
+			plaintexts = append(plaintexts, k)
 		}
-		injectants = append(injectants, aa)
-	}
-	return injectants
-}
-
-func injectLoop2(p Primitive, kinjectants [][]Value) []Value {
-	injectants := []Value{}
-	for i := range kinjectants[0] {
-		if verifyResultsAllResolved() {
-			return []Value{}
-		}
-		for ii := range kinjectants[1] {
-			aa := Value{
-				Kind: "primitive",
+		for _, mm := range plaintexts {
+			injectants = append(injectants, Value{
+				Kind: ValueKindPrimitive,
 				Primitive: Primitive{
-					Name: p.Name,
-					Arguments: []Value{
-						kinjectants[0][i],
-						kinjectants[1][ii],
-					},
-					Output: p.Output,
-					Check:  p.Check,
+					Name:      p.Name,
+					Arguments: []Value{p.Arguments[0], mm},
+					Output:    p.Output,
+					Check:     p.Check,
 				},
-			}
-			injectants = append(injectants, aa)
+			})
 		}
 	}
 	return injectants
 }
 
-func injectLoop3(p Primitive, kinjectants [][]Value) []Value {
-	injectants := []Value{}
-	for i := range kinjectants[0] {
-		if verifyResultsAllResolved() {
-			return []Value{}
-		}
-		for ii := range kinjectants[1] {
-			for iii := range kinjectants[2] {
-				aa := Value{
-					Kind: "primitive",
-					Primitive: Primitive{
-						Name: p.Name,
-						Arguments: []Value{
-							kinjectants[0][i],
-							kinjectants[1][ii],
-							kinjectants[2][iii],
-						},
-						Output: p.Output,
-						Check:  p.Check,
-					},
-				}
-				injectants = append(injectants, aa)
-			}
+func injectLoopN(p Primitive, kinjectants [][]Value) []Value {
+	allInjectants := injectLoop(p, kinjectants, []Value{})
+	uniqueInjectants := []Value{}
+	for _, a := range allInjectants {
+		if valueEquivalentValueInValues(a, uniqueInjectants) < 0 {
+			uniqueInjectants = append(uniqueInjectants, a)
 		}
 	}
-	return injectants
+	return uniqueInjectants
 }
 
-func injectLoop4(p Primitive, kinjectants [][]Value) []Value {
-	injectants := []Value{}
-	for i := range kinjectants[0] {
-		if verifyResultsAllResolved() {
-			return []Value{}
-		}
-		for ii := range kinjectants[1] {
-			for iii := range kinjectants[2] {
-				for iiii := range kinjectants[3] {
-					aa := Value{
-						Kind: "primitive",
-						Primitive: Primitive{
-							Name: p.Name,
-							Arguments: []Value{
-								kinjectants[0][i],
-								kinjectants[1][ii],
-								kinjectants[2][iii],
-								kinjectants[3][iiii],
-							},
-							Output: p.Output,
-							Check:  p.Check,
-						},
-					}
-					injectants = append(injectants, aa)
-				}
-			}
-		}
+// injectLoop builds every combination of injectants for the arguments of a
+// primitive, whatever its arity, with the first argument varying slowest.
+func injectLoop(p Primitive, kinjectants [][]Value, arguments []Value) []Value {
+	if len(arguments) == len(kinjectants) {
+		a := make([]Value, len(arguments))
+		copy(a, arguments)
+		return []Value{{
+			Kind: ValueKindPrimitive,
+			Primitive: Primitive{
+				Name:      p.Name,
+				ID:        p.ID,
+				Arguments: a,
+				Output:    p.Output,
+				Check:     p.Check,
+			},
+		}}
 	}
-	return injectants
-}
-
-func injectLoop5(p Primitive, kinjectants [][]Value) []Value {
 	injectants := []Value{}
-	for i := range kinjectants[0] {
-		if verifyResultsAllResolved() {
+	for _, k := range kinjectants[len(arguments)] {
+		if len(arguments) == 0 && verifyResultsAllResolved() {
 			return []Value{}
 		}
-		for ii := range kinjectants[1] {
-			for iii := range kinjectants[2] {
-				for iiii := range kinjectants[3] {
-					for iiiii := range kinjectants[4] {
-						aa := Value{
-							Kind: "primitive",
-							Primitive: Primitive{
-								Name: p.Name,
-								Arguments: []Value{
-									kinjectants[0][i],
-									kinjectants[1][ii],
-									kinjectants[2][iii],
-									kinjectants[3][iiii],
-									kinjectants[4][iiiii],
-								},
-								Output: p.Output,
-								Check:  p.Check,
-							},
-						}
-						injectants = append(injectants, aa)
-					}
-				}
-			}
-		}
+		injectants = append(injectants, injectLoop(
+			p, kinjectants, append(arguments, k),
+		)...)
 	}
 	return injectants
 }
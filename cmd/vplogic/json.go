@@ -5,6 +5,7 @@ package vplogic
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -112,7 +113,7 @@ func JsonVerify(inputString string) error {
 	if err != nil {
 		return err
 	}
-	valVerifyResults, _, err := verifyModel(m.(Model))
+	valVerifyResults, _, err := VerifyModel(context.Background(), m.(Model))
 	if err != nil {
 		return err
 	}
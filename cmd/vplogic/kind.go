@@ -0,0 +1,212 @@
+/* SPDX-FileCopyrightText: © 2019-2020 Nadim Kobeissi <nadim@symbolic.software>
+ * SPDX-License-Identifier: GPL-3.0-only */
+// 00000000000000000000000000000000
+
+package vplogic
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Kinds are stored as integers, so that they are cheap to compare during
+// analysis, but are written out as the same strings used in models, so that
+// their JSON encoding is unchanged. The zero value of each kind stands for
+// no kind at all, and is written out as an empty string.
+const (
+	ValueKindConstant ValueKind = iota + 1
+	ValueKindPrimitive
+	ValueKindEquation
+)
+
+const (
+	ExpressionKindKnows ExpressionKind = iota + 1
+	ExpressionKindGenerates
+	ExpressionKindLeaks
+	ExpressionKindAssignment
+)
+
+const (
+	BlockKindPrincipal BlockKind = iota + 1
+	BlockKindMessage
+	BlockKindPhase
+	BlockKindFragment
+	BlockKindUse
+	BlockKindRepeat
+)
+
+const (
+	QueryKindConfidentiality QueryKind = iota + 1
+	QueryKindAuthentication
+	QueryKindFreshness
+	QueryKindUnlinkability
+)
+
+const (
+	QueryOptionKindPrecondition QueryOptionKind = iota + 1
+)
+
+var valueKindNames = []string{"", "constant", "primitive", "equation"}
+
+var expressionKindNames = []string{"", "knows", "generates", "leaks", "assignment"}
+
+var blockKindNames = []string{"", "principal", "message", "phase", "fragment", "use", "repeat"}
+
+var queryKindNames = []string{"", "confidentiality", "authentication", "freshness", "unlinkability"}
+
+var queryOptionKindNames = []string{"", "precondition"}
+
+func (k ValueKind) String() string {
+	if int(k) < len(valueKindNames) {
+		return valueKindNames[k]
+	}
+	return fmt.Sprintf("ValueKind(%d)", int(k))
+}
+
+func (k ValueKind) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.String())
+}
+
+func (k *ValueKind) UnmarshalJSON(b []byte) error {
+	s := ""
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return err
+	}
+	kind, err := valueKindFromString(s)
+	*k = kind
+	return err
+}
+
+func valueKindFromString(s string) (ValueKind, error) {
+	for i, name := range valueKindNames {
+		if name == s {
+			return ValueKind(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid value kind (%s)", s)
+}
+
+func (k ExpressionKind) String() string {
+	if int(k) < len(expressionKindNames) {
+		return expressionKindNames[k]
+	}
+	return fmt.Sprintf("ExpressionKind(%d)", int(k))
+}
+
+func (k ExpressionKind) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.String())
+}
+
+func (k *ExpressionKind) UnmarshalJSON(b []byte) error {
+	s := ""
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return err
+	}
+	kind, err := expressionKindFromString(s)
+	*k = kind
+	return err
+}
+
+func expressionKindFromString(s string) (ExpressionKind, error) {
+	for i, name := range expressionKindNames {
+		if name == s {
+			return ExpressionKind(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid expression kind (%s)", s)
+}
+
+func (k BlockKind) String() string {
+	if int(k) < len(blockKindNames) {
+		return blockKindNames[k]
+	}
+	return fmt.Sprintf("BlockKind(%d)", int(k))
+}
+
+func (k BlockKind) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.String())
+}
+
+func (k *BlockKind) UnmarshalJSON(b []byte) error {
+	s := ""
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return err
+	}
+	kind, err := blockKindFromString(s)
+	*k = kind
+	return err
+}
+
+func blockKindFromString(s string) (BlockKind, error) {
+	for i, name := range blockKindNames {
+		if name == s {
+			return BlockKind(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid block kind (%s)", s)
+}
+
+func (k QueryKind) String() string {
+	if int(k) < len(queryKindNames) {
+		return queryKindNames[k]
+	}
+	return fmt.Sprintf("QueryKind(%d)", int(k))
+}
+
+func (k QueryKind) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.String())
+}
+
+func (k *QueryKind) UnmarshalJSON(b []byte) error {
+	s := ""
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return err
+	}
+	kind, err := queryKindFromString(s)
+	*k = kind
+	return err
+}
+
+func queryKindFromString(s string) (QueryKind, error) {
+	for i, name := range queryKindNames {
+		if name == s {
+			return QueryKind(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid query kind (%s)", s)
+}
+
+func (k QueryOptionKind) String() string {
+	if int(k) < len(queryOptionKindNames) {
+		return queryOptionKindNames[k]
+	}
+	return fmt.Sprintf("QueryOptionKind(%d)", int(k))
+}
+
+func (k QueryOptionKind) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.String())
+}
+
+func (k *QueryOptionKind) UnmarshalJSON(b []byte) error {
+	s := ""
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return err
+	}
+	kind, err := queryOptionKindFromString(s)
+	*k = kind
+	return err
+}
+
+func queryOptionKindFromString(s string) (QueryOptionKind, error) {
+	for i, name := range queryOptionKindNames {
+		if name == s {
+			return QueryOptionKind(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid query option kind (%s)", s)
+}
@@ -269,6 +269,37 @@ var libgo = strings.Join([]string{
 	"\treturn plaintext, nil",
 	"}",
 	"",
+	"func STREAM_ENC(k []byte, plaintext []byte) ([]byte, error) {",
+	"\tblock, err := aes.NewCipher(k)",
+	"\tif err != nil {",
+	"\t\treturn []byte{}, err",
+	"\t}",
+	"\tiv := make([]byte, aes.BlockSize)",
+	"\t_, err = rand.Read(iv)",
+	"\tif err != nil {",
+	"\t\treturn []byte{}, err",
+	"\t}",
+	"\tstream := cipher.NewCTR(block, iv)",
+	"\tciphertext := make([]byte, len(plaintext))",
+	"\tstream.XORKeyStream(ciphertext, plaintext)",
+	"\treturn append(iv, ciphertext...), nil",
+	"}",
+	"",
+	"func STREAM_DEC(k []byte, ciphertext []byte) ([]byte, error) {",
+	"\tblock, err := aes.NewCipher(k)",
+	"\tif err != nil {",
+	"\t\treturn []byte{}, err",
+	"\t}",
+	"\tif len(ciphertext) < aes.BlockSize {",
+	"\t\treturn []byte{}, fmt.Errorf(\"invalid ciphertext\")",
+	"\t}",
+	"\tiv := ciphertext[:aes.BlockSize]",
+	"\tstream := cipher.NewCTR(block, iv)",
+	"\tplaintext := make([]byte, len(ciphertext[aes.BlockSize:]))",
+	"\tstream.XORKeyStream(plaintext, ciphertext[aes.BlockSize:])",
+	"\treturn plaintext, nil",
+	"}",
+	"",
 	"func AEAD_ENC(k []byte, plaintext []byte, ad []byte) ([]byte, error) {",
 	"\tnonce := make([]byte, chacha20poly1305.NonceSizeX)",
 	"\t_, err := rand.Read(nonce)",
@@ -322,6 +353,33 @@ var libgo = strings.Join([]string{
 	"\treturn plaintext, err",
 	"}",
 	"",
+	"func KEM_ENCAPS(pk []byte, r ...[]byte) ([]byte, []byte, error) {",
+	"\tesk, epk, err := ed25519Gen()",
+	"\tif err != nil {",
+	"\t\treturn []byte{}, []byte{}, err",
+	"\t}",
+	"\tif len(r) > 0 {",
+	"\t\tk := ed25519.NewKeyFromSeed(HASH(r[0]))",
+	"\t\tesk, epk = k, k.Public().(ed25519.PublicKey)",
+	"\t}",
+	"\tss, err := x25519DhFromEd25519PublicKey(esk, pk)",
+	"\tif err != nil {",
+	"\t\treturn []byte{}, []byte{}, err",
+	"\t}",
+	"\treturn epk, HASH(epk, ss), nil",
+	"}",
+	"",
+	"func KEM_DECAPS(k []byte, ct []byte) ([]byte, error) {",
+	"\tif len(ct) != 32 {",
+	"\t\treturn []byte{}, fmt.Errorf(\"invalid ciphertext\")",
+	"\t}",
+	"\tss, err := x25519DhFromEd25519PublicKey(k, ct)",
+	"\tif err != nil {",
+	"\t\treturn []byte{}, err",
+	"\t}",
+	"\treturn HASH(ct, ss), nil",
+	"}",
+	"",
 	"func SIGN(k []byte, message []byte) []byte {",
 	"\treturn ed25519.Sign(k, message)",
 	"}",
@@ -330,11 +388,19 @@ var libgo = strings.Join([]string{
 	"\treturn ed25519.Verify(pk, message, signature)",
 	"}",
 	"",
-	"func RINGSIGN(ka []byte, kb []byte, kc []byte, message []byte) []byte {",
+	"func RINGSIGN(k []byte, a ...[]byte) []byte {",
 	"\treturn []byte{}",
 	"}",
 	"",
-	"func RINGSIGNVERIF(pka []byte, pkb []byte, pkc []byte, message []byte, signature []byte) bool {",
+	"func RINGSIGNVERIF(a ...[]byte) bool {",
+	"\treturn false",
+	"}",
+	"",
+	"func THRESHOLD_SIGN(share []byte, message []byte) []byte {",
+	"\treturn []byte{}",
+	"}",
+	"",
+	"func THRESHOLD_VERIF(pk []byte, message []byte, signatures ...[]byte) bool {",
 	"\treturn false",
 	"}",
 	"",
@@ -346,11 +412,46 @@ var libgo = strings.Join([]string{
 	"\treturn []byte{}",
 	"}",
 	"",
-	"func SHAMIR_SPLIT(x []byte) []byte {",
+	"func COMMIT(value []byte, randomness []byte) ([]byte, error) {",
+	"\treturn MAC(randomness, value)",
+	"}",
+	"",
+	"func OPEN(commitment []byte, value []byte, randomness []byte) ([]byte, error) {",
+	"\tc, err := COMMIT(value, randomness)",
+	"\tif err != nil {",
+	"\t\treturn []byte{}, err",
+	"\t}",
+	"\tif !hmac.Equal(commitment, c) {",
+	"\t\treturn []byte{}, fmt.Errorf(\"commitment opening failed\")",
+	"\t}",
+	"\treturn value, nil",
+	"}",
+	"",
+	"func ZKP_PROVE(witness []byte, statement []byte) []byte {",
+	"\treturn []byte{}",
+	"}",
+	"",
+	"func ZKP_VERIFY(proof []byte, statement []byte) bool {",
+	"\treturn false",
+	"}",
+	"",
+	"func XOR(a []byte, b []byte) []byte {",
+	"\tif len(a) < len(b) {",
+	"\t\ta, b = b, a",
+	"\t}",
+	"\tc := make([]byte, len(a))",
+	"\tcopy(c, a)",
+	"\tfor i := range b {",
+	"\t\tc[i] = c[i] ^ b[i]",
+	"\t}",
+	"\treturn c",
+	"}",
+	"",
+	"func SHAMIR_SPLIT(x []byte, t ...[]byte) []byte {",
 	"\treturn []byte{}",
 	"}",
 	"",
-	"func SHAMIR_JOIN(a []byte, b []byte, c []byte) []byte {",
+	"func SHAMIR_JOIN(shares ...[]byte) []byte {",
 	"\treturn []byte{}",
 	"}",
 	"",
@@ -30,10 +30,18 @@ var libpegReserved = []string{
 	"aead_enc", "aead_dec", "enc", "dec",
 	"mac", "assert", "sign", "signverif",
 	"pke_enc", "pke_dec", "shamir_split",
-	"shamir_join", "concat", "split",
+	"shamir_join", "concat", "split", "xor",
+	"kem_encaps", "kem_decaps",
+	"zkp_prove", "zkp_verify",
+	"threshold_sign", "threshold_verif",
+	"stream_enc", "stream_dec",
 	"g", "nil", "unnamed",
 }
 
+// COMMIT and OPEN, along with import, fragment, use and repeat, are only
+// keywords where the grammar expects them, so that models written before
+// they were introduced may go on using them as names.
+
 var libpegUnnamedCounter = 0
 
 func libpegCheckIfReserved(s string) error {
@@ -45,6 +53,8 @@ func libpegCheckIfReserved(s string) error {
 		found = true
 	case strings.HasPrefix(s, "unnamed"):
 		found = true
+	case valueConstantIsLiteral(Constant{Name: s}):
+		found = true
 	}
 	if found {
 		return fmt.Errorf("cannot use reserved keyword in Name: %s", s)
@@ -61,16 +71,35 @@ func libpegParseModel(filePath string, verbose bool) (Model, error) {
 		return Model{}, fmt.Errorf("model file name must have a '.vp' extension")
 	}
 	if verbose {
-		InfoMessage(fmt.Sprintf(
-			"Parsing model '%s'...", fileName,
-		), "verifpal", false)
+		eventEmit(EventParseStarted{FileName: fileName})
 	}
 	parsed, err := ParseFile(filePath)
 	if err != nil {
 		return Model{}, err
 	}
-	m := parsed.(Model)
-	m.FileName = fileName
+	return libpegResolveModel(parsed.(Model), filePath)
+}
+
+// ParseModel parses a Verifpal model from its source text. Fragments that
+// it imports are resolved relative to filePath, which also names the model.
+func ParseModel(filePath string, b []byte) (Model, error) {
+	parsed, err := Parse(filePath, b)
+	if err != nil {
+		return Model{}, err
+	}
+	return libpegResolveModel(parsed.(Model), filePath)
+}
+
+func libpegResolveModel(m Model, filePath string) (Model, error) {
+	m, err := fragmentResolve(m, filePath)
+	if err != nil {
+		return Model{}, err
+	}
+	m, err = repeatUnroll(m)
+	if err != nil {
+		return Model{}, err
+	}
+	m.FileName = filepath.Base(filePath)
 	return m, nil
 }
 
@@ -78,117 +107,620 @@ var g = &grammar{
 	rules: []*rule{
 		{
 			name: "Model",
-			pos:  position{line: 79, col: 1, offset: 1743},
+			pos:  position{line: 108, col: 1, offset: 2716},
 			expr: &actionExpr{
-				pos: position{line: 79, col: 10, offset: 1752},
+				pos: position{line: 108, col: 10, offset: 2725},
 				run: (*parser).callonModel1,
 				expr: &seqExpr{
-					pos: position{line: 79, col: 10, offset: 1752},
+					pos: position{line: 108, col: 10, offset: 2725},
 					exprs: []interface{}{
 						&zeroOrMoreExpr{
-							pos: position{line: 79, col: 10, offset: 1752},
+							pos: position{line: 108, col: 10, offset: 2725},
 							expr: &ruleRefExpr{
-								pos:  position{line: 79, col: 10, offset: 1752},
+								pos:  position{line: 108, col: 10, offset: 2725},
 								name: "Comment",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 79, col: 19, offset: 1761},
+							pos:   position{line: 108, col: 19, offset: 2734},
+							label: "Imports",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 108, col: 27, offset: 2742},
+								expr: &ruleRefExpr{
+									pos:  position{line: 108, col: 27, offset: 2742},
+									name: "Import",
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 108, col: 35, offset: 2750},
 							label: "Attacker",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 79, col: 28, offset: 1770},
+								pos: position{line: 108, col: 44, offset: 2759},
 								expr: &ruleRefExpr{
-									pos:  position{line: 79, col: 28, offset: 1770},
+									pos:  position{line: 108, col: 44, offset: 2759},
 									name: "Attacker",
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 79, col: 38, offset: 1780},
+							pos:   position{line: 108, col: 54, offset: 2769},
 							label: "Blocks",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 79, col: 45, offset: 1787},
+								pos: position{line: 108, col: 61, offset: 2776},
 								expr: &oneOrMoreExpr{
-									pos: position{line: 79, col: 46, offset: 1788},
+									pos: position{line: 108, col: 62, offset: 2777},
 									expr: &ruleRefExpr{
-										pos:  position{line: 79, col: 46, offset: 1788},
+										pos:  position{line: 108, col: 62, offset: 2777},
 										name: "Block",
 									},
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 79, col: 55, offset: 1797},
+							pos:   position{line: 108, col: 71, offset: 2786},
 							label: "Queries",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 79, col: 63, offset: 1805},
+								pos: position{line: 108, col: 79, offset: 2794},
 								expr: &ruleRefExpr{
-									pos:  position{line: 79, col: 63, offset: 1805},
+									pos:  position{line: 108, col: 79, offset: 2794},
 									name: "Queries",
 								},
 							},
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 79, col: 72, offset: 1814},
+							pos: position{line: 108, col: 88, offset: 2803},
+							expr: &ruleRefExpr{
+								pos:  position{line: 108, col: 88, offset: 2803},
+								name: "Comment",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 108, col: 97, offset: 2812},
+							name: "EOF",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Attacker",
+			pos:  position{line: 150, col: 1, offset: 3809},
+			expr: &actionExpr{
+				pos: position{line: 150, col: 13, offset: 3821},
+				run: (*parser).callonAttacker1,
+				expr: &seqExpr{
+					pos: position{line: 150, col: 13, offset: 3821},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 150, col: 13, offset: 3821},
+							val:        "attacker",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 150, col: 24, offset: 3832},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 150, col: 26, offset: 3834},
+							val:        "[",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 150, col: 30, offset: 3838},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 150, col: 32, offset: 3840},
+							label: "Attacker",
+							expr: &choiceExpr{
+								pos: position{line: 150, col: 42, offset: 3850},
+								alternatives: []interface{}{
+									&ruleRefExpr{
+										pos:  position{line: 150, col: 42, offset: 3850},
+										name: "AttackerBoundedDepth",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 150, col: 63, offset: 3871},
+										name: "AttackerPerChannel",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 150, col: 82, offset: 3890},
+										name: "AttackerType",
+									},
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 150, col: 96, offset: 3904},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 150, col: 98, offset: 3906},
+							val:        "]",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 150, col: 102, offset: 3910},
+							name: "_",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "AttackerType",
+			pos:  position{line: 154, col: 1, offset: 3939},
+			expr: &actionExpr{
+				pos: position{line: 154, col: 17, offset: 3955},
+				run: (*parser).callonAttackerType1,
+				expr: &choiceExpr{
+					pos: position{line: 154, col: 18, offset: 3956},
+					alternatives: []interface{}{
+						&litMatcher{
+							pos:        position{line: 154, col: 18, offset: 3956},
+							val:        "active",
+							ignoreCase: false,
+						},
+						&litMatcher{
+							pos:        position{line: 154, col: 27, offset: 3965},
+							val:        "passive",
+							ignoreCase: false,
+						},
+						&litMatcher{
+							pos:        position{line: 154, col: 37, offset: 3975},
+							val:        "replay-only",
+							ignoreCase: false,
+						},
+						&litMatcher{
+							pos:        position{line: 154, col: 51, offset: 3989},
+							val:        "no-inject",
+							ignoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "AttackerBoundedDepth",
+			pos:  position{line: 160, col: 1, offset: 4058},
+			expr: &actionExpr{
+				pos: position{line: 160, col: 25, offset: 4082},
+				run: (*parser).callonAttackerBoundedDepth1,
+				expr: &seqExpr{
+					pos: position{line: 160, col: 25, offset: 4082},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 160, col: 25, offset: 4082},
+							val:        "bounded-depth",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 160, col: 41, offset: 4098},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 160, col: 43, offset: 4100},
+							label: "Depth",
+							expr: &oneOrMoreExpr{
+								pos: position{line: 160, col: 49, offset: 4106},
+								expr: &charClassMatcher{
+									pos:        position{line: 160, col: 49, offset: 4106},
+									val:        "[0-9]",
+									ranges:     []rune{'0', '9'},
+									ignoreCase: false,
+									inverted:   false,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "AttackerPerChannel",
+			pos:  position{line: 171, col: 1, offset: 4329},
+			expr: &actionExpr{
+				pos: position{line: 171, col: 23, offset: 4351},
+				run: (*parser).callonAttackerPerChannel1,
+				expr: &seqExpr{
+					pos: position{line: 171, col: 23, offset: 4351},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 171, col: 23, offset: 4351},
+							val:        "per-channel",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 171, col: 37, offset: 4365},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 171, col: 39, offset: 4367},
+							label: "Channels",
+							expr: &oneOrMoreExpr{
+								pos: position{line: 171, col: 48, offset: 4376},
+								expr: &ruleRefExpr{
+									pos:  position{line: 171, col: 48, offset: 4376},
+									name: "AttackerChannel",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "AttackerChannel",
+			pos:  position{line: 181, col: 1, offset: 4580},
+			expr: &actionExpr{
+				pos: position{line: 181, col: 20, offset: 4599},
+				run: (*parser).callonAttackerChannel1,
+				expr: &seqExpr{
+					pos: position{line: 181, col: 20, offset: 4599},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 181, col: 20, offset: 4599},
+							label: "Sender",
+							expr: &ruleRefExpr{
+								pos:  position{line: 181, col: 27, offset: 4606},
+								name: "PrincipalName",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 181, col: 41, offset: 4620},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 181, col: 43, offset: 4622},
+							val:        "->",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 181, col: 48, offset: 4627},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 181, col: 50, offset: 4629},
+							label: "Recipient",
+							expr: &ruleRefExpr{
+								pos:  position{line: 181, col: 60, offset: 4639},
+								name: "PrincipalName",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 181, col: 74, offset: 4653},
+							name: "_",
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 181, col: 76, offset: 4655},
+							expr: &seqExpr{
+								pos: position{line: 181, col: 77, offset: 4656},
+								exprs: []interface{}{
+									&litMatcher{
+										pos:        position{line: 181, col: 77, offset: 4656},
+										val:        ",",
+										ignoreCase: false,
+									},
+									&ruleRefExpr{
+										pos:  position{line: 181, col: 81, offset: 4660},
+										name: "_",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Import",
+			pos:  position{line: 189, col: 1, offset: 4781},
+			expr: &actionExpr{
+				pos: position{line: 189, col: 11, offset: 4791},
+				run: (*parser).callonImport1,
+				expr: &seqExpr{
+					pos: position{line: 189, col: 11, offset: 4791},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 189, col: 11, offset: 4791},
+							val:        "import",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 189, col: 20, offset: 4800},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 189, col: 22, offset: 4802},
+							label: "Path",
+							expr: &ruleRefExpr{
+								pos:  position{line: 189, col: 27, offset: 4807},
+								name: "StringLiteral",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 189, col: 41, offset: 4821},
+							name: "_",
+						},
+						&zeroOrMoreExpr{
+							pos: position{line: 189, col: 43, offset: 4823},
 							expr: &ruleRefExpr{
-								pos:  position{line: 79, col: 72, offset: 1814},
+								pos:  position{line: 189, col: 43, offset: 4823},
 								name: "Comment",
 							},
 						},
-						&ruleRefExpr{
-							pos:  position{line: 79, col: 81, offset: 1823},
-							name: "EOF",
-						},
 					},
 				},
 			},
 		},
 		{
-			name: "Attacker",
-			pos:  position{line: 101, col: 1, offset: 2375},
+			name: "Block",
+			pos:  position{line: 197, col: 1, offset: 4945},
+			expr: &actionExpr{
+				pos: position{line: 197, col: 10, offset: 4954},
+				run: (*parser).callonBlock1,
+				expr: &seqExpr{
+					pos: position{line: 197, col: 10, offset: 4954},
+					exprs: []interface{}{
+						&zeroOrMoreExpr{
+							pos: position{line: 197, col: 10, offset: 4954},
+							expr: &ruleRefExpr{
+								pos:  position{line: 197, col: 10, offset: 4954},
+								name: "Comment",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 197, col: 19, offset: 4963},
+							label: "Block",
+							expr: &choiceExpr{
+								pos: position{line: 197, col: 26, offset: 4970},
+								alternatives: []interface{}{
+									&ruleRefExpr{
+										pos:  position{line: 197, col: 26, offset: 4970},
+										name: "Fragment",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 197, col: 35, offset: 4979},
+										name: "Use",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 197, col: 39, offset: 4983},
+										name: "Repeat",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 197, col: 46, offset: 4990},
+										name: "Principal",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 197, col: 56, offset: 5000},
+										name: "Message",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 197, col: 64, offset: 5008},
+										name: "Phase",
+									},
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 197, col: 71, offset: 5015},
+							name: "_",
+						},
+						&zeroOrMoreExpr{
+							pos: position{line: 197, col: 73, offset: 5017},
+							expr: &ruleRefExpr{
+								pos:  position{line: 197, col: 73, offset: 5017},
+								name: "Comment",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Fragment",
+			pos:  position{line: 201, col: 1, offset: 5050},
+			expr: &actionExpr{
+				pos: position{line: 201, col: 13, offset: 5062},
+				run: (*parser).callonFragment1,
+				expr: &seqExpr{
+					pos: position{line: 201, col: 13, offset: 5062},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 201, col: 13, offset: 5062},
+							val:        "fragment",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 201, col: 24, offset: 5073},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 201, col: 26, offset: 5075},
+							label: "Name",
+							expr: &ruleRefExpr{
+								pos:  position{line: 201, col: 31, offset: 5080},
+								name: "Identifier",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 201, col: 42, offset: 5091},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 201, col: 44, offset: 5093},
+							val:        "(",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 201, col: 48, offset: 5097},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 201, col: 50, offset: 5099},
+							label: "Parameters",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 201, col: 61, offset: 5110},
+								expr: &ruleRefExpr{
+									pos:  position{line: 201, col: 61, offset: 5110},
+									name: "FragmentParameter",
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 201, col: 80, offset: 5129},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 201, col: 82, offset: 5131},
+							val:        ")",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 201, col: 86, offset: 5135},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 201, col: 88, offset: 5137},
+							val:        "[",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 201, col: 92, offset: 5141},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 201, col: 94, offset: 5143},
+							label: "Blocks",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 201, col: 102, offset: 5151},
+								expr: &ruleRefExpr{
+									pos:  position{line: 201, col: 102, offset: 5151},
+									name: "Block",
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 201, col: 110, offset: 5159},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 201, col: 112, offset: 5161},
+							val:        "]",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 201, col: 116, offset: 5165},
+							name: "_",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "FragmentParameter",
+			pos:  position{line: 220, col: 1, offset: 5593},
+			expr: &actionExpr{
+				pos: position{line: 220, col: 22, offset: 5614},
+				run: (*parser).callonFragmentParameter1,
+				expr: &seqExpr{
+					pos: position{line: 220, col: 22, offset: 5614},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 220, col: 22, offset: 5614},
+							label: "Name",
+							expr: &ruleRefExpr{
+								pos:  position{line: 220, col: 27, offset: 5619},
+								name: "Identifier",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 220, col: 38, offset: 5630},
+							name: "_",
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 220, col: 40, offset: 5632},
+							expr: &seqExpr{
+								pos: position{line: 220, col: 41, offset: 5633},
+								exprs: []interface{}{
+									&litMatcher{
+										pos:        position{line: 220, col: 41, offset: 5633},
+										val:        ",",
+										ignoreCase: false,
+									},
+									&ruleRefExpr{
+										pos:  position{line: 220, col: 45, offset: 5637},
+										name: "_",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Repeat",
+			pos:  position{line: 224, col: 1, offset: 5697},
 			expr: &actionExpr{
-				pos: position{line: 101, col: 13, offset: 2387},
-				run: (*parser).callonAttacker1,
+				pos: position{line: 224, col: 11, offset: 5707},
+				run: (*parser).callonRepeat1,
 				expr: &seqExpr{
-					pos: position{line: 101, col: 13, offset: 2387},
+					pos: position{line: 224, col: 11, offset: 5707},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 101, col: 13, offset: 2387},
-							val:        "attacker",
+							pos:        position{line: 224, col: 11, offset: 5707},
+							val:        "repeat",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 101, col: 24, offset: 2398},
+							pos:  position{line: 224, col: 20, offset: 5716},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 224, col: 22, offset: 5718},
+							label: "Header",
+							expr: &ruleRefExpr{
+								pos:  position{line: 224, col: 29, offset: 5725},
+								name: "RepeatHeader",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 224, col: 42, offset: 5738},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 101, col: 26, offset: 2400},
+							pos:        position{line: 224, col: 44, offset: 5740},
 							val:        "[",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 101, col: 30, offset: 2404},
+							pos:  position{line: 224, col: 48, offset: 5744},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 101, col: 32, offset: 2406},
-							label: "Type",
-							expr: &ruleRefExpr{
-								pos:  position{line: 101, col: 37, offset: 2411},
-								name: "AttackerType",
+							pos:   position{line: 224, col: 50, offset: 5746},
+							label: "Blocks",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 224, col: 58, offset: 5754},
+								expr: &ruleRefExpr{
+									pos:  position{line: 224, col: 58, offset: 5754},
+									name: "Block",
+								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 101, col: 50, offset: 2424},
+							pos:  position{line: 224, col: 66, offset: 5762},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 101, col: 52, offset: 2426},
+							pos:        position{line: 224, col: 68, offset: 5764},
 							val:        "]",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 101, col: 56, offset: 2430},
+							pos:  position{line: 224, col: 72, offset: 5768},
 							name: "_",
 						},
 					},
@@ -196,75 +728,129 @@ var g = &grammar{
 			},
 		},
 		{
-			name: "AttackerType",
-			pos:  position{line: 105, col: 1, offset: 2455},
-			expr: &actionExpr{
-				pos: position{line: 105, col: 17, offset: 2471},
-				run: (*parser).callonAttackerType1,
-				expr: &choiceExpr{
-					pos: position{line: 105, col: 18, offset: 2472},
-					alternatives: []interface{}{
-						&litMatcher{
-							pos:        position{line: 105, col: 18, offset: 2472},
-							val:        "active",
-							ignoreCase: false,
+			name: "RepeatHeader",
+			pos:  position{line: 236, col: 1, offset: 5975},
+			expr: &choiceExpr{
+				pos: position{line: 236, col: 17, offset: 5991},
+				alternatives: []interface{}{
+					&actionExpr{
+						pos: position{line: 236, col: 17, offset: 5991},
+						run: (*parser).callonRepeatHeader2,
+						expr: &seqExpr{
+							pos: position{line: 236, col: 17, offset: 5991},
+							exprs: []interface{}{
+								&labeledExpr{
+									pos:   position{line: 236, col: 17, offset: 5991},
+									label: "Index",
+									expr: &ruleRefExpr{
+										pos:  position{line: 236, col: 23, offset: 5997},
+										name: "Identifier",
+									},
+								},
+								&ruleRefExpr{
+									pos:  position{line: 236, col: 34, offset: 6008},
+									name: "_",
+								},
+								&labeledExpr{
+									pos:   position{line: 236, col: 36, offset: 6010},
+									label: "Count",
+									expr: &oneOrMoreExpr{
+										pos: position{line: 236, col: 42, offset: 6016},
+										expr: &charClassMatcher{
+											pos:        position{line: 236, col: 42, offset: 6016},
+											val:        "[0-9]",
+											ranges:     []rune{'0', '9'},
+											ignoreCase: false,
+											inverted:   false,
+										},
+									},
+								},
+							},
 						},
-						&litMatcher{
-							pos:        position{line: 105, col: 27, offset: 2481},
-							val:        "passive",
-							ignoreCase: false,
+					},
+					&actionExpr{
+						pos: position{line: 245, col: 5, offset: 6229},
+						run: (*parser).callonRepeatHeader10,
+						expr: &labeledExpr{
+							pos:   position{line: 245, col: 5, offset: 6229},
+							label: "Count",
+							expr: &oneOrMoreExpr{
+								pos: position{line: 245, col: 11, offset: 6235},
+								expr: &charClassMatcher{
+									pos:        position{line: 245, col: 11, offset: 6235},
+									val:        "[0-9]",
+									ranges:     []rune{'0', '9'},
+									ignoreCase: false,
+									inverted:   false,
+								},
+							},
 						},
 					},
 				},
 			},
 		},
 		{
-			name: "Block",
-			pos:  position{line: 109, col: 1, offset: 2525},
+			name: "Use",
+			pos:  position{line: 256, col: 1, offset: 6436},
 			expr: &actionExpr{
-				pos: position{line: 109, col: 10, offset: 2534},
-				run: (*parser).callonBlock1,
+				pos: position{line: 256, col: 8, offset: 6443},
+				run: (*parser).callonUse1,
 				expr: &seqExpr{
-					pos: position{line: 109, col: 10, offset: 2534},
+					pos: position{line: 256, col: 8, offset: 6443},
 					exprs: []interface{}{
-						&zeroOrMoreExpr{
-							pos: position{line: 109, col: 10, offset: 2534},
+						&litMatcher{
+							pos:        position{line: 256, col: 8, offset: 6443},
+							val:        "use",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 256, col: 14, offset: 6449},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 256, col: 16, offset: 6451},
+							label: "Name",
 							expr: &ruleRefExpr{
-								pos:  position{line: 109, col: 10, offset: 2534},
-								name: "Comment",
+								pos:  position{line: 256, col: 21, offset: 6456},
+								name: "Identifier",
 							},
 						},
+						&ruleRefExpr{
+							pos:  position{line: 256, col: 32, offset: 6467},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 256, col: 34, offset: 6469},
+							val:        "(",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 256, col: 38, offset: 6473},
+							name: "_",
+						},
 						&labeledExpr{
-							pos:   position{line: 109, col: 19, offset: 2543},
-							label: "Block",
-							expr: &choiceExpr{
-								pos: position{line: 109, col: 26, offset: 2550},
-								alternatives: []interface{}{
-									&ruleRefExpr{
-										pos:  position{line: 109, col: 26, offset: 2550},
-										name: "Principal",
-									},
-									&ruleRefExpr{
-										pos:  position{line: 109, col: 36, offset: 2560},
-										name: "Message",
-									},
-									&ruleRefExpr{
-										pos:  position{line: 109, col: 44, offset: 2568},
-										name: "Phase",
-									},
+							pos:   position{line: 256, col: 40, offset: 6475},
+							label: "Arguments",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 256, col: 50, offset: 6485},
+								expr: &ruleRefExpr{
+									pos:  position{line: 256, col: 50, offset: 6485},
+									name: "FragmentParameter",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 109, col: 51, offset: 2575},
+							pos:  position{line: 256, col: 69, offset: 6504},
 							name: "_",
 						},
-						&zeroOrMoreExpr{
-							pos: position{line: 109, col: 53, offset: 2577},
-							expr: &ruleRefExpr{
-								pos:  position{line: 109, col: 53, offset: 2577},
-								name: "Comment",
-							},
+						&litMatcher{
+							pos:        position{line: 256, col: 71, offset: 6506},
+							val:        ")",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 256, col: 75, offset: 6510},
+							name: "_",
 						},
 					},
 				},
@@ -272,65 +858,65 @@ var g = &grammar{
 		},
 		{
 			name: "Principal",
-			pos:  position{line: 113, col: 1, offset: 2610},
+			pos:  position{line: 271, col: 1, offset: 6771},
 			expr: &actionExpr{
-				pos: position{line: 113, col: 14, offset: 2623},
+				pos: position{line: 271, col: 14, offset: 6784},
 				run: (*parser).callonPrincipal1,
 				expr: &seqExpr{
-					pos: position{line: 113, col: 14, offset: 2623},
+					pos: position{line: 271, col: 14, offset: 6784},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 113, col: 14, offset: 2623},
+							pos:        position{line: 271, col: 14, offset: 6784},
 							val:        "principal",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 113, col: 26, offset: 2635},
+							pos:  position{line: 271, col: 26, offset: 6796},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 113, col: 28, offset: 2637},
+							pos:   position{line: 271, col: 28, offset: 6798},
 							label: "Name",
 							expr: &ruleRefExpr{
-								pos:  position{line: 113, col: 33, offset: 2642},
+								pos:  position{line: 271, col: 33, offset: 6803},
 								name: "PrincipalName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 113, col: 47, offset: 2656},
+							pos:  position{line: 271, col: 47, offset: 6817},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 113, col: 49, offset: 2658},
+							pos:        position{line: 271, col: 49, offset: 6819},
 							val:        "[",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 113, col: 53, offset: 2662},
+							pos:  position{line: 271, col: 53, offset: 6823},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 113, col: 55, offset: 2664},
+							pos:   position{line: 271, col: 55, offset: 6825},
 							label: "Expressions",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 113, col: 68, offset: 2677},
+								pos: position{line: 271, col: 68, offset: 6838},
 								expr: &ruleRefExpr{
-									pos:  position{line: 113, col: 68, offset: 2677},
+									pos:  position{line: 271, col: 68, offset: 6838},
 									name: "Expression",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 113, col: 81, offset: 2690},
+							pos:  position{line: 271, col: 81, offset: 6851},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 113, col: 83, offset: 2692},
+							pos:        position{line: 271, col: 83, offset: 6853},
 							val:        "]",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 113, col: 87, offset: 2696},
+							pos:  position{line: 271, col: 87, offset: 6857},
 							name: "_",
 						},
 					},
@@ -339,15 +925,15 @@ var g = &grammar{
 		},
 		{
 			name: "PrincipalName",
-			pos:  position{line: 126, col: 1, offset: 2937},
+			pos:  position{line: 284, col: 1, offset: 7105},
 			expr: &actionExpr{
-				pos: position{line: 126, col: 18, offset: 2954},
+				pos: position{line: 284, col: 18, offset: 7122},
 				run: (*parser).callonPrincipalName1,
 				expr: &labeledExpr{
-					pos:   position{line: 126, col: 18, offset: 2954},
+					pos:   position{line: 284, col: 18, offset: 7122},
 					label: "Name",
 					expr: &ruleRefExpr{
-						pos:  position{line: 126, col: 23, offset: 2959},
+						pos:  position{line: 284, col: 23, offset: 7127},
 						name: "Identifier",
 					},
 				},
@@ -355,25 +941,25 @@ var g = &grammar{
 		},
 		{
 			name: "Qualifier",
-			pos:  position{line: 131, col: 1, offset: 3062},
+			pos:  position{line: 289, col: 1, offset: 7230},
 			expr: &actionExpr{
-				pos: position{line: 131, col: 14, offset: 3075},
+				pos: position{line: 289, col: 14, offset: 7243},
 				run: (*parser).callonQualifier1,
 				expr: &choiceExpr{
-					pos: position{line: 131, col: 15, offset: 3076},
+					pos: position{line: 289, col: 15, offset: 7244},
 					alternatives: []interface{}{
 						&litMatcher{
-							pos:        position{line: 131, col: 15, offset: 3076},
+							pos:        position{line: 289, col: 15, offset: 7244},
 							val:        "public",
 							ignoreCase: false,
 						},
 						&litMatcher{
-							pos:        position{line: 131, col: 24, offset: 3085},
+							pos:        position{line: 289, col: 24, offset: 7253},
 							val:        "private",
 							ignoreCase: false,
 						},
 						&litMatcher{
-							pos:        position{line: 131, col: 34, offset: 3095},
+							pos:        position{line: 289, col: 34, offset: 7263},
 							val:        "password",
 							ignoreCase: false,
 						},
@@ -383,60 +969,63 @@ var g = &grammar{
 		},
 		{
 			name: "Message",
-			pos:  position{line: 135, col: 1, offset: 3140},
+			pos:  position{line: 293, col: 1, offset: 7308},
 			expr: &actionExpr{
-				pos: position{line: 135, col: 12, offset: 3151},
+				pos: position{line: 293, col: 12, offset: 7319},
 				run: (*parser).callonMessage1,
 				expr: &seqExpr{
-					pos: position{line: 135, col: 12, offset: 3151},
+					pos: position{line: 293, col: 12, offset: 7319},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 135, col: 12, offset: 3151},
+							pos:   position{line: 293, col: 12, offset: 7319},
 							label: "Sender",
 							expr: &ruleRefExpr{
-								pos:  position{line: 135, col: 19, offset: 3158},
+								pos:  position{line: 293, col: 19, offset: 7326},
 								name: "PrincipalName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 135, col: 33, offset: 3172},
+							pos:  position{line: 293, col: 33, offset: 7340},
 							name: "_",
 						},
-						&litMatcher{
-							pos:        position{line: 135, col: 35, offset: 3174},
-							val:        "->",
-							ignoreCase: false,
+						&labeledExpr{
+							pos:   position{line: 293, col: 35, offset: 7342},
+							label: "Channel",
+							expr: &ruleRefExpr{
+								pos:  position{line: 293, col: 43, offset: 7350},
+								name: "MessageArrow",
+							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 135, col: 40, offset: 3179},
+							pos:  position{line: 293, col: 56, offset: 7363},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 135, col: 42, offset: 3181},
-							label: "Recipient",
+							pos:   position{line: 293, col: 58, offset: 7365},
+							label: "Recipients",
 							expr: &ruleRefExpr{
-								pos:  position{line: 135, col: 52, offset: 3191},
-								name: "PrincipalName",
+								pos:  position{line: 293, col: 69, offset: 7376},
+								name: "MessageRecipients",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 135, col: 66, offset: 3205},
+							pos:  position{line: 293, col: 87, offset: 7394},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 135, col: 68, offset: 3207},
+							pos:        position{line: 293, col: 89, offset: 7396},
 							val:        ":",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 135, col: 72, offset: 3211},
+							pos:  position{line: 293, col: 93, offset: 7400},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 135, col: 74, offset: 3213},
+							pos:   position{line: 293, col: 95, offset: 7402},
 							label: "Constants",
 							expr: &ruleRefExpr{
-								pos:  position{line: 135, col: 84, offset: 3223},
+								pos:  position{line: 293, col: 105, offset: 7412},
 								name: "MessageConstants",
 							},
 						},
@@ -444,26 +1033,168 @@ var g = &grammar{
 				},
 			},
 		},
+		{
+			name: "MessageArrow",
+			pos:  position{line: 307, col: 1, offset: 7670},
+			expr: &choiceExpr{
+				pos: position{line: 307, col: 17, offset: 7686},
+				alternatives: []interface{}{
+					&actionExpr{
+						pos: position{line: 307, col: 17, offset: 7686},
+						run: (*parser).callonMessageArrow2,
+						expr: &seqExpr{
+							pos: position{line: 307, col: 17, offset: 7686},
+							exprs: []interface{}{
+								&litMatcher{
+									pos:        position{line: 307, col: 17, offset: 7686},
+									val:        "-(",
+									ignoreCase: false,
+								},
+								&ruleRefExpr{
+									pos:  position{line: 307, col: 22, offset: 7691},
+									name: "_",
+								},
+								&labeledExpr{
+									pos:   position{line: 307, col: 24, offset: 7693},
+									label: "Channel",
+									expr: &ruleRefExpr{
+										pos:  position{line: 307, col: 32, offset: 7701},
+										name: "MessageChannel",
+									},
+								},
+								&ruleRefExpr{
+									pos:  position{line: 307, col: 47, offset: 7716},
+									name: "_",
+								},
+								&litMatcher{
+									pos:        position{line: 307, col: 49, offset: 7718},
+									val:        ")->",
+									ignoreCase: false,
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 309, col: 5, offset: 7751},
+						run: (*parser).callonMessageArrow10,
+						expr: &litMatcher{
+							pos:        position{line: 309, col: 5, offset: 7751},
+							val:        "->",
+							ignoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "MessageChannel",
+			pos:  position{line: 313, col: 1, offset: 7777},
+			expr: &actionExpr{
+				pos: position{line: 313, col: 19, offset: 7795},
+				run: (*parser).callonMessageChannel1,
+				expr: &choiceExpr{
+					pos: position{line: 313, col: 20, offset: 7796},
+					alternatives: []interface{}{
+						&litMatcher{
+							pos:        position{line: 313, col: 20, offset: 7796},
+							val:        "auth",
+							ignoreCase: false,
+						},
+						&litMatcher{
+							pos:        position{line: 313, col: 27, offset: 7803},
+							val:        "conf",
+							ignoreCase: false,
+						},
+						&litMatcher{
+							pos:        position{line: 313, col: 34, offset: 7810},
+							val:        "secure",
+							ignoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "MessageRecipients",
+			pos:  position{line: 317, col: 1, offset: 7853},
+			expr: &actionExpr{
+				pos: position{line: 317, col: 22, offset: 7874},
+				run: (*parser).callonMessageRecipients1,
+				expr: &labeledExpr{
+					pos:   position{line: 317, col: 22, offset: 7874},
+					label: "Recipients",
+					expr: &oneOrMoreExpr{
+						pos: position{line: 317, col: 33, offset: 7885},
+						expr: &ruleRefExpr{
+							pos:  position{line: 317, col: 34, offset: 7886},
+							name: "MessageRecipient",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "MessageRecipient",
+			pos:  position{line: 324, col: 1, offset: 8034},
+			expr: &actionExpr{
+				pos: position{line: 324, col: 21, offset: 8054},
+				run: (*parser).callonMessageRecipient1,
+				expr: &seqExpr{
+					pos: position{line: 324, col: 21, offset: 8054},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 324, col: 21, offset: 8054},
+							label: "Name",
+							expr: &ruleRefExpr{
+								pos:  position{line: 324, col: 26, offset: 8059},
+								name: "PrincipalName",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 324, col: 40, offset: 8073},
+							name: "_",
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 324, col: 42, offset: 8075},
+							expr: &seqExpr{
+								pos: position{line: 324, col: 43, offset: 8076},
+								exprs: []interface{}{
+									&litMatcher{
+										pos:        position{line: 324, col: 43, offset: 8076},
+										val:        ",",
+										ignoreCase: false,
+									},
+									&ruleRefExpr{
+										pos:  position{line: 324, col: 47, offset: 8080},
+										name: "_",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "MessageConstants",
-			pos:  position{line: 146, col: 1, offset: 3412},
+			pos:  position{line: 328, col: 1, offset: 8107},
 			expr: &actionExpr{
-				pos: position{line: 146, col: 21, offset: 3432},
+				pos: position{line: 328, col: 21, offset: 8127},
 				run: (*parser).callonMessageConstants1,
 				expr: &labeledExpr{
-					pos:   position{line: 146, col: 21, offset: 3432},
+					pos:   position{line: 328, col: 21, offset: 8127},
 					label: "MessageConstants",
 					expr: &oneOrMoreExpr{
-						pos: position{line: 146, col: 38, offset: 3449},
+						pos: position{line: 328, col: 38, offset: 8144},
 						expr: &choiceExpr{
-							pos: position{line: 146, col: 39, offset: 3450},
+							pos: position{line: 328, col: 39, offset: 8145},
 							alternatives: []interface{}{
 								&ruleRefExpr{
-									pos:  position{line: 146, col: 39, offset: 3450},
+									pos:  position{line: 328, col: 39, offset: 8145},
 									name: "GuardedConstant",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 146, col: 55, offset: 3466},
+									pos:  position{line: 328, col: 55, offset: 8161},
 									name: "Constant",
 								},
 							},
@@ -474,53 +1205,53 @@ var g = &grammar{
 		},
 		{
 			name: "Expression",
-			pos:  position{line: 156, col: 1, offset: 3630},
+			pos:  position{line: 338, col: 1, offset: 8325},
 			expr: &actionExpr{
-				pos: position{line: 156, col: 15, offset: 3644},
+				pos: position{line: 338, col: 15, offset: 8339},
 				run: (*parser).callonExpression1,
 				expr: &seqExpr{
-					pos: position{line: 156, col: 15, offset: 3644},
+					pos: position{line: 338, col: 15, offset: 8339},
 					exprs: []interface{}{
 						&zeroOrMoreExpr{
-							pos: position{line: 156, col: 15, offset: 3644},
+							pos: position{line: 338, col: 15, offset: 8339},
 							expr: &ruleRefExpr{
-								pos:  position{line: 156, col: 15, offset: 3644},
+								pos:  position{line: 338, col: 15, offset: 8339},
 								name: "Comment",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 156, col: 24, offset: 3653},
+							pos:   position{line: 338, col: 24, offset: 8348},
 							label: "Expression",
 							expr: &choiceExpr{
-								pos: position{line: 156, col: 36, offset: 3665},
+								pos: position{line: 338, col: 36, offset: 8360},
 								alternatives: []interface{}{
 									&ruleRefExpr{
-										pos:  position{line: 156, col: 36, offset: 3665},
+										pos:  position{line: 338, col: 36, offset: 8360},
 										name: "Knows",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 156, col: 42, offset: 3671},
+										pos:  position{line: 338, col: 42, offset: 8366},
 										name: "Generates",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 156, col: 52, offset: 3681},
+										pos:  position{line: 338, col: 52, offset: 8376},
 										name: "Leaks",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 156, col: 58, offset: 3687},
+										pos:  position{line: 338, col: 58, offset: 8382},
 										name: "Assignment",
 									},
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 156, col: 70, offset: 3699},
+							pos:  position{line: 338, col: 70, offset: 8394},
 							name: "_",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 156, col: 72, offset: 3701},
+							pos: position{line: 338, col: 72, offset: 8396},
 							expr: &ruleRefExpr{
-								pos:  position{line: 156, col: 72, offset: 3701},
+								pos:  position{line: 338, col: 72, offset: 8396},
 								name: "Comment",
 							},
 						},
@@ -530,39 +1261,39 @@ var g = &grammar{
 		},
 		{
 			name: "Knows",
-			pos:  position{line: 160, col: 1, offset: 3739},
+			pos:  position{line: 342, col: 1, offset: 8434},
 			expr: &actionExpr{
-				pos: position{line: 160, col: 10, offset: 3748},
+				pos: position{line: 342, col: 10, offset: 8443},
 				run: (*parser).callonKnows1,
 				expr: &seqExpr{
-					pos: position{line: 160, col: 10, offset: 3748},
+					pos: position{line: 342, col: 10, offset: 8443},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 160, col: 10, offset: 3748},
+							pos:        position{line: 342, col: 10, offset: 8443},
 							val:        "knows",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 160, col: 18, offset: 3756},
+							pos:  position{line: 342, col: 18, offset: 8451},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 160, col: 20, offset: 3758},
+							pos:   position{line: 342, col: 20, offset: 8453},
 							label: "Qualifier",
 							expr: &ruleRefExpr{
-								pos:  position{line: 160, col: 30, offset: 3768},
+								pos:  position{line: 342, col: 30, offset: 8463},
 								name: "Qualifier",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 160, col: 40, offset: 3778},
+							pos:  position{line: 342, col: 40, offset: 8473},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 160, col: 42, offset: 3780},
+							pos:   position{line: 342, col: 42, offset: 8475},
 							label: "Constants",
 							expr: &ruleRefExpr{
-								pos:  position{line: 160, col: 52, offset: 3790},
+								pos:  position{line: 342, col: 52, offset: 8485},
 								name: "Constants",
 							},
 						},
@@ -572,27 +1303,27 @@ var g = &grammar{
 		},
 		{
 			name: "Generates",
-			pos:  position{line: 168, col: 1, offset: 3920},
+			pos:  position{line: 350, col: 1, offset: 8627},
 			expr: &actionExpr{
-				pos: position{line: 168, col: 14, offset: 3933},
+				pos: position{line: 350, col: 14, offset: 8640},
 				run: (*parser).callonGenerates1,
 				expr: &seqExpr{
-					pos: position{line: 168, col: 14, offset: 3933},
+					pos: position{line: 350, col: 14, offset: 8640},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 168, col: 14, offset: 3933},
+							pos:        position{line: 350, col: 14, offset: 8640},
 							val:        "generates",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 168, col: 26, offset: 3945},
+							pos:  position{line: 350, col: 26, offset: 8652},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 168, col: 28, offset: 3947},
+							pos:   position{line: 350, col: 28, offset: 8654},
 							label: "Constants",
 							expr: &ruleRefExpr{
-								pos:  position{line: 168, col: 38, offset: 3957},
+								pos:  position{line: 350, col: 38, offset: 8664},
 								name: "Constants",
 							},
 						},
@@ -602,27 +1333,27 @@ var g = &grammar{
 		},
 		{
 			name: "Leaks",
-			pos:  position{line: 176, col: 1, offset: 4075},
+			pos:  position{line: 358, col: 1, offset: 8794},
 			expr: &actionExpr{
-				pos: position{line: 176, col: 10, offset: 4084},
+				pos: position{line: 358, col: 10, offset: 8803},
 				run: (*parser).callonLeaks1,
 				expr: &seqExpr{
-					pos: position{line: 176, col: 10, offset: 4084},
+					pos: position{line: 358, col: 10, offset: 8803},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 176, col: 10, offset: 4084},
+							pos:        position{line: 358, col: 10, offset: 8803},
 							val:        "leaks",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 176, col: 18, offset: 4092},
+							pos:  position{line: 358, col: 18, offset: 8811},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 176, col: 20, offset: 4094},
+							pos:   position{line: 358, col: 20, offset: 8813},
 							label: "Constants",
 							expr: &ruleRefExpr{
-								pos:  position{line: 176, col: 30, offset: 4104},
+								pos:  position{line: 358, col: 30, offset: 8823},
 								name: "Constants",
 							},
 						},
@@ -632,39 +1363,39 @@ var g = &grammar{
 		},
 		{
 			name: "Assignment",
-			pos:  position{line: 184, col: 1, offset: 4218},
+			pos:  position{line: 366, col: 1, offset: 8949},
 			expr: &actionExpr{
-				pos: position{line: 184, col: 15, offset: 4232},
+				pos: position{line: 366, col: 15, offset: 8963},
 				run: (*parser).callonAssignment1,
 				expr: &seqExpr{
-					pos: position{line: 184, col: 15, offset: 4232},
+					pos: position{line: 366, col: 15, offset: 8963},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 184, col: 15, offset: 4232},
+							pos:   position{line: 366, col: 15, offset: 8963},
 							label: "Left",
 							expr: &ruleRefExpr{
-								pos:  position{line: 184, col: 20, offset: 4237},
+								pos:  position{line: 366, col: 20, offset: 8968},
 								name: "Constants",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 184, col: 30, offset: 4247},
+							pos:  position{line: 366, col: 30, offset: 8978},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 184, col: 32, offset: 4249},
+							pos:        position{line: 366, col: 32, offset: 8980},
 							val:        "=",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 184, col: 36, offset: 4253},
+							pos:  position{line: 366, col: 36, offset: 8984},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 184, col: 38, offset: 4255},
+							pos:   position{line: 366, col: 38, offset: 8986},
 							label: "Right",
 							expr: &ruleRefExpr{
-								pos:  position{line: 184, col: 44, offset: 4261},
+								pos:  position{line: 366, col: 44, offset: 8992},
 								name: "Value",
 							},
 						},
@@ -674,37 +1405,37 @@ var g = &grammar{
 		},
 		{
 			name: "Constant",
-			pos:  position{line: 205, col: 1, offset: 4697},
+			pos:  position{line: 387, col: 1, offset: 9447},
 			expr: &actionExpr{
-				pos: position{line: 205, col: 13, offset: 4709},
+				pos: position{line: 387, col: 13, offset: 9459},
 				run: (*parser).callonConstant1,
 				expr: &seqExpr{
-					pos: position{line: 205, col: 13, offset: 4709},
+					pos: position{line: 387, col: 13, offset: 9459},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 205, col: 13, offset: 4709},
+							pos:   position{line: 387, col: 13, offset: 9459},
 							label: "Const",
 							expr: &ruleRefExpr{
-								pos:  position{line: 205, col: 19, offset: 4715},
+								pos:  position{line: 387, col: 19, offset: 9465},
 								name: "Identifier",
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 205, col: 30, offset: 4726},
+							pos: position{line: 387, col: 30, offset: 9476},
 							expr: &seqExpr{
-								pos: position{line: 205, col: 31, offset: 4727},
+								pos: position{line: 387, col: 31, offset: 9477},
 								exprs: []interface{}{
 									&ruleRefExpr{
-										pos:  position{line: 205, col: 31, offset: 4727},
+										pos:  position{line: 387, col: 31, offset: 9477},
 										name: "_",
 									},
 									&litMatcher{
-										pos:        position{line: 205, col: 33, offset: 4729},
+										pos:        position{line: 387, col: 33, offset: 9479},
 										val:        ",",
 										ignoreCase: false,
 									},
 									&ruleRefExpr{
-										pos:  position{line: 205, col: 37, offset: 4733},
+										pos:  position{line: 387, col: 37, offset: 9483},
 										name: "_",
 									},
 								},
@@ -716,17 +1447,17 @@ var g = &grammar{
 		},
 		{
 			name: "Constants",
-			pos:  position{line: 214, col: 1, offset: 4837},
+			pos:  position{line: 396, col: 1, offset: 9594},
 			expr: &actionExpr{
-				pos: position{line: 214, col: 14, offset: 4850},
+				pos: position{line: 396, col: 14, offset: 9607},
 				run: (*parser).callonConstants1,
 				expr: &labeledExpr{
-					pos:   position{line: 214, col: 14, offset: 4850},
+					pos:   position{line: 396, col: 14, offset: 9607},
 					label: "Constants",
 					expr: &oneOrMoreExpr{
-						pos: position{line: 214, col: 24, offset: 4860},
+						pos: position{line: 396, col: 24, offset: 9617},
 						expr: &ruleRefExpr{
-							pos:  position{line: 214, col: 24, offset: 4860},
+							pos:  position{line: 396, col: 24, offset: 9617},
 							name: "Constant",
 						},
 					},
@@ -735,38 +1466,38 @@ var g = &grammar{
 		},
 		{
 			name: "Phase",
-			pos:  position{line: 226, col: 1, offset: 5103},
+			pos:  position{line: 408, col: 1, offset: 9860},
 			expr: &actionExpr{
-				pos: position{line: 226, col: 10, offset: 5112},
+				pos: position{line: 408, col: 10, offset: 9869},
 				run: (*parser).callonPhase1,
 				expr: &seqExpr{
-					pos: position{line: 226, col: 10, offset: 5112},
+					pos: position{line: 408, col: 10, offset: 9869},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 226, col: 10, offset: 5112},
+							pos:        position{line: 408, col: 10, offset: 9869},
 							val:        "phase",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 226, col: 18, offset: 5120},
+							pos:  position{line: 408, col: 18, offset: 9877},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 226, col: 20, offset: 5122},
+							pos:        position{line: 408, col: 20, offset: 9879},
 							val:        "[",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 226, col: 24, offset: 5126},
+							pos:  position{line: 408, col: 24, offset: 9883},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 226, col: 26, offset: 5128},
+							pos:   position{line: 408, col: 26, offset: 9885},
 							label: "Number",
 							expr: &oneOrMoreExpr{
-								pos: position{line: 226, col: 33, offset: 5135},
+								pos: position{line: 408, col: 33, offset: 9892},
 								expr: &charClassMatcher{
-									pos:        position{line: 226, col: 33, offset: 5135},
+									pos:        position{line: 408, col: 33, offset: 9892},
 									val:        "[0-9]",
 									ranges:     []rune{'0', '9'},
 									ignoreCase: false,
@@ -775,16 +1506,16 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 226, col: 40, offset: 5142},
+							pos:  position{line: 408, col: 40, offset: 9899},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 226, col: 42, offset: 5144},
+							pos:        position{line: 408, col: 42, offset: 9901},
 							val:        "]",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 226, col: 46, offset: 5148},
+							pos:  position{line: 408, col: 46, offset: 9905},
 							name: "_",
 						},
 					},
@@ -793,47 +1524,47 @@ var g = &grammar{
 		},
 		{
 			name: "GuardedConstant",
-			pos:  position{line: 239, col: 1, offset: 5370},
+			pos:  position{line: 421, col: 1, offset: 10134},
 			expr: &actionExpr{
-				pos: position{line: 239, col: 20, offset: 5389},
+				pos: position{line: 421, col: 20, offset: 10153},
 				run: (*parser).callonGuardedConstant1,
 				expr: &seqExpr{
-					pos: position{line: 239, col: 20, offset: 5389},
+					pos: position{line: 421, col: 20, offset: 10153},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 239, col: 20, offset: 5389},
+							pos:        position{line: 421, col: 20, offset: 10153},
 							val:        "[",
 							ignoreCase: false,
 						},
 						&labeledExpr{
-							pos:   position{line: 239, col: 24, offset: 5393},
+							pos:   position{line: 421, col: 24, offset: 10157},
 							label: "Guarded",
 							expr: &ruleRefExpr{
-								pos:  position{line: 239, col: 32, offset: 5401},
+								pos:  position{line: 421, col: 32, offset: 10165},
 								name: "Identifier",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 239, col: 43, offset: 5412},
+							pos:        position{line: 421, col: 43, offset: 10176},
 							val:        "]",
 							ignoreCase: false,
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 239, col: 47, offset: 5416},
+							pos: position{line: 421, col: 47, offset: 10180},
 							expr: &seqExpr{
-								pos: position{line: 239, col: 48, offset: 5417},
+								pos: position{line: 421, col: 48, offset: 10181},
 								exprs: []interface{}{
 									&ruleRefExpr{
-										pos:  position{line: 239, col: 48, offset: 5417},
+										pos:  position{line: 421, col: 48, offset: 10181},
 										name: "_",
 									},
 									&litMatcher{
-										pos:        position{line: 239, col: 50, offset: 5419},
+										pos:        position{line: 421, col: 50, offset: 10183},
 										val:        ",",
 										ignoreCase: false,
 									},
 									&ruleRefExpr{
-										pos:  position{line: 239, col: 54, offset: 5423},
+										pos:  position{line: 421, col: 54, offset: 10187},
 										name: "_",
 									},
 								},
@@ -845,78 +1576,195 @@ var g = &grammar{
 		},
 		{
 			name: "Primitive",
-			pos:  position{line: 250, col: 1, offset: 5593},
+			pos:  position{line: 432, col: 1, offset: 10364},
 			expr: &actionExpr{
-				pos: position{line: 250, col: 14, offset: 5606},
+				pos: position{line: 432, col: 14, offset: 10377},
 				run: (*parser).callonPrimitive1,
 				expr: &seqExpr{
-					pos: position{line: 250, col: 14, offset: 5606},
+					pos: position{line: 432, col: 14, offset: 10377},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 250, col: 14, offset: 5606},
+							pos:   position{line: 432, col: 14, offset: 10377},
 							label: "Name",
 							expr: &ruleRefExpr{
-								pos:  position{line: 250, col: 19, offset: 5611},
+								pos:  position{line: 432, col: 19, offset: 10382},
 								name: "PrimitiveName",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 250, col: 33, offset: 5625},
+							pos:        position{line: 432, col: 33, offset: 10396},
 							val:        "(",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 250, col: 37, offset: 5629},
+							pos:  position{line: 432, col: 37, offset: 10400},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 250, col: 39, offset: 5631},
+							pos:   position{line: 432, col: 39, offset: 10402},
 							label: "Arguments",
 							expr: &oneOrMoreExpr{
-								pos: position{line: 250, col: 49, offset: 5641},
+								pos: position{line: 432, col: 49, offset: 10412},
 								expr: &ruleRefExpr{
-									pos:  position{line: 250, col: 49, offset: 5641},
+									pos:  position{line: 432, col: 49, offset: 10412},
 									name: "Value",
 								},
 							},
 						},
-						&ruleRefExpr{
-							pos:  position{line: 250, col: 56, offset: 5648},
-							name: "_",
-						},
-						&litMatcher{
-							pos:        position{line: 250, col: 58, offset: 5650},
-							val:        ")",
-							ignoreCase: false,
-						},
+						&ruleRefExpr{
+							pos:  position{line: 432, col: 56, offset: 10419},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 432, col: 58, offset: 10421},
+							val:        ")",
+							ignoreCase: false,
+						},
+						&labeledExpr{
+							pos:   position{line: 432, col: 62, offset: 10425},
+							label: "Check",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 432, col: 68, offset: 10431},
+								expr: &litMatcher{
+									pos:        position{line: 432, col: 68, offset: 10431},
+									val:        "?",
+									ignoreCase: false,
+								},
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 432, col: 73, offset: 10436},
+							expr: &seqExpr{
+								pos: position{line: 432, col: 74, offset: 10437},
+								exprs: []interface{}{
+									&ruleRefExpr{
+										pos:  position{line: 432, col: 74, offset: 10437},
+										name: "_",
+									},
+									&litMatcher{
+										pos:        position{line: 432, col: 76, offset: 10439},
+										val:        ",",
+										ignoreCase: false,
+									},
+									&ruleRefExpr{
+										pos:  position{line: 432, col: 80, offset: 10443},
+										name: "_",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "PrimitiveName",
+			pos:  position{line: 449, col: 1, offset: 10751},
+			expr: &actionExpr{
+				pos: position{line: 449, col: 18, offset: 10768},
+				run: (*parser).callonPrimitiveName1,
+				expr: &labeledExpr{
+					pos:   position{line: 449, col: 18, offset: 10768},
+					label: "Name",
+					expr: &ruleRefExpr{
+						pos:  position{line: 449, col: 23, offset: 10773},
+						name: "Identifier",
+					},
+				},
+			},
+		},
+		{
+			name: "Equation",
+			pos:  position{line: 453, col: 1, offset: 10833},
+			expr: &actionExpr{
+				pos: position{line: 453, col: 13, offset: 10845},
+				run: (*parser).callonEquation1,
+				expr: &seqExpr{
+					pos: position{line: 453, col: 13, offset: 10845},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 453, col: 13, offset: 10845},
+							label: "First",
+							expr: &ruleRefExpr{
+								pos:  position{line: 453, col: 19, offset: 10851},
+								name: "Constant",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 453, col: 28, offset: 10860},
+							label: "Rest",
+							expr: &oneOrMoreExpr{
+								pos: position{line: 453, col: 33, offset: 10865},
+								expr: &seqExpr{
+									pos: position{line: 453, col: 34, offset: 10866},
+									exprs: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 453, col: 34, offset: 10866},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 453, col: 36, offset: 10868},
+											val:        "^",
+											ignoreCase: false,
+										},
+										&ruleRefExpr{
+											pos:  position{line: 453, col: 40, offset: 10872},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 453, col: 42, offset: 10874},
+											name: "Constant",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Literal",
+			pos:  position{line: 466, col: 1, offset: 11121},
+			expr: &actionExpr{
+				pos: position{line: 466, col: 12, offset: 11132},
+				run: (*parser).callonLiteral1,
+				expr: &seqExpr{
+					pos: position{line: 466, col: 12, offset: 11132},
+					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 250, col: 62, offset: 5654},
-							label: "Check",
-							expr: &zeroOrOneExpr{
-								pos: position{line: 250, col: 68, offset: 5660},
-								expr: &litMatcher{
-									pos:        position{line: 250, col: 68, offset: 5660},
-									val:        "?",
-									ignoreCase: false,
+							pos:   position{line: 466, col: 12, offset: 11132},
+							label: "Literal",
+							expr: &choiceExpr{
+								pos: position{line: 466, col: 21, offset: 11141},
+								alternatives: []interface{}{
+									&ruleRefExpr{
+										pos:  position{line: 466, col: 21, offset: 11141},
+										name: "StringLiteral",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 466, col: 35, offset: 11155},
+										name: "NumberLiteral",
+									},
 								},
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 250, col: 73, offset: 5665},
+							pos: position{line: 466, col: 50, offset: 11170},
 							expr: &seqExpr{
-								pos: position{line: 250, col: 74, offset: 5666},
+								pos: position{line: 466, col: 51, offset: 11171},
 								exprs: []interface{}{
 									&ruleRefExpr{
-										pos:  position{line: 250, col: 74, offset: 5666},
+										pos:  position{line: 466, col: 51, offset: 11171},
 										name: "_",
 									},
 									&litMatcher{
-										pos:        position{line: 250, col: 76, offset: 5668},
+										pos:        position{line: 466, col: 53, offset: 11173},
 										val:        ",",
 										ignoreCase: false,
 									},
 									&ruleRefExpr{
-										pos:  position{line: 250, col: 80, offset: 5672},
+										pos:  position{line: 466, col: 57, offset: 11177},
 										name: "_",
 									},
 								},
@@ -927,62 +1775,91 @@ var g = &grammar{
 			},
 		},
 		{
-			name: "PrimitiveName",
-			pos:  position{line: 266, col: 1, offset: 5938},
+			name: "StringLiteral",
+			pos:  position{line: 475, col: 1, offset: 11290},
 			expr: &actionExpr{
-				pos: position{line: 266, col: 18, offset: 5955},
-				run: (*parser).callonPrimitiveName1,
-				expr: &labeledExpr{
-					pos:   position{line: 266, col: 18, offset: 5955},
-					label: "Name",
-					expr: &ruleRefExpr{
-						pos:  position{line: 266, col: 23, offset: 5960},
-						name: "Identifier",
+				pos: position{line: 475, col: 18, offset: 11307},
+				run: (*parser).callonStringLiteral1,
+				expr: &seqExpr{
+					pos: position{line: 475, col: 18, offset: 11307},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 475, col: 18, offset: 11307},
+							val:        "\"",
+							ignoreCase: false,
+						},
+						&zeroOrMoreExpr{
+							pos: position{line: 475, col: 22, offset: 11311},
+							expr: &charClassMatcher{
+								pos:        position{line: 475, col: 22, offset: 11311},
+								val:        "[^\"\\n]",
+								chars:      []rune{'"', '\n'},
+								ignoreCase: false,
+								inverted:   true,
+							},
+						},
+						&litMatcher{
+							pos:        position{line: 475, col: 30, offset: 11319},
+							val:        "\"",
+							ignoreCase: false,
+						},
 					},
 				},
 			},
 		},
 		{
-			name: "Equation",
-			pos:  position{line: 270, col: 1, offset: 6020},
+			name: "NumberLiteral",
+			pos:  position{line: 479, col: 1, offset: 11356},
 			expr: &actionExpr{
-				pos: position{line: 270, col: 13, offset: 6032},
-				run: (*parser).callonEquation1,
+				pos: position{line: 479, col: 18, offset: 11373},
+				run: (*parser).callonNumberLiteral1,
 				expr: &seqExpr{
-					pos: position{line: 270, col: 13, offset: 6032},
+					pos: position{line: 479, col: 18, offset: 11373},
 					exprs: []interface{}{
-						&labeledExpr{
-							pos:   position{line: 270, col: 13, offset: 6032},
-							label: "First",
-							expr: &ruleRefExpr{
-								pos:  position{line: 270, col: 19, offset: 6038},
-								name: "Constant",
-							},
-						},
-						&seqExpr{
-							pos: position{line: 270, col: 29, offset: 6048},
-							exprs: []interface{}{
-								&ruleRefExpr{
-									pos:  position{line: 270, col: 29, offset: 6048},
-									name: "_",
-								},
-								&litMatcher{
-									pos:        position{line: 270, col: 31, offset: 6050},
-									val:        "^",
-									ignoreCase: false,
+						&choiceExpr{
+							pos: position{line: 479, col: 19, offset: 11374},
+							alternatives: []interface{}{
+								&seqExpr{
+									pos: position{line: 479, col: 19, offset: 11374},
+									exprs: []interface{}{
+										&litMatcher{
+											pos:        position{line: 479, col: 19, offset: 11374},
+											val:        "0x",
+											ignoreCase: false,
+										},
+										&oneOrMoreExpr{
+											pos: position{line: 479, col: 24, offset: 11379},
+											expr: &charClassMatcher{
+												pos:        position{line: 479, col: 24, offset: 11379},
+												val:        "[0-9a-fA-F]",
+												ranges:     []rune{'0', '9', 'a', 'f', 'A', 'F'},
+												ignoreCase: false,
+												inverted:   false,
+											},
+										},
+									},
 								},
-								&ruleRefExpr{
-									pos:  position{line: 270, col: 35, offset: 6054},
-									name: "_",
+								&oneOrMoreExpr{
+									pos: position{line: 479, col: 39, offset: 11394},
+									expr: &charClassMatcher{
+										pos:        position{line: 479, col: 39, offset: 11394},
+										val:        "[0-9]",
+										ranges:     []rune{'0', '9'},
+										ignoreCase: false,
+										inverted:   false,
+									},
 								},
 							},
 						},
-						&labeledExpr{
-							pos:   position{line: 270, col: 38, offset: 6057},
-							label: "Second",
-							expr: &ruleRefExpr{
-								pos:  position{line: 270, col: 45, offset: 6064},
-								name: "Constant",
+						&notExpr{
+							pos: position{line: 479, col: 47, offset: 11402},
+							expr: &charClassMatcher{
+								pos:        position{line: 479, col: 48, offset: 11403},
+								val:        "[a-zA-Z0-9_]",
+								chars:      []rune{'_'},
+								ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
+								ignoreCase: false,
+								inverted:   false,
 							},
 						},
 					},
@@ -991,20 +1868,24 @@ var g = &grammar{
 		},
 		{
 			name: "Value",
-			pos:  position{line: 282, col: 1, offset: 6213},
+			pos:  position{line: 483, col: 1, offset: 11449},
 			expr: &choiceExpr{
-				pos: position{line: 282, col: 10, offset: 6222},
+				pos: position{line: 483, col: 10, offset: 11458},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 282, col: 10, offset: 6222},
+						pos:  position{line: 483, col: 10, offset: 11458},
 						name: "Primitive",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 282, col: 20, offset: 6232},
+						pos:  position{line: 483, col: 20, offset: 11468},
 						name: "Equation",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 282, col: 29, offset: 6241},
+						pos:  position{line: 483, col: 29, offset: 11477},
+						name: "Literal",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 483, col: 37, offset: 11485},
 						name: "Constant",
 					},
 				},
@@ -1012,49 +1893,49 @@ var g = &grammar{
 		},
 		{
 			name: "Queries",
-			pos:  position{line: 284, col: 1, offset: 6252},
+			pos:  position{line: 485, col: 1, offset: 11496},
 			expr: &actionExpr{
-				pos: position{line: 284, col: 12, offset: 6263},
+				pos: position{line: 485, col: 12, offset: 11507},
 				run: (*parser).callonQueries1,
 				expr: &seqExpr{
-					pos: position{line: 284, col: 12, offset: 6263},
+					pos: position{line: 485, col: 12, offset: 11507},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 284, col: 12, offset: 6263},
+							pos:        position{line: 485, col: 12, offset: 11507},
 							val:        "queries",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 284, col: 22, offset: 6273},
+							pos:  position{line: 485, col: 22, offset: 11517},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 284, col: 24, offset: 6275},
+							pos:        position{line: 485, col: 24, offset: 11519},
 							val:        "[",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 284, col: 28, offset: 6279},
+							pos:  position{line: 485, col: 28, offset: 11523},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 284, col: 30, offset: 6281},
+							pos:   position{line: 485, col: 30, offset: 11525},
 							label: "Queries",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 284, col: 39, offset: 6290},
+								pos: position{line: 485, col: 39, offset: 11534},
 								expr: &ruleRefExpr{
-									pos:  position{line: 284, col: 39, offset: 6290},
+									pos:  position{line: 485, col: 39, offset: 11534},
 									name: "Query",
 								},
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 284, col: 47, offset: 6298},
+							pos:        position{line: 485, col: 47, offset: 11542},
 							val:        "]",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 284, col: 51, offset: 6302},
+							pos:  position{line: 485, col: 51, offset: 11546},
 							name: "_",
 						},
 					},
@@ -1063,49 +1944,49 @@ var g = &grammar{
 		},
 		{
 			name: "Query",
-			pos:  position{line: 288, col: 1, offset: 6330},
+			pos:  position{line: 489, col: 1, offset: 11574},
 			expr: &actionExpr{
-				pos: position{line: 288, col: 10, offset: 6339},
+				pos: position{line: 489, col: 10, offset: 11583},
 				run: (*parser).callonQuery1,
 				expr: &seqExpr{
-					pos: position{line: 288, col: 10, offset: 6339},
+					pos: position{line: 489, col: 10, offset: 11583},
 					exprs: []interface{}{
 						&zeroOrMoreExpr{
-							pos: position{line: 288, col: 10, offset: 6339},
+							pos: position{line: 489, col: 10, offset: 11583},
 							expr: &ruleRefExpr{
-								pos:  position{line: 288, col: 10, offset: 6339},
+								pos:  position{line: 489, col: 10, offset: 11583},
 								name: "Comment",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 288, col: 19, offset: 6348},
+							pos:   position{line: 489, col: 19, offset: 11592},
 							label: "Query",
 							expr: &choiceExpr{
-								pos: position{line: 288, col: 26, offset: 6355},
+								pos: position{line: 489, col: 26, offset: 11599},
 								alternatives: []interface{}{
 									&ruleRefExpr{
-										pos:  position{line: 288, col: 26, offset: 6355},
+										pos:  position{line: 489, col: 26, offset: 11599},
 										name: "QueryConfidentiality",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 288, col: 47, offset: 6376},
+										pos:  position{line: 489, col: 47, offset: 11620},
 										name: "QueryAuthentication",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 288, col: 67, offset: 6396},
+										pos:  position{line: 489, col: 67, offset: 11640},
 										name: "QueryFreshness",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 288, col: 82, offset: 6411},
+										pos:  position{line: 489, col: 82, offset: 11655},
 										name: "QueryUnlinkability",
 									},
 								},
 							},
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 288, col: 102, offset: 6431},
+							pos: position{line: 489, col: 102, offset: 11675},
 							expr: &ruleRefExpr{
-								pos:  position{line: 288, col: 102, offset: 6431},
+								pos:  position{line: 489, col: 102, offset: 11675},
 								name: "Comment",
 							},
 						},
@@ -1115,47 +1996,47 @@ var g = &grammar{
 		},
 		{
 			name: "QueryConfidentiality",
-			pos:  position{line: 292, col: 1, offset: 6465},
+			pos:  position{line: 493, col: 1, offset: 11709},
 			expr: &actionExpr{
-				pos: position{line: 292, col: 25, offset: 6489},
+				pos: position{line: 493, col: 25, offset: 11733},
 				run: (*parser).callonQueryConfidentiality1,
 				expr: &seqExpr{
-					pos: position{line: 292, col: 25, offset: 6489},
+					pos: position{line: 493, col: 25, offset: 11733},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 292, col: 25, offset: 6489},
+							pos:        position{line: 493, col: 25, offset: 11733},
 							val:        "confidentiality?",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 292, col: 44, offset: 6508},
+							pos:  position{line: 493, col: 44, offset: 11752},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 292, col: 46, offset: 6510},
+							pos:   position{line: 493, col: 46, offset: 11754},
 							label: "Const",
 							expr: &ruleRefExpr{
-								pos:  position{line: 292, col: 52, offset: 6516},
+								pos:  position{line: 493, col: 52, offset: 11760},
 								name: "Constant",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 292, col: 61, offset: 6525},
+							pos:  position{line: 493, col: 61, offset: 11769},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 292, col: 63, offset: 6527},
+							pos:   position{line: 493, col: 63, offset: 11771},
 							label: "Options",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 292, col: 71, offset: 6535},
+								pos: position{line: 493, col: 71, offset: 11779},
 								expr: &ruleRefExpr{
-									pos:  position{line: 292, col: 71, offset: 6535},
+									pos:  position{line: 493, col: 71, offset: 11779},
 									name: "QueryOptions",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 292, col: 85, offset: 6549},
+							pos:  position{line: 493, col: 85, offset: 11793},
 							name: "_",
 						},
 					},
@@ -1164,47 +2045,47 @@ var g = &grammar{
 		},
 		{
 			name: "QueryAuthentication",
-			pos:  position{line: 304, col: 1, offset: 6765},
+			pos:  position{line: 505, col: 1, offset: 12016},
 			expr: &actionExpr{
-				pos: position{line: 304, col: 24, offset: 6788},
+				pos: position{line: 505, col: 24, offset: 12039},
 				run: (*parser).callonQueryAuthentication1,
 				expr: &seqExpr{
-					pos: position{line: 304, col: 24, offset: 6788},
+					pos: position{line: 505, col: 24, offset: 12039},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 304, col: 24, offset: 6788},
+							pos:        position{line: 505, col: 24, offset: 12039},
 							val:        "authentication?",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 304, col: 42, offset: 6806},
+							pos:  position{line: 505, col: 42, offset: 12057},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 304, col: 44, offset: 6808},
+							pos:   position{line: 505, col: 44, offset: 12059},
 							label: "Message",
 							expr: &ruleRefExpr{
-								pos:  position{line: 304, col: 52, offset: 6816},
+								pos:  position{line: 505, col: 52, offset: 12067},
 								name: "Message",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 304, col: 60, offset: 6824},
+							pos:  position{line: 505, col: 60, offset: 12075},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 304, col: 62, offset: 6826},
+							pos:   position{line: 505, col: 62, offset: 12077},
 							label: "Options",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 304, col: 70, offset: 6834},
+								pos: position{line: 505, col: 70, offset: 12085},
 								expr: &ruleRefExpr{
-									pos:  position{line: 304, col: 70, offset: 6834},
+									pos:  position{line: 505, col: 70, offset: 12085},
 									name: "QueryOptions",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 304, col: 84, offset: 6848},
+							pos:  position{line: 505, col: 84, offset: 12099},
 							name: "_",
 						},
 					},
@@ -1213,47 +2094,47 @@ var g = &grammar{
 		},
 		{
 			name: "QueryFreshness",
-			pos:  position{line: 316, col: 1, offset: 7057},
+			pos:  position{line: 517, col: 1, offset: 12315},
 			expr: &actionExpr{
-				pos: position{line: 316, col: 19, offset: 7075},
+				pos: position{line: 517, col: 19, offset: 12333},
 				run: (*parser).callonQueryFreshness1,
 				expr: &seqExpr{
-					pos: position{line: 316, col: 19, offset: 7075},
+					pos: position{line: 517, col: 19, offset: 12333},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 316, col: 19, offset: 7075},
+							pos:        position{line: 517, col: 19, offset: 12333},
 							val:        "freshness?",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 316, col: 32, offset: 7088},
+							pos:  position{line: 517, col: 32, offset: 12346},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 316, col: 34, offset: 7090},
+							pos:   position{line: 517, col: 34, offset: 12348},
 							label: "Const",
 							expr: &ruleRefExpr{
-								pos:  position{line: 316, col: 40, offset: 7096},
+								pos:  position{line: 517, col: 40, offset: 12354},
 								name: "Constant",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 316, col: 49, offset: 7105},
+							pos:  position{line: 517, col: 49, offset: 12363},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 316, col: 51, offset: 7107},
+							pos:   position{line: 517, col: 51, offset: 12365},
 							label: "Options",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 316, col: 59, offset: 7115},
+								pos: position{line: 517, col: 59, offset: 12373},
 								expr: &ruleRefExpr{
-									pos:  position{line: 316, col: 59, offset: 7115},
+									pos:  position{line: 517, col: 59, offset: 12373},
 									name: "QueryOptions",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 316, col: 73, offset: 7129},
+							pos:  position{line: 517, col: 73, offset: 12387},
 							name: "_",
 						},
 					},
@@ -1262,47 +2143,47 @@ var g = &grammar{
 		},
 		{
 			name: "QueryUnlinkability",
-			pos:  position{line: 328, col: 1, offset: 7339},
+			pos:  position{line: 529, col: 1, offset: 12604},
 			expr: &actionExpr{
-				pos: position{line: 328, col: 23, offset: 7361},
+				pos: position{line: 529, col: 23, offset: 12626},
 				run: (*parser).callonQueryUnlinkability1,
 				expr: &seqExpr{
-					pos: position{line: 328, col: 23, offset: 7361},
+					pos: position{line: 529, col: 23, offset: 12626},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 328, col: 23, offset: 7361},
+							pos:        position{line: 529, col: 23, offset: 12626},
 							val:        "unlinkability?",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 328, col: 40, offset: 7378},
+							pos:  position{line: 529, col: 40, offset: 12643},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 328, col: 42, offset: 7380},
+							pos:   position{line: 529, col: 42, offset: 12645},
 							label: "Constants",
 							expr: &ruleRefExpr{
-								pos:  position{line: 328, col: 52, offset: 7390},
+								pos:  position{line: 529, col: 52, offset: 12655},
 								name: "Constants",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 328, col: 62, offset: 7400},
+							pos:  position{line: 529, col: 62, offset: 12665},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 328, col: 64, offset: 7402},
+							pos:   position{line: 529, col: 64, offset: 12667},
 							label: "Options",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 328, col: 72, offset: 7410},
+								pos: position{line: 529, col: 72, offset: 12675},
 								expr: &ruleRefExpr{
-									pos:  position{line: 328, col: 72, offset: 7410},
+									pos:  position{line: 529, col: 72, offset: 12675},
 									name: "QueryOptions",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 328, col: 86, offset: 7424},
+							pos:  position{line: 529, col: 86, offset: 12689},
 							name: "_",
 						},
 					},
@@ -1311,40 +2192,40 @@ var g = &grammar{
 		},
 		{
 			name: "QueryOptions",
-			pos:  position{line: 340, col: 1, offset: 7626},
+			pos:  position{line: 541, col: 1, offset: 12898},
 			expr: &actionExpr{
-				pos: position{line: 340, col: 17, offset: 7642},
+				pos: position{line: 541, col: 17, offset: 12914},
 				run: (*parser).callonQueryOptions1,
 				expr: &seqExpr{
-					pos: position{line: 340, col: 17, offset: 7642},
+					pos: position{line: 541, col: 17, offset: 12914},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 340, col: 17, offset: 7642},
+							pos:        position{line: 541, col: 17, offset: 12914},
 							val:        "[",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 340, col: 21, offset: 7646},
+							pos:  position{line: 541, col: 21, offset: 12918},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 340, col: 23, offset: 7648},
+							pos:   position{line: 541, col: 23, offset: 12920},
 							label: "Options",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 340, col: 32, offset: 7657},
+								pos: position{line: 541, col: 32, offset: 12929},
 								expr: &ruleRefExpr{
-									pos:  position{line: 340, col: 32, offset: 7657},
+									pos:  position{line: 541, col: 32, offset: 12929},
 									name: "QueryOption",
 								},
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 340, col: 46, offset: 7671},
+							pos:        position{line: 541, col: 46, offset: 12943},
 							val:        "]",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 340, col: 50, offset: 7675},
+							pos:  position{line: 541, col: 50, offset: 12947},
 							name: "_",
 						},
 					},
@@ -1353,53 +2234,53 @@ var g = &grammar{
 		},
 		{
 			name: "QueryOption",
-			pos:  position{line: 347, col: 1, offset: 7812},
+			pos:  position{line: 548, col: 1, offset: 13084},
 			expr: &actionExpr{
-				pos: position{line: 347, col: 16, offset: 7827},
+				pos: position{line: 548, col: 16, offset: 13099},
 				run: (*parser).callonQueryOption1,
 				expr: &seqExpr{
-					pos: position{line: 347, col: 16, offset: 7827},
+					pos: position{line: 548, col: 16, offset: 13099},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 347, col: 16, offset: 7827},
+							pos:   position{line: 548, col: 16, offset: 13099},
 							label: "OptionName",
 							expr: &ruleRefExpr{
-								pos:  position{line: 347, col: 27, offset: 7838},
+								pos:  position{line: 548, col: 27, offset: 13110},
 								name: "Identifier",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 347, col: 38, offset: 7849},
+							pos:  position{line: 548, col: 38, offset: 13121},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 347, col: 40, offset: 7851},
+							pos:        position{line: 548, col: 40, offset: 13123},
 							val:        "[",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 347, col: 44, offset: 7855},
+							pos:  position{line: 548, col: 44, offset: 13127},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 347, col: 46, offset: 7857},
+							pos:   position{line: 548, col: 46, offset: 13129},
 							label: "Message",
 							expr: &ruleRefExpr{
-								pos:  position{line: 347, col: 54, offset: 7865},
+								pos:  position{line: 548, col: 54, offset: 13137},
 								name: "Message",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 347, col: 62, offset: 7873},
+							pos:  position{line: 548, col: 62, offset: 13145},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 347, col: 64, offset: 7875},
+							pos:        position{line: 548, col: 64, offset: 13147},
 							val:        "]",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 347, col: 68, offset: 7879},
+							pos:  position{line: 548, col: 68, offset: 13151},
 							name: "_",
 						},
 					},
@@ -1408,22 +2289,63 @@ var g = &grammar{
 		},
 		{
 			name: "Identifier",
-			pos:  position{line: 354, col: 1, offset: 7982},
+			pos:  position{line: 559, col: 1, offset: 13348},
 			expr: &actionExpr{
-				pos: position{line: 354, col: 15, offset: 7996},
+				pos: position{line: 559, col: 15, offset: 13362},
 				run: (*parser).callonIdentifier1,
 				expr: &labeledExpr{
-					pos:   position{line: 354, col: 15, offset: 7996},
+					pos:   position{line: 559, col: 15, offset: 13362},
 					label: "Identifier",
 					expr: &oneOrMoreExpr{
-						pos: position{line: 354, col: 26, offset: 8007},
-						expr: &charClassMatcher{
-							pos:        position{line: 354, col: 26, offset: 8007},
-							val:        "[a-zA-Z0-9_]",
-							chars:      []rune{'_'},
-							ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
-							ignoreCase: false,
-							inverted:   false,
+						pos: position{line: 559, col: 26, offset: 13373},
+						expr: &choiceExpr{
+							pos: position{line: 559, col: 27, offset: 13374},
+							alternatives: []interface{}{
+								&charClassMatcher{
+									pos:        position{line: 559, col: 27, offset: 13374},
+									val:        "[a-zA-Z0-9_]",
+									chars:      []rune{'_'},
+									ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
+									ignoreCase: false,
+									inverted:   false,
+								},
+								&seqExpr{
+									pos: position{line: 559, col: 42, offset: 13389},
+									exprs: []interface{}{
+										&litMatcher{
+											pos:        position{line: 559, col: 42, offset: 13389},
+											val:        "{",
+											ignoreCase: false,
+										},
+										&oneOrMoreExpr{
+											pos: position{line: 559, col: 46, offset: 13393},
+											expr: &choiceExpr{
+												pos: position{line: 559, col: 47, offset: 13394},
+												alternatives: []interface{}{
+													&charClassMatcher{
+														pos:        position{line: 559, col: 47, offset: 13394},
+														val:        "[a-zA-Z0-9_+ ]",
+														chars:      []rune{'_', '+', ' '},
+														ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
+														ignoreCase: false,
+														inverted:   false,
+													},
+													&litMatcher{
+														pos:        position{line: 559, col: 64, offset: 13411},
+														val:        "-",
+														ignoreCase: false,
+													},
+												},
+											},
+										},
+										&litMatcher{
+											pos:        position{line: 559, col: 70, offset: 13417},
+											val:        "}",
+											ignoreCase: false,
+										},
+									},
+								},
+							},
 						},
 					},
 				},
@@ -1431,23 +2353,23 @@ var g = &grammar{
 		},
 		{
 			name: "Comment",
-			pos:  position{line: 359, col: 1, offset: 8097},
+			pos:  position{line: 564, col: 1, offset: 13499},
 			expr: &seqExpr{
-				pos: position{line: 359, col: 12, offset: 8108},
+				pos: position{line: 564, col: 12, offset: 13510},
 				exprs: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 359, col: 12, offset: 8108},
+						pos:  position{line: 564, col: 12, offset: 13510},
 						name: "_",
 					},
 					&litMatcher{
-						pos:        position{line: 359, col: 14, offset: 8110},
+						pos:        position{line: 564, col: 14, offset: 13512},
 						val:        "//",
 						ignoreCase: false,
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 359, col: 19, offset: 8115},
+						pos: position{line: 564, col: 19, offset: 13517},
 						expr: &charClassMatcher{
-							pos:        position{line: 359, col: 19, offset: 8115},
+							pos:        position{line: 564, col: 19, offset: 13517},
 							val:        "[^\\n]",
 							chars:      []rune{'\n'},
 							ignoreCase: false,
@@ -1455,7 +2377,7 @@ var g = &grammar{
 						},
 					},
 					&ruleRefExpr{
-						pos:  position{line: 359, col: 26, offset: 8122},
+						pos:  position{line: 564, col: 26, offset: 13524},
 						name: "_",
 					},
 				},
@@ -1464,11 +2386,11 @@ var g = &grammar{
 		{
 			name:        "_",
 			displayName: "\"whitespace\"",
-			pos:         position{line: 361, col: 1, offset: 8125},
+			pos:         position{line: 566, col: 1, offset: 13527},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 361, col: 19, offset: 8143},
+				pos: position{line: 566, col: 19, offset: 13545},
 				expr: &charClassMatcher{
-					pos:        position{line: 361, col: 19, offset: 8143},
+					pos:        position{line: 566, col: 19, offset: 13545},
 					val:        "[ \\t\\n\\r]",
 					chars:      []rune{' ', '\t', '\n', '\r'},
 					ignoreCase: false,
@@ -1478,18 +2400,35 @@ var g = &grammar{
 		},
 		{
 			name: "EOF",
-			pos:  position{line: 363, col: 1, offset: 8155},
+			pos:  position{line: 568, col: 1, offset: 13557},
 			expr: &notExpr{
-				pos: position{line: 363, col: 8, offset: 8162},
+				pos: position{line: 568, col: 8, offset: 13564},
 				expr: &anyMatcher{
-					line: 363, col: 9, offset: 8163,
+					line: 568, col: 9, offset: 13565,
 				},
 			},
 		},
 	},
 }
 
-func (c *current) onModel1(Attacker, Blocks, Queries interface{}) (interface{}, error) {
+func (c *current) onModel1(Imports, Attacker, Blocks, Queries interface{}) (interface{}, error) {
+	i := Imports.([]interface{})
+	di := make([]Import, len(i))
+	for ii, v := range i {
+		di[ii] = v.(Import)
+	}
+	if Attacker == nil && Queries == nil {
+		var db []Block
+		if Blocks != nil {
+			for _, v := range Blocks.([]interface{}) {
+				db = append(db, v.(Block))
+			}
+		}
+		return Model{
+			Imports: di,
+			Blocks:  db,
+		}, nil
+	}
 	switch {
 	case Attacker == nil:
 		return nil, errors.New("no `attacker` block defined")
@@ -1507,32 +2446,39 @@ func (c *current) onModel1(Attacker, Blocks, Queries interface{}) (interface{},
 	}
 	for i, v := range q {
 		dq[i] = v.(Query)
+		dq[i].ID = i
 	}
+	a := Attacker.(Model)
 	return Model{
-		Attacker: Attacker.(string),
-		Blocks:   db,
-		Queries:  dq,
+		Attacker:         a.Attacker,
+		AttackerDepth:    a.AttackerDepth,
+		AttackerChannels: a.AttackerChannels,
+		Imports:          di,
+		Blocks:           db,
+		Queries:          dq,
 	}, nil
 }
 
 func (p *parser) callonModel1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onModel1(stack["Attacker"], stack["Blocks"], stack["Queries"])
+	return p.cur.onModel1(stack["Imports"], stack["Attacker"], stack["Blocks"], stack["Queries"])
 }
 
-func (c *current) onAttacker1(Type interface{}) (interface{}, error) {
-	return Type, nil
+func (c *current) onAttacker1(Attacker interface{}) (interface{}, error) {
+	return Attacker, nil
 }
 
 func (p *parser) callonAttacker1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onAttacker1(stack["Type"])
+	return p.cur.onAttacker1(stack["Attacker"])
 }
 
 func (c *current) onAttackerType1() (interface{}, error) {
-	return string(c.text), nil
+	return Model{
+		Attacker: string(c.text),
+	}, nil
 }
 
 func (p *parser) callonAttackerType1() (interface{}, error) {
@@ -1541,6 +2487,71 @@ func (p *parser) callonAttackerType1() (interface{}, error) {
 	return p.cur.onAttackerType1()
 }
 
+func (c *current) onAttackerBoundedDepth1(Depth interface{}) (interface{}, error) {
+	a := Depth.([]interface{})
+	da := make([]uint8, len(a))
+	for i, v := range a {
+		da[i] = v.([]uint8)[0]
+	}
+	n, err := strconv.Atoi(b2s(da))
+	return Model{
+		Attacker:      "bounded-depth",
+		AttackerDepth: n,
+	}, err
+}
+
+func (p *parser) callonAttackerBoundedDepth1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onAttackerBoundedDepth1(stack["Depth"])
+}
+
+func (c *current) onAttackerPerChannel1(Channels interface{}) (interface{}, error) {
+	a := Channels.([]interface{})
+	da := make([]Message, len(a))
+	for i, v := range a {
+		da[i] = v.(Message)
+	}
+	return Model{
+		Attacker:         "per-channel",
+		AttackerChannels: da,
+	}, nil
+}
+
+func (p *parser) callonAttackerPerChannel1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onAttackerPerChannel1(stack["Channels"])
+}
+
+func (c *current) onAttackerChannel1(Sender, Recipient interface{}) (interface{}, error) {
+	return Message{
+		Sender:    Sender.(string),
+		Recipient: Recipient.(string),
+		Constants: []Constant{},
+	}, nil
+}
+
+func (p *parser) callonAttackerChannel1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onAttackerChannel1(stack["Sender"], stack["Recipient"])
+}
+
+func (c *current) onImport1(Path interface{}) (interface{}, error) {
+	return Import{
+		Path:   strings.Trim(Path.(string), "\""),
+		Line:   c.pos.line,
+		Column: c.pos.col,
+	}, nil
+}
+
+func (p *parser) callonImport1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onImport1(stack["Path"])
+}
+
 func (c *current) onBlock1(Block interface{}) (interface{}, error) {
 	return Block, nil
 }
@@ -1551,6 +2562,126 @@ func (p *parser) callonBlock1() (interface{}, error) {
 	return p.cur.onBlock1(stack["Block"])
 }
 
+func (c *current) onFragment1(Name, Parameters, Blocks interface{}) (interface{}, error) {
+	p := Parameters.([]interface{})
+	dp := make([]string, len(p))
+	for i, v := range p {
+		dp[i] = v.(string)
+	}
+	b := Blocks.([]interface{})
+	db := make([]Block, len(b))
+	for i, v := range b {
+		db[i] = v.(Block)
+	}
+	return Block{
+		Kind: BlockKindFragment,
+		Fragment: Fragment{
+			Name:       Name.(string),
+			Parameters: dp,
+			Blocks:     db,
+			Line:       c.pos.line,
+			Column:     c.pos.col,
+		},
+	}, libpegCheckIfReserved(Name.(string))
+}
+
+func (p *parser) callonFragment1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onFragment1(stack["Name"], stack["Parameters"], stack["Blocks"])
+}
+
+func (c *current) onFragmentParameter1(Name interface{}) (interface{}, error) {
+	return Name, libpegCheckIfReserved(Name.(string))
+}
+
+func (p *parser) callonFragmentParameter1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onFragmentParameter1(stack["Name"])
+}
+
+func (c *current) onRepeat1(Header, Blocks interface{}) (interface{}, error) {
+	b := Blocks.([]interface{})
+	db := make([]Block, len(b))
+	for i, v := range b {
+		db[i] = v.(Block)
+	}
+	r := Header.(Repeat)
+	r.Blocks = db
+	return Block{
+		Kind:   BlockKindRepeat,
+		Repeat: r,
+	}, nil
+}
+
+func (p *parser) callonRepeat1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRepeat1(stack["Header"], stack["Blocks"])
+}
+
+func (c *current) onRepeatHeader2(Index, Count interface{}) (interface{}, error) {
+	a := Count.([]interface{})
+	da := make([]uint8, len(a))
+	for i, v := range a {
+		da[i] = v.([]uint8)[0]
+	}
+	n, err := strconv.Atoi(b2s(da))
+	return Repeat{
+		Index: Index.(string),
+		Count: n,
+	}, err
+}
+
+func (p *parser) callonRepeatHeader2() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRepeatHeader2(stack["Index"], stack["Count"])
+}
+
+func (c *current) onRepeatHeader10(Count interface{}) (interface{}, error) {
+	a := Count.([]interface{})
+	da := make([]uint8, len(a))
+	for i, v := range a {
+		da[i] = v.([]uint8)[0]
+	}
+	n, err := strconv.Atoi(b2s(da))
+	return Repeat{
+		Index: "i",
+		Count: n,
+	}, err
+}
+
+func (p *parser) callonRepeatHeader10() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRepeatHeader10(stack["Count"])
+}
+
+func (c *current) onUse1(Name, Arguments interface{}) (interface{}, error) {
+	a := Arguments.([]interface{})
+	da := make([]string, len(a))
+	for i, v := range a {
+		da[i] = v.(string)
+	}
+	return Block{
+		Kind: BlockKindUse,
+		Use: Use{
+			Name:      Name.(string),
+			Arguments: da,
+			Line:      c.pos.line,
+			Column:    c.pos.col,
+		},
+	}, nil
+}
+
+func (p *parser) callonUse1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onUse1(stack["Name"], stack["Arguments"])
+}
+
 func (c *current) onPrincipal1(Name, Expressions interface{}) (interface{}, error) {
 	e := Expressions.([]interface{})
 	de := make([]Expression, len(e))
@@ -1558,7 +2689,7 @@ func (c *current) onPrincipal1(Name, Expressions interface{}) (interface{}, erro
 		de[i] = v.(Expression)
 	}
 	return Block{
-		Kind: "principal",
+		Kind: BlockKindPrincipal,
 		Principal: Principal{
 			Name:        Name.(string),
 			Expressions: de,
@@ -1593,13 +2724,16 @@ func (p *parser) callonQualifier1() (interface{}, error) {
 	return p.cur.onQualifier1()
 }
 
-func (c *current) onMessage1(Sender, Recipient, Constants interface{}) (interface{}, error) {
+func (c *current) onMessage1(Sender, Channel, Recipients, Constants interface{}) (interface{}, error) {
+	r := Recipients.([]string)
 	return Block{
-		Kind: "message",
+		Kind: BlockKindMessage,
 		Message: Message{
-			Sender:    Sender.(string),
-			Recipient: Recipient.(string),
-			Constants: Constants.([]Constant),
+			Sender:     Sender.(string),
+			Recipient:  r[0],
+			Recipients: r,
+			Channel:    Channel.(string),
+			Constants:  Constants.([]Constant),
 		},
 	}, nil
 }
@@ -1607,7 +2741,62 @@ func (c *current) onMessage1(Sender, Recipient, Constants interface{}) (interfac
 func (p *parser) callonMessage1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onMessage1(stack["Sender"], stack["Recipient"], stack["Constants"])
+	return p.cur.onMessage1(stack["Sender"], stack["Channel"], stack["Recipients"], stack["Constants"])
+}
+
+func (c *current) onMessageArrow2(Channel interface{}) (interface{}, error) {
+	return Channel, nil
+}
+
+func (p *parser) callonMessageArrow2() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onMessageArrow2(stack["Channel"])
+}
+
+func (c *current) onMessageArrow10() (interface{}, error) {
+	return "", nil
+}
+
+func (p *parser) callonMessageArrow10() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onMessageArrow10()
+}
+
+func (c *current) onMessageChannel1() (interface{}, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonMessageChannel1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onMessageChannel1()
+}
+
+func (c *current) onMessageRecipients1(Recipients interface{}) (interface{}, error) {
+	a := Recipients.([]interface{})
+	da := make([]string, len(a))
+	for i, v := range a {
+		da[i] = v.(string)
+	}
+	return da, nil
+}
+
+func (p *parser) callonMessageRecipients1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onMessageRecipients1(stack["Recipients"])
+}
+
+func (c *current) onMessageRecipient1(Name interface{}) (interface{}, error) {
+	return Name, nil
+}
+
+func (p *parser) callonMessageRecipient1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onMessageRecipient1(stack["Name"])
 }
 
 func (c *current) onMessageConstants1(MessageConstants interface{}) (interface{}, error) {
@@ -1638,7 +2827,7 @@ func (p *parser) callonExpression1() (interface{}, error) {
 
 func (c *current) onKnows1(Qualifier, Constants interface{}) (interface{}, error) {
 	return Expression{
-		Kind:      "knows",
+		Kind:      ExpressionKindKnows,
 		Qualifier: Qualifier.(string),
 		Constants: Constants.([]Constant),
 	}, nil
@@ -1652,7 +2841,7 @@ func (p *parser) callonKnows1() (interface{}, error) {
 
 func (c *current) onGenerates1(Constants interface{}) (interface{}, error) {
 	return Expression{
-		Kind:      "generates",
+		Kind:      ExpressionKindGenerates,
 		Qualifier: "",
 		Constants: Constants.([]Constant),
 	}, nil
@@ -1666,7 +2855,7 @@ func (p *parser) callonGenerates1() (interface{}, error) {
 
 func (c *current) onLeaks1(Constants interface{}) (interface{}, error) {
 	return Expression{
-		Kind:      "leaks",
+		Kind:      ExpressionKindLeaks,
 		Qualifier: "",
 		Constants: Constants.([]Constant),
 	}, nil
@@ -1680,7 +2869,7 @@ func (p *parser) callonLeaks1() (interface{}, error) {
 
 func (c *current) onAssignment1(Left, Right interface{}) (interface{}, error) {
 	switch Right.(Value).Kind {
-	case "constant":
+	case ValueKindConstant:
 		err := errors.New("cannot assign value to value")
 		return nil, err
 	}
@@ -1693,7 +2882,7 @@ func (c *current) onAssignment1(Left, Right interface{}) (interface{}, error) {
 		}
 	}
 	return Expression{
-		Kind:  "assignment",
+		Kind:  ExpressionKindAssignment,
 		Left:  consts,
 		Right: Right.(Value),
 	}, nil
@@ -1707,7 +2896,7 @@ func (p *parser) callonAssignment1() (interface{}, error) {
 
 func (c *current) onConstant1(Const interface{}) (interface{}, error) {
 	return Value{
-		Kind: "constant",
+		Kind: ValueKindConstant,
 		Constant: Constant{
 			Name: Const.(string),
 		},
@@ -1748,7 +2937,7 @@ func (c *current) onPhase1(Number interface{}) (interface{}, error) {
 	}
 	n, err := strconv.Atoi(b2s(da))
 	return Block{
-		Kind: "phase",
+		Kind: BlockKindPhase,
 		Phase: Phase{
 			Number: n,
 		},
@@ -1764,7 +2953,7 @@ func (p *parser) callonPhase1() (interface{}, error) {
 func (c *current) onGuardedConstant1(Guarded interface{}) (interface{}, error) {
 	err := libpegCheckIfReserved(Guarded.(string))
 	return Value{
-		Kind: "constant",
+		Kind: ValueKindConstant,
 		Constant: Constant{
 			Name:  Guarded.(string),
 			Guard: true,
@@ -1784,9 +2973,10 @@ func (c *current) onPrimitive1(Name, Arguments, Check interface{}) (interface{},
 		args = append(args, a.(Value))
 	}
 	return Value{
-		Kind: "primitive",
+		Kind: ValueKindPrimitive,
 		Primitive: Primitive{
 			Name:      Name.(string),
+			ID:        PrimitiveID(Name.(string)),
 			Arguments: args,
 			Output:    0,
 			Check:     Check != nil,
@@ -1810,14 +3000,15 @@ func (p *parser) callonPrimitiveName1() (interface{}, error) {
 	return p.cur.onPrimitiveName1(stack["Name"])
 }
 
-func (c *current) onEquation1(First, Second interface{}) (interface{}, error) {
+func (c *current) onEquation1(First, Rest interface{}) (interface{}, error) {
+	values := []Value{First.(Value)}
+	for _, v := range Rest.([]interface{}) {
+		values = append(values, v.([]interface{})[3].(Value))
+	}
 	return Value{
-		Kind: "equation",
+		Kind: ValueKindEquation,
 		Equation: Equation{
-			Values: []Value{
-				First.(Value),
-				Second.(Value),
-			},
+			Values: values,
 		},
 	}, nil
 }
@@ -1825,7 +3016,42 @@ func (c *current) onEquation1(First, Second interface{}) (interface{}, error) {
 func (p *parser) callonEquation1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onEquation1(stack["First"], stack["Second"])
+	return p.cur.onEquation1(stack["First"], stack["Rest"])
+}
+
+func (c *current) onLiteral1(Literal interface{}) (interface{}, error) {
+	return Value{
+		Kind: ValueKindConstant,
+		Constant: Constant{
+			Name: Literal.(string),
+		},
+	}, nil
+}
+
+func (p *parser) callonLiteral1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onLiteral1(stack["Literal"])
+}
+
+func (c *current) onStringLiteral1() (interface{}, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonStringLiteral1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onStringLiteral1()
+}
+
+func (c *current) onNumberLiteral1() (interface{}, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonNumberLiteral1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onNumberLiteral1()
 }
 
 func (c *current) onQueries1(Queries interface{}) (interface{}, error) {
@@ -1853,7 +3079,7 @@ func (c *current) onQueryConfidentiality1(Const, Options interface{}) (interface
 		Options = []QueryOption{}
 	}
 	return Query{
-		Kind:      "confidentiality",
+		Kind:      QueryKindConfidentiality,
 		Constants: []Constant{Const.(Value).Constant},
 		Message:   Message{},
 		Options:   Options.([]QueryOption),
@@ -1871,7 +3097,7 @@ func (c *current) onQueryAuthentication1(Message, Options interface{}) (interfac
 		Options = []QueryOption{}
 	}
 	return Query{
-		Kind:      "authentication",
+		Kind:      QueryKindAuthentication,
 		Constants: []Constant{},
 		Message:   (Message.(Block)).Message,
 		Options:   Options.([]QueryOption),
@@ -1889,7 +3115,7 @@ func (c *current) onQueryFreshness1(Const, Options interface{}) (interface{}, er
 		Options = []QueryOption{}
 	}
 	return Query{
-		Kind:      "freshness",
+		Kind:      QueryKindFreshness,
 		Constants: []Constant{Const.(Value).Constant},
 		Message:   Message{},
 		Options:   Options.([]QueryOption),
@@ -1907,7 +3133,7 @@ func (c *current) onQueryUnlinkability1(Constants, Options interface{}) (interfa
 		Options = []QueryOption{}
 	}
 	return Query{
-		Kind:      "unlinkability",
+		Kind:      QueryKindUnlinkability,
 		Constants: Constants.([]Constant),
 		Message:   Message{},
 		Options:   Options.([]QueryOption),
@@ -1936,8 +3162,12 @@ func (p *parser) callonQueryOptions1() (interface{}, error) {
 }
 
 func (c *current) onQueryOption1(OptionName, Message interface{}) (interface{}, error) {
+	kind, err := queryOptionKindFromString(OptionName.(string))
+	if err != nil {
+		return QueryOption{}, err
+	}
 	return QueryOption{
-		Kind:    OptionName.(string),
+		Kind:    kind,
 		Message: (Message.(Block)).Message,
 	}, nil
 }
@@ -39,6 +39,20 @@ var libpv = PvTemplate{
 			)
 		}
 		for _, c := range valKnowledgeMap.Constants {
+			if valueConstantIsInternal(c) {
+				consts = fmt.Sprintf(
+					"%sconst internal_%s:bitstring [private].\n",
+					consts, c.Name[1:],
+				)
+				continue
+			}
+			if valueConstantIsLiteral(c) {
+				consts = fmt.Sprintf(
+					"%sconst %s:bitstring [data]. (* %s *)\n",
+					consts, pvLiteral(c), strings.ReplaceAll(c.Name, "*)", "* )"),
+				)
+				continue
+			}
 			priv := ""
 			switch c.Qualifier {
 			case "private":
@@ -123,6 +137,21 @@ var libpv = PvTemplate{
 			"\totherwise forall pk:bitstring, s:bitstring, m:bitstring;",
 			"\tSIGNVERIF(pk, s, m) = false.",
 			"fun RINGSIGN(bitstring, bitstring, bitstring, bitstring):bitstring.",
+			"fun RINGSIGNVERIF(bitstring, bitstring, bitstring, bitstring, bitstring):bool reduc",
+			"\tforall a:bitstring, b:bitstring, c:bitstring, m:bitstring;",
+			"\tRINGSIGNVERIF(exp(a, const_g), b, c, m, RINGSIGN(a, b, c, m)) = true",
+			"\totherwise forall a:bitstring, b:bitstring, c:bitstring, m:bitstring;",
+			"\tRINGSIGNVERIF(exp(a, const_g), c, b, m, RINGSIGN(a, b, c, m)) = true",
+			"\totherwise forall a:bitstring, b:bitstring, c:bitstring, m:bitstring;",
+			"\tRINGSIGNVERIF(b, exp(a, const_g), c, m, RINGSIGN(a, b, c, m)) = true",
+			"\totherwise forall a:bitstring, b:bitstring, c:bitstring, m:bitstring;",
+			"\tRINGSIGNVERIF(c, exp(a, const_g), b, m, RINGSIGN(a, b, c, m)) = true",
+			"\totherwise forall a:bitstring, b:bitstring, c:bitstring, m:bitstring;",
+			"\tRINGSIGNVERIF(b, c, exp(a, const_g), m, RINGSIGN(a, b, c, m)) = true",
+			"\totherwise forall a:bitstring, b:bitstring, c:bitstring, m:bitstring;",
+			"\tRINGSIGNVERIF(c, b, exp(a, const_g), m, RINGSIGN(a, b, c, m)) = true",
+			"\totherwise forall a:bitstring, b:bitstring, c:bitstring, m:bitstring, s:bitstring;",
+			"\tRINGSIGNVERIF(a, b, c, m, s) = false.",
 			"fun shamir_split1(bitstring):bitstring.",
 			"fun shamir_split2(bitstring):bitstring.",
 			"fun shamir_split3(bitstring):bitstring.",
@@ -150,6 +179,47 @@ var libpv = PvTemplate{
 			"\tUNBLIND(k, m, SIGN(a, BLIND(k, m))) = SIGN(a, m)",
 			"\totherwise forall k:bitstring, m:bitstring, a:bitstring;",
 			"\tUNBLIND(k, m, a) = const_nil.",
+			"fun kem_ct(bitstring, bitstring):bitstring.",
+			"fun kem_ss(bitstring, bitstring):bitstring.",
+			"letfun KEM_ENCAPS_R(pk:bitstring, r:bitstring) =",
+			"\t(kem_ct(pk, r), kem_ss(pk, r)).",
+			"fun KEM_DECAPS(bitstring, bitstring):bitstring reduc",
+			"\tforall k:bitstring, r:bitstring;",
+			"\tKEM_DECAPS(k, kem_ct(exp(k, const_g), r)) = kem_ss(exp(k, const_g), r)",
+			"\totherwise forall k:bitstring, ct:bitstring;",
+			"\tKEM_DECAPS(k, ct) = empty.",
+			"fun COMMIT(bitstring, bitstring):bitstring.",
+			"reduc forall v:bitstring, r:bitstring;",
+			"\tcommit_reveal(COMMIT(v, r), r) = v.",
+			"fun OPEN(bitstring, bitstring, bitstring):bitstring reduc",
+			"\tforall v:bitstring, r:bitstring;",
+			"\tOPEN(COMMIT(v, r), v, r) = v",
+			"\totherwise forall c:bitstring, v:bitstring, r:bitstring;",
+			"\tOPEN(c, v, r) = empty.",
+			"fun OPEN_check(bitstring, bitstring, bitstring):bool reduc",
+			"\tforall v:bitstring, r:bitstring;",
+			"\tOPEN_check(COMMIT(v, r), v, r) = true",
+			"\totherwise forall c:bitstring, v:bitstring, r:bitstring;",
+			"\tOPEN_check(c, v, r) = false.",
+			"fun ZKP_PROVE(bitstring, bitstring):bitstring.",
+			"fun ZKP_VERIFY(bitstring, bitstring):bool reduc",
+			"\tforall w:bitstring;",
+			"\tZKP_VERIFY(ZKP_PROVE(w, exp(w, const_g)), exp(w, const_g)) = true",
+			"\totherwise forall w:bitstring;",
+			"\tZKP_VERIFY(ZKP_PROVE(w, HASH1(w)), HASH1(w)) = true",
+			"\totherwise forall p:bitstring, s:bitstring;",
+			"\tZKP_VERIFY(p, s) = false.",
+			"fun XOR(bitstring, bitstring):bitstring.",
+			"equation forall a:bitstring, b:bitstring;",
+			"\tXOR(XOR(a, b), b) = a.",
+			"fun STREAM_ENC(bitstring, bitstring):bitstring.",
+			"fun STREAM_DEC(bitstring, bitstring):bitstring reduc",
+			"\tforall k:bitstring, m:bitstring;",
+			"\tSTREAM_DEC(k, STREAM_ENC(k, m)) = m",
+			"\totherwise forall k:bitstring, m:bitstring;",
+			"\tSTREAM_DEC(k, m) = empty.",
+			"reduc forall k:bitstring, m:bitstring, d:bitstring;",
+			"\tstream_malleate(STREAM_ENC(k, m), d) = STREAM_ENC(k, XOR(m, d)).",
 		}, "\n") + "\n"
 	},
 	Channels: func(valKnowledgeMap KnowledgeMap) string {
@@ -165,6 +235,12 @@ var libpv = PvTemplate{
 				), fmt.Sprintf(
 					"const chan_%s_to_%s_private:channel [private].",
 					prin1, prin2,
+				), fmt.Sprintf(
+					"const chan_%s_to_%s_conf:channel [private].",
+					prin1, prin2,
+				), fmt.Sprintf(
+					"let conf_%s_to_%s() =\n\tin(chan_%s_to_%s, (x:bitstring));\n\tout(chan_%s_to_%s_conf, (x)).",
+					prin1, prin2, prin1, prin2, prin1, prin2,
 				)}, "\n")
 				channels = append(channels, channel)
 			}
@@ -194,14 +270,14 @@ var libpv = PvTemplate{
 				sep = ""
 			}
 			switch block.Kind {
-			case "principal":
+			case BlockKindPrincipal:
 				parallel = fmt.Sprintf(
 					"%s%s_%d()%s",
 					parallel, block.Principal.Name,
 					pc, sep,
 				)
 				pc = pc + 1
-			case "message":
+			case BlockKindMessage:
 				parallel = fmt.Sprintf(
 					"%s%s_to_%s_%d()%s",
 					parallel, block.Message.Sender,
@@ -216,6 +292,17 @@ var libpv = PvTemplate{
 				pc = pc + 1
 			}
 		}
+		conf := map[string]bool{}
+		for _, block := range blocks {
+			if block.Kind != BlockKindMessage || pvMessageChannel(block.Message, Constant{}) != "confidential" {
+				continue
+			}
+			injector := fmt.Sprintf("conf_%s_to_%s", block.Message.Sender, block.Message.Recipient)
+			if !conf[injector] {
+				conf[injector] = true
+				parallel = fmt.Sprintf("%s | !%s()", parallel, injector)
+			}
+		}
 		output := strings.Join([]string{
 			"process (",
 			fmt.Sprintf("\t(%s)", parallel),
@@ -15,7 +15,7 @@ func mutationMapInit(
 		DepthIndex:     []int{},
 		OutOfMutations: false,
 	}
-	for _, v := range valAttackerState.Known {
+	for _, v := range mutationMapCandidates(valPrincipalState, valAttackerState) {
 		i := valueGetPrincipalStateIndexFromConstant(valPrincipalState, v.Constant)
 		if mutationMapSkipValue(v, i, valKnowledgeMap, valPrincipalState, valAttackerState) {
 			continue
@@ -41,13 +41,32 @@ func mutationMapInit(
 	return valMutationMap
 }
 
+// mutationMapCandidates returns the values that the attacker may replace in
+// the principal's state. Values sent only over confidential channels are never
+// learned by the attacker, but it can still replace them in transit, since
+// nothing authenticates the channel.
+func mutationMapCandidates(valPrincipalState PrincipalState, valAttackerState AttackerState) []Value {
+	candidates := valAttackerState.Known[:len(valAttackerState.Known):len(valAttackerState.Known)]
+	for i, c := range valPrincipalState.Constants {
+		if !valPrincipalState.Confidential[i] {
+			continue
+		}
+		v := Value{Kind: ValueKindConstant, Constant: c}
+		if attackerStateKnows(valAttackerState, v) >= 0 {
+			continue
+		}
+		candidates = append(candidates, v)
+	}
+	return candidates
+}
+
 func mutationMapSkipValue(
 	v Value, i int, valKnowledgeMap KnowledgeMap, valPrincipalState PrincipalState, valAttackerState AttackerState,
 ) bool {
 	switch v.Kind {
-	case "primitive":
+	case ValueKindPrimitive:
 		return true
-	case "equation":
+	case ValueKindEquation:
 		return true
 	}
 	switch {
@@ -55,6 +74,8 @@ func mutationMapSkipValue(
 		return true
 	case !strInSlice(valPrincipalState.Name, valPrincipalState.Wire[i]):
 		return true
+	case !attackerStateControlsChannel(valAttackerState, valPrincipalState.Sender[i], valPrincipalState.Name):
+		return true
 	case valPrincipalState.Guard[i]:
 		if !strInSlice(valPrincipalState.Sender[i], valPrincipalState.MutatableTo[i]) {
 			return true
@@ -76,15 +97,15 @@ func mutationMapReplaceValue(
 	valPrincipalState PrincipalState, valAttackerState AttackerState,
 ) (Constant, []Value) {
 	switch a.Kind {
-	case "constant":
+	case ValueKindConstant:
 		return v.Constant, mutationMapReplaceConstant(
 			a, stage, valPrincipalState, valAttackerState,
 		)
-	case "primitive":
+	case ValueKindPrimitive:
 		return v.Constant, mutationMapReplacePrimitive(
 			a, rootIndex, stage, valPrincipalState, valAttackerState,
 		)
-	case "equation":
+	case ValueKindEquation:
 		return v.Constant, mutationMapReplaceEquation(
 			a, stage, valAttackerState,
 		)
@@ -100,19 +121,21 @@ func mutationMapReplaceConstant(
 	if valueIsGOrNil(a.Constant) {
 		return mutations
 	}
-	mutations = append(mutations, valueN)
+	if attackerStateCanFabricate(valAttackerState) {
+		mutations = append(mutations, valueN)
+	}
 	if stage <= 3 {
 		return mutations
 	}
 	for _, v := range valAttackerState.Known {
 		switch v.Kind {
-		case "constant":
+		case ValueKindConstant:
 			if valueIsGOrNil(v.Constant) {
 				continue
 			}
 			c := valueResolveConstant(v.Constant, valPrincipalState)
 			switch c.Kind {
-			case "constant":
+			case ValueKindConstant:
 				if valueEquivalentValueInValues(c, mutations) < 0 {
 					mutations = append(mutations, c)
 				}
@@ -129,7 +152,7 @@ func mutationMapReplacePrimitive(
 	mutations := []Value{}
 	for _, v := range valAttackerState.Known {
 		switch v.Kind {
-		case "primitive":
+		case ValueKindPrimitive:
 			a = valueResolveValueInternalValuesFromPrincipalState(
 				a, a, rootIndex, valPrincipalState, valAttackerState, false,
 			)
@@ -141,10 +164,16 @@ func mutationMapReplacePrimitive(
 			}
 		}
 	}
+	if !attackerStateCanInject(valAttackerState) {
+		return mutations
+	}
 	injectants := inject(
 		a.Primitive, a.Primitive, true,
-		valPrincipalState, valAttackerState, stage,
+		valPrincipalState, valAttackerState, stage, 1,
 	)
+	injectants = append(injectants, injectMalleable(
+		a.Primitive, valPrincipalState, valAttackerState, stage,
+	)...)
 	for _, aa := range injectants {
 		if valueEquivalentValueInValues(aa, mutations) < 0 {
 			mutations = append(mutations, aa)
@@ -156,11 +185,14 @@ func mutationMapReplacePrimitive(
 func mutationMapReplaceEquation(a Value, stage int, valAttackerState AttackerState) []Value {
 	mutations := []Value{}
 	if stage <= 3 {
+		if !attackerStateCanFabricate(valAttackerState) {
+			return mutations
+		}
 		return []Value{valueGN}
 	}
 	for _, v := range valAttackerState.Known {
 		switch v.Kind {
-		case "equation":
+		case ValueKindEquation:
 			switch len(v.Equation.Values) {
 			case len(a.Equation.Values):
 				if valueEquivalentValueInValues(v, mutations) < 0 {
@@ -11,20 +11,23 @@ func possibleToDecomposePrimitive(
 	if primitiveIsCorePrim(p.Name) {
 		return false, Value{}, has
 	}
-	prim, _ := primitiveGet(p.Name)
+	prim, _ := primitiveGet(p)
 	if !prim.Decompose.HasRule {
 		return false, Value{}, has
 	}
+	if p.Name == "XOR" {
+		return possibleToDecomposeXor(p, valAttackerState)
+	}
 	for i, g := range prim.Decompose.Given {
 		a := p.Arguments[g]
 		a, valid := prim.Decompose.Filter(p, a, i)
-		ii := valueEquivalentValueInValues(a, valAttackerState.Known)
+		ii := attackerStateKnows(valAttackerState, a)
 		if valid && ii >= 0 {
 			has = append(has, a)
 			continue
 		}
 		switch a.Kind {
-		case "primitive":
+		case ValueKindPrimitive:
 			r, _ := possibleToReconstructPrimitive(a.Primitive, valAttackerState)
 			if r {
 				has = append(has, a)
@@ -35,7 +38,7 @@ func possibleToDecomposePrimitive(
 				has = append(has, a)
 				continue
 			}
-		case "equation":
+		case ValueKindEquation:
 			r, _ := possibleToReconstructEquation(a.Equation, valAttackerState)
 			if r {
 				has = append(has, a)
@@ -44,6 +47,9 @@ func possibleToDecomposePrimitive(
 		}
 	}
 	if len(has) >= len(prim.Decompose.Given) {
+		if prim.Decompose.To != nil {
+			return true, prim.Decompose.To(p), has
+		}
 		revealed := p.Arguments[prim.Decompose.Reveal]
 		return true, revealed, has
 	}
@@ -56,17 +62,23 @@ func possibleToRecomposePrimitive(
 	if primitiveIsCorePrim(p.Name) {
 		return false, Value{}, []Value{}
 	}
-	prim, _ := primitiveGet(p.Name)
+	prim, _ := primitiveGet(p)
 	if !prim.Recompose.HasRule {
 		return false, Value{}, []Value{}
 	}
+	switch p.Name {
+	case "XOR":
+		return possibleToRecomposeXor(p, valAttackerState)
+	case "SHAMIR_SPLIT":
+		return possibleToRecomposeShares(p, valAttackerState)
+	}
 	for _, i := range prim.Recompose.Given {
 		ar := []Value{}
 		for _, ii := range i {
 			for _, v := range valAttackerState.Known {
 				vb := v
 				switch v.Kind {
-				case "primitive":
+				case ValueKindPrimitive:
 					equivPrim, vo, _ := valueEquivalentPrimitives(
 						v.Primitive, p, false,
 					)
@@ -85,95 +97,257 @@ func possibleToRecomposePrimitive(
 	return false, Value{}, []Value{}
 }
 
-func possibleToReconstructPrimitive(
+// possibleToDecomposeXor reveals the one term of an XOR that the attacker
+// does not know, given all of its other terms. Since XOR is associative,
+// commutative and nilpotent, this is done over its normalized terms rather
+// than over its arguments, and any other known XOR, such as a second
+// ciphertext under a reused one-time pad, may cancel out shared terms.
+// Terms left over from such a combination must already be known, since
+// obtaining them could otherwise lead back to the same combination.
+func possibleToDecomposeXor(
 	p Primitive, valAttackerState AttackerState,
-) (bool, []Value) {
+) (bool, Value, []Value) {
+	x := Value{Kind: ValueKindPrimitive, Primitive: p}
+	r, revealed, has := possibleToDecomposeXorTerms(
+		valueXorTerms(x), func(t Value) bool {
+			return possibleToObtainValue(t, valAttackerState)
+		},
+	)
+	if r {
+		return true, revealed, has
+	}
+	for _, v := range valAttackerState.Known {
+		if !valueIsXor(v) || valueEquivalentValues(v, x, true) {
+			continue
+		}
+		r, revealed, has = possibleToDecomposeXorTerms(
+			possibleToCombineXor(x, v), func(t Value) bool {
+				return attackerStateKnows(valAttackerState, t) >= 0
+			},
+		)
+		if r {
+			return true, revealed, append([]Value{v}, has...)
+		}
+	}
+	return false, Value{}, []Value{}
+}
+
+func possibleToDecomposeXorTerms(
+	terms []Value, obtainable func(Value) bool,
+) (bool, Value, []Value) {
 	has := []Value{}
-	for _, a := range p.Arguments {
-		if valueEquivalentValueInValues(a, valAttackerState.Known) >= 0 {
-			has = append(has, a)
+	unknown := -1
+	for i, t := range terms {
+		if obtainable(t) {
+			has = append(has, t)
 			continue
 		}
-		switch a.Kind {
-		case "primitive":
-			r, _, _ := possibleToDecomposePrimitive(a.Primitive, valAttackerState)
-			if r {
-				has = append(has, a)
-				continue
-			}
-			r, _ = possibleToReconstructPrimitive(a.Primitive, valAttackerState)
-			if r {
-				has = append(has, a)
-				continue
-			}
-		case "equation":
-			r, _ := possibleToReconstructEquation(a.Equation, valAttackerState)
-			if r {
-				has = append(has, a)
-				continue
+		if unknown >= 0 {
+			return false, Value{}, []Value{}
+		}
+		unknown = i
+	}
+	if unknown < 0 {
+		return false, Value{}, []Value{}
+	}
+	return true, terms[unknown], has
+}
+
+// possibleToRecomposeXor obtains an XOR from another XOR known to the
+// attacker whenever every term on which the two differ is known as well.
+func possibleToRecomposeXor(
+	p Primitive, valAttackerState AttackerState,
+) (bool, Value, []Value) {
+	x := Value{Kind: ValueKindPrimitive, Primitive: p}
+	for _, v := range valAttackerState.Known {
+		if !valueIsXor(v) {
+			continue
+		}
+		terms := possibleToCombineXor(x, v)
+		if len(terms) == 0 {
+			continue
+		}
+		has := []Value{v}
+		for _, t := range terms {
+			if !possibleToObtainValue(t, valAttackerState) {
+				break
 			}
+			has = append(has, t)
+		}
+		if len(has) > len(terms) {
+			return true, x, has
+		}
+	}
+	return false, Value{}, []Value{}
+}
+
+// possibleToRecomposeShares recovers the secret of a SHAMIR_SPLIT once the
+// attacker knows as many of its shares as its threshold requires.
+func possibleToRecomposeShares(
+	p Primitive, valAttackerState AttackerState,
+) (bool, Value, []Value) {
+	ar := []Value{}
+	shares := []Primitive{}
+	for _, v := range valAttackerState.Known {
+		if v.Kind != ValueKindPrimitive || v.Primitive.Name != p.Name {
+			continue
+		}
+		if equivPrim, _, _ := valueEquivalentPrimitives(v.Primitive, p, false); !equivPrim {
+			continue
+		}
+		counted := false
+		for _, s := range shares {
+			counted = counted || s.Output == v.Primitive.Output
+		}
+		if counted {
+			continue
+		}
+		ar = append(ar, v)
+		shares = append(shares, v.Primitive)
+		if possibleToCombineShares(shares) {
+			return true, p.Arguments[0], ar
 		}
 	}
-	if len(has) < len(p.Arguments) {
+	return false, Value{}, []Value{}
+}
+
+func possibleToCombineXor(a1 Value, a2 Value) []Value {
+	return valueXorTerms(Value{
+		Kind: ValueKindPrimitive,
+		Primitive: Primitive{
+			Name:      "XOR",
+			ID:        primitiveIDXor,
+			Arguments: []Value{a1, a2},
+			Output:    0,
+			Check:     false,
+		},
+	})
+}
+
+func possibleToReconstructPrimitive(
+	p Primitive, valAttackerState AttackerState,
+) (bool, []Value) {
+	has := []Value{}
+	arguments := p.Arguments
+	if p.Name == "XOR" {
+		arguments = valueXorTerms(Value{Kind: ValueKindPrimitive, Primitive: p})
+	}
+	for _, a := range arguments {
+		if possibleToObtainValue(a, valAttackerState) {
+			has = append(has, a)
+		}
+	}
+	if len(has) < len(arguments) {
 		return false, []Value{}
 	}
 	return true, has
 }
 
+func possibleToObtainValue(a Value, valAttackerState AttackerState) bool {
+	if attackerStateKnows(valAttackerState, a) >= 0 {
+		return true
+	}
+	switch a.Kind {
+	case ValueKindPrimitive:
+		r, _, _ := possibleToDecomposePrimitive(a.Primitive, valAttackerState)
+		if r {
+			return true
+		}
+		r, _ = possibleToReconstructPrimitive(a.Primitive, valAttackerState)
+		return r
+	case ValueKindEquation:
+		r, _ := possibleToReconstructEquation(a.Equation, valAttackerState)
+		return r
+	}
+	return false
+}
+
 func possibleToReconstructEquation(
 	e Equation, valAttackerState AttackerState,
 ) (bool, []Value) {
+	e = valueFlattenEquation(e)
 	if len(e.Values) <= 2 {
-		if valueEquivalentValueInValues(e.Values[1], valAttackerState.Known) >= 0 {
+		if attackerStateKnows(valAttackerState, e.Values[1]) >= 0 {
 			return true, []Value{e.Values[1]}
 		}
 		return false, []Value{}
 	}
-	s0 := e.Values[1]
-	s1 := e.Values[2]
-	hs0 := valueEquivalentValueInValues(s0, valAttackerState.Known) >= 0
-	hs1 := valueEquivalentValueInValues(s1, valAttackerState.Known) >= 0
-	if hs0 && hs1 {
-		return true, []Value{s0, s1}
+	exponents := e.Values[1:]
+	known := make([]bool, len(exponents))
+	hasAll := true
+	for i, x := range exponents {
+		known[i] = attackerStateKnows(valAttackerState, x) >= 0
+		hasAll = hasAll && known[i]
 	}
-	p0 := Value{
-		Kind: "equation",
-		Equation: Equation{
-			Values: []Value{e.Values[0], e.Values[1]},
-		},
+	if hasAll {
+		return true, exponents
 	}
-	p1 := Value{
-		Kind: "equation",
-		Equation: Equation{
-			Values: []Value{e.Values[0], e.Values[2]},
-		},
+	for _, v := range valAttackerState.Known {
+		if v.Kind != ValueKindEquation {
+			continue
+		}
+		ve := valueFlattenEquation(v.Equation)
+		switch {
+		case len(ve.Values) < 2, len(ve.Values) >= len(e.Values):
+			continue
+		case !valueEquivalentValues(ve.Values[0], e.Values[0], true):
+			continue
+		}
+		if r, ok := possibleToReconstructEquationFrom(exponents, known, ve.Values[1:]); ok {
+			return true, append([]Value{v}, r...)
+		}
 	}
-	hp0 := valueEquivalentValueInValues(p0, valAttackerState.Known) >= 0
-	hp1 := valueEquivalentValueInValues(p1, valAttackerState.Known) >= 0
-	if hs0 && hp1 {
-		return true, []Value{s0, p1}
+	return false, []Value{}
+}
+
+// possibleToReconstructEquationFrom checks whether an exponent chain can
+// be completed from a partial chain already known to the attacker, with
+// every exponent missing from the partial chain being known as well.
+func possibleToReconstructEquationFrom(
+	exponents []Value, known []bool, partial []Value,
+) ([]Value, bool) {
+	used := make([]bool, len(exponents))
+	for _, p := range partial {
+		found := false
+		for i, x := range exponents {
+			if !used[i] && valueEquivalentValues(p, x, true) {
+				used[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return []Value{}, false
+		}
 	}
-	if hp0 && hs1 {
-		return true, []Value{p0, s1}
+	remaining := []Value{}
+	for i, x := range exponents {
+		if used[i] {
+			continue
+		}
+		if !known[i] {
+			return []Value{}, false
+		}
+		remaining = append(remaining, x)
 	}
-	return false, []Value{}
+	return remaining, true
 }
 
 func possibleToRewrite(
 	p Primitive, valPrincipalState PrincipalState,
 ) (bool, []Value) {
-	v := []Value{{Kind: "primitive", Primitive: p}}
+	v := []Value{{Kind: ValueKindPrimitive, Primitive: p}}
 	if primitiveIsCorePrim(p.Name) {
-		prim, _ := primitiveCoreGet(p.Name)
+		prim, _ := primitiveCoreGet(p)
 		if prim.HasRule {
 			return prim.CoreRule(p)
 		}
 		return !prim.Check, v
 	}
-	prim, _ := primitiveGet(p.Name)
-	from := p.Arguments[prim.Rewrite.From]
+	prim, _ := primitiveGet(p)
+	from := p.Arguments[possibleToRewriteFrom(p, prim)]
 	switch from.Kind {
-	case "primitive":
+	case ValueKindPrimitive:
 		if from.Primitive.Name != prim.Rewrite.Name {
 			return !prim.Check, v
 		}
@@ -189,8 +363,14 @@ func possibleToRewrite(
 func possibleToRewritePrim(
 	p Primitive, valPrincipalState PrincipalState,
 ) bool {
-	prim, _ := primitiveGet(p.Name)
-	from := p.Arguments[prim.Rewrite.From]
+	prim, _ := primitiveGet(p)
+	from := p.Arguments[possibleToRewriteFrom(p, prim)]
+	switch p.Name {
+	case "RINGSIGNVERIF":
+		return possibleToRewriteRingSign(p, from.Primitive)
+	case "THRESHOLD_VERIF":
+		return possibleToRewriteThresholdSign(p)
+	}
 	for a, m := range prim.Rewrite.Matching {
 		valid := false
 		for _, mm := range m {
@@ -201,7 +381,7 @@ func possibleToRewritePrim(
 			}
 			for i := range ax {
 				switch ax[i].Kind {
-				case "primitive":
+				case ValueKindPrimitive:
 					r, v := possibleToRewrite(ax[i].Primitive, valPrincipalState)
 					if r {
 						ax[i] = v[0]
@@ -220,14 +400,98 @@ func possibleToRewritePrim(
 	return true
 }
 
+// possibleToRewriteFrom returns the index of the argument that a rewrite
+// rule starts from, with negative indices counting from the last argument
+// for primitives of variable arity.
+func possibleToRewriteFrom(p Primitive, prim PrimitiveSpec) int {
+	if prim.Rewrite.From < 0 {
+		return len(p.Arguments) + prim.Rewrite.From
+	}
+	return prim.Rewrite.From
+}
+
+// possibleToRewriteRingSign checks that a ring signature verifies against
+// a ring of public keys, in any order, made up of the public key of its
+// signer and of the other public keys it was computed with, as well as
+// against the message it was computed over.
+func possibleToRewriteRingSign(p Primitive, s Primitive) bool {
+	n := len(p.Arguments) - 2
+	if len(s.Arguments) != n+1 {
+		return false
+	}
+	ring := []Value{{
+		Kind: ValueKindEquation,
+		Equation: Equation{
+			Values: []Value{valueG, s.Arguments[0]},
+		},
+	}}
+	ring = append(ring, s.Arguments[1:n]...)
+	found, _ := valueRemoveValues(ring, p.Arguments[:n])
+	if !found {
+		return false
+	}
+	return valueEquivalentValues(p.Arguments[n], s.Arguments[n], true)
+}
+
+// possibleToRewriteThresholdSign checks that enough partial signatures
+// over the same message, each computed with a different share of the same
+// SHAMIR_SPLIT, are given to reach its threshold, and that the public key
+// corresponds to the secret that was split.
+func possibleToRewriteThresholdSign(p Primitive) bool {
+	shares := []Primitive{}
+	for _, a := range p.Arguments[2:] {
+		if a.Kind != ValueKindPrimitive || a.Primitive.Name != "THRESHOLD_SIGN" {
+			return false
+		}
+		if !valueEquivalentValues(a.Primitive.Arguments[1], p.Arguments[1], true) {
+			return false
+		}
+		share := a.Primitive.Arguments[0]
+		if share.Kind != ValueKindPrimitive || share.Primitive.Name != "SHAMIR_SPLIT" {
+			return false
+		}
+		shares = append(shares, share.Primitive)
+	}
+	if !possibleToCombineShares(shares) {
+		return false
+	}
+	return valueEquivalentValues(p.Arguments[0], Value{
+		Kind: ValueKindEquation,
+		Equation: Equation{
+			Values: []Value{valueG, shares[0].Arguments[0]},
+		},
+	}, true)
+}
+
+// possibleToCombineShares checks whether secret shares all come from the
+// same SHAMIR_SPLIT, with no share counted twice, and whether there are
+// enough of them to reach its threshold.
+func possibleToCombineShares(shares []Primitive) bool {
+	if len(shares) == 0 {
+		return false
+	}
+	for i := range shares {
+		for ii := 0; ii < i; ii++ {
+			equivPrim, o1, o2 := valueEquivalentPrimitives(shares[i], shares[ii], false)
+			if !equivPrim || o1 == o2 {
+				return false
+			}
+		}
+	}
+	return len(shares) >= primitiveShamirThreshold(shares[0])
+}
+
 func possibleToRebuild(p Primitive) (bool, Value) {
 	if primitiveIsCorePrim(p.Name) {
 		return false, Value{}
 	}
-	prim, _ := primitiveGet(p.Name)
+	prim, _ := primitiveGet(p)
 	if !prim.Rebuild.HasRule {
 		return false, Value{}
 	}
+	if p.Name == "SHAMIR_JOIN" {
+		return possibleToRebuildShares(p, prim)
+	}
 	for _, g := range prim.Rebuild.Given {
 		has := []Value{}
 	ggLoop:
@@ -236,7 +500,7 @@ func possibleToRebuild(p Primitive) (bool, Value) {
 				continue ggLoop
 			}
 			switch p.Arguments[gg].Kind {
-			case "primitive":
+			case ValueKindPrimitive:
 				if p.Arguments[gg].Primitive.Name == prim.Rebuild.Name {
 					has = append(has, p.Arguments[gg])
 				}
@@ -260,19 +524,33 @@ func possibleToRebuild(p Primitive) (bool, Value) {
 	return false, Value{}
 }
 
+func possibleToRebuildShares(p Primitive, prim PrimitiveSpec) (bool, Value) {
+	shares := []Primitive{}
+	for _, a := range p.Arguments {
+		if a.Kind != ValueKindPrimitive || a.Primitive.Name != prim.Rebuild.Name {
+			return false, Value{}
+		}
+		shares = append(shares, a.Primitive)
+	}
+	if !possibleToCombineShares(shares) {
+		return false, Value{}
+	}
+	return true, shares[0].Arguments[prim.Rebuild.Reveal]
+}
+
 func possibleToObtainPasswords(
 	a Value, aParent Value, aIndex int, valPrincipalState PrincipalState,
 ) []Value {
 	passwords := []Value{}
 	switch a.Kind {
-	case "constant":
+	case ValueKindConstant:
 		aa := valueResolveConstant(a.Constant, valPrincipalState)
 		switch aa.Kind {
-		case "constant":
+		case ValueKindConstant:
 			if aa.Constant.Qualifier == "password" {
 				if aIndex >= 0 {
 					if !primitiveIsCorePrim(aParent.Primitive.Name) {
-						prim, _ := primitiveGet(aParent.Primitive.Name)
+						prim, _ := primitiveGet(aParent.Primitive)
 						if intInSlice(aIndex, prim.PasswordHashing) {
 							return passwords
 						}
@@ -281,10 +559,10 @@ func possibleToObtainPasswords(
 				passwords = append(passwords, aa)
 			}
 		}
-	case "primitive":
+	case ValueKindPrimitive:
 		for ii, aa := range a.Primitive.Arguments {
 			if !primitiveIsCorePrim(a.Primitive.Name) {
-				prim, _ := primitiveGet(a.Primitive.Name)
+				prim, _ := primitiveGet(a.Primitive)
 				if intInSlice(aIndex, prim.PasswordHashing) {
 					aParent = a
 				}
@@ -293,7 +571,7 @@ func possibleToObtainPasswords(
 				possibleToObtainPasswords(aa, aParent, ii, valPrincipalState)...,
 			)
 		}
-	case "equation":
+	case ValueKindEquation:
 		for _, aa := range a.Equation.Values {
 			passwords = append(passwords,
 				possibleToObtainPasswords(aa, a, -1, valPrincipalState)...,
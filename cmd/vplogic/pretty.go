@@ -54,15 +54,14 @@ func prettyPrimitive(p Primitive) string {
 	if p.Check {
 		check = "?"
 	}
-	for i, arg := range p.Arguments {
-		sep := ""
-		if i != (len(p.Arguments) - 1) {
-			sep = ", "
+	arguments := []string{}
+	for _, arg := range p.Arguments {
+		if arg.Kind == ValueKindConstant && valueConstantIsInternal(arg.Constant) {
+			continue
 		}
-		pretty = fmt.Sprintf("%s%s%s",
-			pretty, prettyValue(arg), sep,
-		)
+		arguments = append(arguments, prettyValue(arg))
 	}
+	pretty = pretty + strings.Join(arguments, ", ")
 	return fmt.Sprintf("%s)%s",
 		pretty, check,
 	)
@@ -85,11 +84,11 @@ func prettyEquation(e Equation) string {
 
 func prettyValue(a Value) string {
 	switch a.Kind {
-	case "constant":
+	case ValueKindConstant:
 		return prettyConstant(a.Constant)
-	case "primitive":
+	case ValueKindPrimitive:
 		return prettyPrimitive(a.Primitive)
-	case "equation":
+	case ValueKindEquation:
 		return prettyEquation(a.Equation)
 	}
 	return ""
@@ -113,13 +112,13 @@ func prettyValues(a []Value) string {
 func prettyQuery(query Query) string {
 	output := ""
 	switch query.Kind {
-	case "confidentiality":
+	case QueryKindConfidentiality:
 		output = fmt.Sprintf(
 			"%s? %s",
 			query.Kind,
 			prettyConstants(query.Constants),
 		)
-	case "authentication":
+	case QueryKindAuthentication:
 		output = fmt.Sprintf(
 			"%s? %s -> %s: %s",
 			query.Kind,
@@ -127,13 +126,13 @@ func prettyQuery(query Query) string {
 			query.Message.Recipient,
 			prettyConstants(query.Message.Constants),
 		)
-	case "freshness":
+	case QueryKindFreshness:
 		output = fmt.Sprintf(
 			"%s? %s",
 			query.Kind,
 			prettyConstants(query.Constants),
 		)
-	case "unlinkability":
+	case QueryKindUnlinkability:
 		output = fmt.Sprintf(
 			"%s? %s",
 			query.Kind,
@@ -159,6 +158,22 @@ func prettyQuery(query Query) string {
 	return output
 }
 
+func prettyAttacker(m Model) string {
+	switch m.Attacker {
+	case "bounded-depth":
+		return fmt.Sprintf("%s %d", m.Attacker, m.AttackerDepth)
+	case "per-channel":
+		channels := []string{}
+		for _, c := range m.AttackerChannels {
+			channels = append(channels, fmt.Sprintf(
+				"%s -> %s", c.Sender, c.Recipient,
+			))
+		}
+		return fmt.Sprintf("%s %s", m.Attacker, strings.Join(channels, ", "))
+	}
+	return m.Attacker
+}
+
 func prettyPrincipal(block Block) string {
 	output := fmt.Sprintf(
 		"principal %s[\n",
@@ -177,26 +192,26 @@ func prettyPrincipal(block Block) string {
 func prettyExpression(expression Expression) string {
 	output := ""
 	switch expression.Kind {
-	case "knows":
+	case ExpressionKindKnows:
 		output = fmt.Sprintf(
 			"%s %s %s",
 			expression.Kind,
 			expression.Qualifier,
 			prettyConstants(expression.Constants),
 		)
-	case "generates":
+	case ExpressionKindGenerates:
 		output = fmt.Sprintf(
 			"%s %s",
 			expression.Kind,
 			prettyConstants(expression.Constants),
 		)
-	case "leaks":
+	case ExpressionKindLeaks:
 		output = fmt.Sprintf(
 			"%s %s",
 			expression.Kind,
 			prettyConstants(expression.Constants),
 		)
-	case "assignment":
+	case ExpressionKindAssignment:
 		right := prettyValue(expression.Right)
 		left := []Constant{}
 		for i, c := range expression.Left {
@@ -214,11 +229,19 @@ func prettyExpression(expression Expression) string {
 	return output
 }
 
+func prettyMessageArrow(msg Message) string {
+	if len(msg.Channel) == 0 {
+		return "->"
+	}
+	return fmt.Sprintf("-(%s)->", msg.Channel)
+}
+
 func prettyMessage(block Block) string {
 	output := fmt.Sprintf(
-		"%s -> %s: %s\n\n",
+		"%s %s %s: %s\n\n",
 		block.Message.Sender,
-		block.Message.Recipient,
+		prettyMessageArrow(block.Message),
+		strings.Join(constructMessageRecipients(block.Message), ", "),
 		prettyConstants(block.Message.Constants),
 	)
 	return output
@@ -232,6 +255,40 @@ func prettyPhase(block Block) string {
 	return output
 }
 
+func prettyRepeat(r Repeat) string {
+	header := fmt.Sprintf("repeat %d", r.Count)
+	if r.Index != "i" {
+		header = fmt.Sprintf("repeat %s %d", r.Index, r.Count)
+	}
+	output := ""
+	for _, block := range r.Blocks {
+		for _, line := range strings.SplitAfter(prettyBlock(block), "\n") {
+			if len(strings.TrimSpace(line)) > 0 {
+				line = "\t" + line
+			}
+			output = output + line
+		}
+	}
+	return fmt.Sprintf(
+		"%s [\n%s\n]\n\n",
+		header, strings.TrimRight(output, "\n"),
+	)
+}
+
+func prettyBlock(block Block) string {
+	switch block.Kind {
+	case BlockKindPrincipal:
+		return prettyPrincipal(block)
+	case BlockKindMessage:
+		return prettyMessage(block)
+	case BlockKindPhase:
+		return prettyPhase(block)
+	case BlockKindRepeat:
+		return prettyRepeat(block.Repeat)
+	}
+	return ""
+}
+
 func PrettyModel(m Model) (string, error) {
 	_, _, err := sanity(m)
 	if err != nil {
@@ -239,17 +296,18 @@ func PrettyModel(m Model) (string, error) {
 	}
 	output := fmt.Sprintf(
 		"attacker[%s]\n\n",
-		m.Attacker,
+		prettyAttacker(m),
 	)
+	lastRepeat := 0
 	for _, block := range m.Blocks {
-		switch block.Kind {
-		case "principal":
-			output = output + prettyPrincipal(block)
-		case "message":
-			output = output + prettyMessage(block)
-		case "phase":
-			output = output + prettyPhase(block)
+		if block.Repeat.ID > 0 {
+			if block.Repeat.ID != lastRepeat {
+				output = output + prettyRepeat(block.Repeat)
+				lastRepeat = block.Repeat.ID
+			}
+			continue
 		}
+		output = output + prettyBlock(block)
 	}
 	output = fmt.Sprintf("%squeries[\n", output)
 	for _, query := range m.Queries {
@@ -270,7 +328,7 @@ func PrettyDiagram(m Model) (string, error) {
 	firstPrincipal := ""
 	for _, block := range m.Blocks {
 		switch block.Kind {
-		case "principal":
+		case BlockKindPrincipal:
 			output = fmt.Sprintf(
 				"%sNote over %s: ",
 				output, block.Principal.Name,
@@ -285,9 +343,19 @@ func PrettyDiagram(m Model) (string, error) {
 				)
 			}
 			output = fmt.Sprintf("%s\n", output)
-		case "message":
-			output = output + prettyMessage(block)
-		case "phase":
+		case BlockKindMessage:
+			arrow := "->"
+			if len(block.Message.Channel) > 0 {
+				arrow = "-->"
+			}
+			for _, recipient := range constructMessageRecipients(block.Message) {
+				output = fmt.Sprintf(
+					"%s%s %s %s: %s\n\n",
+					output, block.Message.Sender, arrow, recipient,
+					prettyConstants(block.Message.Constants),
+				)
+			}
+		case BlockKindPhase:
 			output = fmt.Sprintf(
 				"%sNote left of %s:phase %d\n",
 				output, firstPrincipal, block.Phase.Number,
@@ -299,6 +367,9 @@ func PrettyDiagram(m Model) (string, error) {
 
 func prettyArity(specArity []int) string {
 	arityString := ""
+	if len(specArity) > 5 && specArity[len(specArity)-1]-specArity[0] == len(specArity)-1 {
+		return fmt.Sprintf("between %d and %d", specArity[0], specArity[len(specArity)-1])
+	}
 	if len(specArity) == 1 {
 		arityString = fmt.Sprintf("%d", specArity[0])
 	} else {
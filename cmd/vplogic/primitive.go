@@ -6,8 +6,14 @@ package vplogic
 
 import (
 	"fmt"
+	"strconv"
 )
 
+// primitiveMaxArity bounds the number of arguments taken by primitives
+// whose arity depends on the model, such as the number of shares passed to
+// SHAMIR_JOIN or the number of public keys in a ring signature.
+const primitiveMaxArity = 10
+
 var primitiveCoreSpecs = []PrimitiveCoreSpec{
 	{
 		Name:    "ASSERT",
@@ -15,7 +21,7 @@ var primitiveCoreSpecs = []PrimitiveCoreSpec{
 		Output:  1,
 		HasRule: true,
 		CoreRule: func(p Primitive) (bool, []Value) {
-			v := []Value{{Kind: "primitive", Primitive: p}}
+			v := []Value{{Kind: ValueKindPrimitive, Primitive: p}}
 			if valueEquivalentValues(p.Arguments[0], p.Arguments[1], true) {
 				return true, v
 			}
@@ -31,7 +37,7 @@ var primitiveCoreSpecs = []PrimitiveCoreSpec{
 		Output:  1,
 		HasRule: false,
 		CoreRule: func(p Primitive) (bool, []Value) {
-			v := []Value{{Kind: "primitive", Primitive: p}}
+			v := []Value{{Kind: ValueKindPrimitive, Primitive: p}}
 			return false, v
 		},
 		Check:      false,
@@ -44,18 +50,18 @@ var primitiveCoreSpecs = []PrimitiveCoreSpec{
 		Output:  -1,
 		HasRule: true,
 		CoreRule: func(p Primitive) (bool, []Value) {
-			v := []Value{{Kind: "primitive", Primitive: p}}
+			v := []Value{{Kind: ValueKindPrimitive, Primitive: p}}
 			switch p.Arguments[0].Kind {
-			case "constant":
+			case ValueKindConstant:
 				return false, v
-			case "primitive":
+			case ValueKindPrimitive:
 				pp := p.Arguments[0].Primitive
 				switch pp.Name {
 				case "CONCAT":
 					return true, pp.Arguments
 				}
 				return false, v
-			case "equation":
+			case ValueKindEquation:
 				return false, v
 			}
 			return false, v
@@ -334,11 +340,11 @@ var primitiveSpecs = []PrimitiveSpec{
 				switch i {
 				case 0:
 					switch x.Kind {
-					case "constant":
+					case ValueKindConstant:
 						return x, false
-					case "primitive":
+					case ValueKindPrimitive:
 						return x, false
-					case "equation":
+					case ValueKindEquation:
 						switch len(x.Equation.Values) {
 						case 2:
 							return x.Equation.Values[1], true
@@ -372,11 +378,11 @@ var primitiveSpecs = []PrimitiveSpec{
 				switch i {
 				case 0:
 					switch x.Kind {
-					case "constant":
+					case ValueKindConstant:
 						return x, false
-					case "primitive":
+					case ValueKindPrimitive:
 						return x, false
-					case "equation":
+					case ValueKindEquation:
 						if len(x.Equation.Values) == 2 {
 							return x.Equation.Values[1], true
 						}
@@ -431,14 +437,14 @@ var primitiveSpecs = []PrimitiveSpec{
 				switch i {
 				case 0:
 					switch x.Kind {
-					case "constant", "primitive":
+					case ValueKindConstant, ValueKindPrimitive:
 						return Value{
-							Kind: "equation",
+							Kind: ValueKindEquation,
 							Equation: Equation{
 								Values: []Value{valueG, x},
 							},
 						}, true
-					case "equation":
+					case ValueKindEquation:
 						return x, false
 					}
 				}
@@ -455,19 +461,15 @@ var primitiveSpecs = []PrimitiveSpec{
 	},
 	{
 		Name:   "SHAMIR_SPLIT",
-		Arity:  []int{1},
-		Output: 3,
+		Arity:  []int{1, 2},
+		Output: -1,
 		Decompose: DecomposeRule{
 			HasRule: false,
 		},
 		Recompose: RecomposeRule{
 			HasRule: true,
-			Given: [][]int{
-				{0, 1},
-				{0, 2},
-				{1, 2},
-			},
-			Reveal: 0,
+			Given:   [][]int{},
+			Reveal:  0,
 			Filter: func(p Primitive, x Value, i int) (Value, bool) {
 				return x, true
 			},
@@ -485,7 +487,7 @@ var primitiveSpecs = []PrimitiveSpec{
 	},
 	{
 		Name:   "SHAMIR_JOIN",
-		Arity:  []int{2},
+		Arity:  primitiveArityRange(2, primitiveMaxArity),
 		Output: 1,
 		Decompose: DecomposeRule{
 			HasRule: false,
@@ -499,15 +501,8 @@ var primitiveSpecs = []PrimitiveSpec{
 		Rebuild: RebuildRule{
 			HasRule: true,
 			Name:    "SHAMIR_SPLIT",
-			Given: [][]int{
-				{0, 1},
-				{1, 0},
-				{0, 2},
-				{2, 0},
-				{1, 2},
-				{2, 1},
-			},
-			Reveal: 0,
+			Given:   [][]int{},
+			Reveal:  0,
 			Filter: func(p Primitive, x Value, i int) (Value, bool) {
 				return x, true
 			},
@@ -519,7 +514,7 @@ var primitiveSpecs = []PrimitiveSpec{
 	},
 	{
 		Name:   "RINGSIGN",
-		Arity:  []int{4},
+		Arity:  primitiveArityRange(3, primitiveMaxArity-1),
 		Output: 1,
 		Decompose: DecomposeRule{
 			HasRule: false,
@@ -540,7 +535,7 @@ var primitiveSpecs = []PrimitiveSpec{
 	},
 	{
 		Name:   "RINGSIGNVERIF",
-		Arity:  []int{5},
+		Arity:  primitiveArityRange(4, primitiveMaxArity),
 		Output: 1,
 		Decompose: DecomposeRule{
 			HasRule: false,
@@ -551,42 +546,64 @@ var primitiveSpecs = []PrimitiveSpec{
 		Rewrite: RewriteRule{
 			HasRule: true,
 			Name:    "RINGSIGN",
-			From:    4,
+			From:    -1,
 			To: func(p Primitive) Value {
 				return valueN
 			},
-			Matching: map[int][]int{
-				0: {0, 1, 2},
-				1: {0, 1, 2},
-				2: {0, 1, 2},
-				3: {3},
+			Matching: map[int][]int{},
+			Filter: func(p Primitive, x Value, i int) (Value, bool) {
+				return x, true
 			},
+		},
+		Rebuild: RebuildRule{
+			HasRule: false,
+		},
+		Check:           true,
+		Injectable:      false,
+		Explosive:       false,
+		PasswordHashing: []int{},
+	},
+	{
+		Name:   "THRESHOLD_SIGN",
+		Arity:  []int{2},
+		Output: 1,
+		Decompose: DecomposeRule{
+			HasRule: false,
+		},
+		Recompose: RecomposeRule{
+			HasRule: false,
+		},
+		Rewrite: RewriteRule{
+			HasRule: false,
+		},
+		Rebuild: RebuildRule{
+			HasRule: false,
+		},
+		Check:           false,
+		Injectable:      true,
+		Explosive:       false,
+		PasswordHashing: []int{},
+	},
+	{
+		Name:   "THRESHOLD_VERIF",
+		Arity:  primitiveArityRange(3, primitiveMaxArity),
+		Output: 1,
+		Decompose: DecomposeRule{
+			HasRule: false,
+		},
+		Recompose: RecomposeRule{
+			HasRule: false,
+		},
+		Rewrite: RewriteRule{
+			HasRule: true,
+			Name:    "THRESHOLD_SIGN",
+			From:    2,
+			To: func(p Primitive) Value {
+				return valueN
+			},
+			Matching: map[int][]int{},
 			Filter: func(p Primitive, x Value, i int) (Value, bool) {
-				switch i {
-				case 0:
-					switch x.Kind {
-					case "constant":
-						return x, false
-					case "primitive":
-						return x, false
-					case "equation":
-						switch len(x.Equation.Values) {
-						case 2:
-							return x.Equation.Values[1], true
-						default:
-							return x, false
-						}
-					}
-				case 1:
-					return x, true
-				case 2:
-					return x, true
-				case 3:
-					return x, true
-				case 4:
-					return x, true
-				}
-				return x, false
+				return x, true
 			},
 		},
 		Rebuild: RebuildRule{
@@ -639,9 +656,10 @@ var primitiveSpecs = []PrimitiveSpec{
 			From:    2,
 			To: func(p Primitive) Value {
 				return Value{
-					Kind: "primitive",
+					Kind: ValueKindPrimitive,
 					Primitive: Primitive{
 						Name: "SIGN",
+						ID:   primitiveIDSign,
 						Arguments: []Value{
 							p.Arguments[0],
 							p.Arguments[1].Primitive.Arguments[1],
@@ -658,9 +676,10 @@ var primitiveSpecs = []PrimitiveSpec{
 				switch i {
 				case 1:
 					blindPrim := Value{
-						Kind: "primitive",
+						Kind: ValueKindPrimitive,
 						Primitive: Primitive{
 							Name: "BLIND",
+							ID:   primitiveIDBlind,
 							Arguments: []Value{
 								p.Arguments[0], p.Arguments[1],
 							},
@@ -681,6 +700,348 @@ var primitiveSpecs = []PrimitiveSpec{
 		Explosive:       false,
 		PasswordHashing: []int{},
 	},
+	{
+		Name:   "KEM_ENCAPS",
+		Arity:  []int{1, 2},
+		Output: 2,
+		Decompose: DecomposeRule{
+			HasRule: true,
+			Given:   []int{0},
+			Reveal:  0,
+			To: func(p Primitive) Value {
+				return Value{
+					Kind: ValueKindPrimitive,
+					Primitive: Primitive{
+						Name:      p.Name,
+						ID:        p.ID,
+						Arguments: p.Arguments,
+						Output:    1,
+						Check:     false,
+					},
+				}
+			},
+			Filter: func(p Primitive, x Value, i int) (Value, bool) {
+				switch i {
+				case 0:
+					if p.Output != 0 {
+						return x, false
+					}
+					switch x.Kind {
+					case ValueKindConstant:
+						return x, false
+					case ValueKindPrimitive:
+						return x, false
+					case ValueKindEquation:
+						if len(x.Equation.Values) == 2 {
+							return x.Equation.Values[1], true
+						}
+						return x, false
+					}
+				}
+				return x, false
+			},
+		},
+		Recompose: RecomposeRule{
+			HasRule: false,
+		},
+		Rewrite: RewriteRule{
+			HasRule: false,
+		},
+		Rebuild: RebuildRule{
+			HasRule: false,
+		},
+		Check:           false,
+		Injectable:      true,
+		Explosive:       false,
+		PasswordHashing: []int{},
+	},
+	{
+		Name:   "KEM_DECAPS",
+		Arity:  []int{2},
+		Output: 1,
+		Decompose: DecomposeRule{
+			HasRule: false,
+		},
+		Recompose: RecomposeRule{
+			HasRule: false,
+		},
+		Rewrite: RewriteRule{
+			HasRule: true,
+			Name:    "KEM_ENCAPS",
+			From:    1,
+			To: func(p Primitive) Value {
+				return Value{
+					Kind: ValueKindPrimitive,
+					Primitive: Primitive{
+						Name:      p.Name,
+						ID:        p.ID,
+						Arguments: p.Arguments,
+						Output:    1,
+						Check:     false,
+					},
+				}
+			},
+			Matching: map[int][]int{
+				0: {0},
+			},
+			Filter: func(p Primitive, x Value, i int) (Value, bool) {
+				switch i {
+				case 0:
+					if p.Arguments[1].Primitive.Output != 0 {
+						return x, false
+					}
+					switch x.Kind {
+					case ValueKindConstant, ValueKindPrimitive:
+						return Value{
+							Kind: ValueKindEquation,
+							Equation: Equation{
+								Values: []Value{valueG, x},
+							},
+						}, true
+					case ValueKindEquation:
+						return x, false
+					}
+				}
+				return x, false
+			},
+		},
+		Rebuild: RebuildRule{
+			HasRule: false,
+		},
+		Check:           false,
+		Injectable:      false,
+		Explosive:       false,
+		PasswordHashing: []int{},
+	},
+	{
+		Name:   "COMMIT",
+		Arity:  []int{2},
+		Output: 1,
+		Decompose: DecomposeRule{
+			HasRule: true,
+			Given:   []int{1},
+			Reveal:  0,
+			Filter: func(p Primitive, x Value, i int) (Value, bool) {
+				return x, true
+			},
+		},
+		Recompose: RecomposeRule{
+			HasRule: false,
+		},
+		Rewrite: RewriteRule{
+			HasRule: false,
+		},
+		Rebuild: RebuildRule{
+			HasRule: false,
+		},
+		Check:           false,
+		Injectable:      true,
+		Explosive:       false,
+		PasswordHashing: []int{},
+	},
+	{
+		Name:   "OPEN",
+		Arity:  []int{3},
+		Output: 1,
+		Decompose: DecomposeRule{
+			HasRule: false,
+		},
+		Recompose: RecomposeRule{
+			HasRule: false,
+		},
+		Rewrite: RewriteRule{
+			HasRule: true,
+			Name:    "COMMIT",
+			From:    0,
+			To: func(p Primitive) Value {
+				return p.Arguments[0]
+			},
+			Matching: map[int][]int{
+				1: {0},
+				2: {1},
+			},
+			Filter: func(p Primitive, x Value, i int) (Value, bool) {
+				switch i {
+				case 0:
+					return x, true
+				case 1:
+					return x, true
+				}
+				return x, false
+			},
+		},
+		Rebuild: RebuildRule{
+			HasRule: false,
+		},
+		Check:           true,
+		Injectable:      false,
+		Explosive:       false,
+		PasswordHashing: []int{},
+	},
+	{
+		Name:   "ZKP_PROVE",
+		Arity:  []int{2},
+		Output: 1,
+		Decompose: DecomposeRule{
+			HasRule: false,
+		},
+		Recompose: RecomposeRule{
+			HasRule: false,
+		},
+		Rewrite: RewriteRule{
+			HasRule: false,
+		},
+		Rebuild: RebuildRule{
+			HasRule: false,
+		},
+		Check:           false,
+		Injectable:      true,
+		Explosive:       false,
+		PasswordHashing: []int{},
+	},
+	{
+		Name:   "ZKP_VERIFY",
+		Arity:  []int{2},
+		Output: 1,
+		Decompose: DecomposeRule{
+			HasRule: false,
+		},
+		Recompose: RecomposeRule{
+			HasRule: false,
+		},
+		Rewrite: RewriteRule{
+			HasRule: true,
+			Name:    "ZKP_PROVE",
+			From:    0,
+			To: func(p Primitive) Value {
+				return valueN
+			},
+			Matching: map[int][]int{
+				1: {1},
+			},
+			Filter: func(p Primitive, x Value, i int) (Value, bool) {
+				switch i {
+				case 1:
+					witness := p.Arguments[0].Primitive.Arguments[0]
+					switch witness.Kind {
+					case ValueKindConstant:
+						if witness.Constant.Name == "g" {
+							return x, false
+						}
+					}
+					return x, primitiveZKPStatement(x, witness)
+				}
+				return x, false
+			},
+		},
+		Rebuild: RebuildRule{
+			HasRule: false,
+		},
+		Check:           true,
+		Injectable:      false,
+		Explosive:       false,
+		PasswordHashing: []int{},
+	},
+	{
+		Name:   "XOR",
+		Arity:  []int{2},
+		Output: 1,
+		Decompose: DecomposeRule{
+			HasRule: true,
+			Given:   []int{0},
+			Reveal:  1,
+			Filter: func(p Primitive, x Value, i int) (Value, bool) {
+				return x, true
+			},
+		},
+		Recompose: RecomposeRule{
+			HasRule: true,
+			Given: [][]int{
+				{0, 1},
+			},
+			Reveal: 0,
+			Filter: func(p Primitive, x Value, i int) (Value, bool) {
+				return x, true
+			},
+		},
+		Rewrite: RewriteRule{
+			HasRule: false,
+		},
+		Rebuild: RebuildRule{
+			HasRule: false,
+		},
+		Check:           false,
+		Injectable:      true,
+		Explosive:       false,
+		PasswordHashing: []int{},
+	},
+	{
+		Name:   "STREAM_ENC",
+		Arity:  []int{2},
+		Output: 1,
+		Decompose: DecomposeRule{
+			HasRule: true,
+			Given:   []int{0},
+			Reveal:  1,
+			Filter: func(p Primitive, x Value, i int) (Value, bool) {
+				return x, true
+			},
+		},
+		Recompose: RecomposeRule{
+			HasRule: false,
+		},
+		Rewrite: RewriteRule{
+			HasRule: false,
+		},
+		Rebuild: RebuildRule{
+			HasRule: false,
+		},
+		Check:           false,
+		Injectable:      true,
+		Explosive:       false,
+		PasswordHashing: []int{1},
+	},
+	{
+		Name:   "STREAM_DEC",
+		Arity:  []int{2},
+		Output: 1,
+		Decompose: DecomposeRule{
+			HasRule: true,
+			Given:   []int{0},
+			Reveal:  1,
+			Filter: func(p Primitive, x Value, i int) (Value, bool) {
+				return x, true
+			},
+		},
+		Recompose: RecomposeRule{
+			HasRule: false,
+		},
+		Rewrite: RewriteRule{
+			HasRule: true,
+			Name:    "STREAM_ENC",
+			From:    1,
+			To: func(p Primitive) Value {
+				return p.Arguments[1]
+			},
+			Matching: map[int][]int{
+				0: {0},
+			},
+			Filter: func(p Primitive, x Value, i int) (Value, bool) {
+				switch i {
+				case 0:
+					return x, true
+				}
+				return x, false
+			},
+		},
+		Rebuild: RebuildRule{
+			HasRule: false,
+		},
+		Check:           false,
+		Injectable:      false,
+		Explosive:       false,
+		PasswordHashing: []int{},
+	},
 }
 
 func primitiveIsCorePrim(name string) bool {
@@ -691,35 +1052,108 @@ func primitiveIsCorePrim(name string) bool {
 	return false
 }
 
-func primitiveCoreGet(name string) (PrimitiveCoreSpec, error) {
-	for _, v := range primitiveCoreSpecs {
-		if v.Name == name {
-			return v, nil
-		}
+// primitiveCoreIDs and primitiveIDs map the name of each primitive to its
+// ID, which is its index within primitiveCoreSpecs or primitiveSpecs. They
+// are only used to give each primitive its ID as it is parsed, after which
+// its specification is found using the ID alone.
+var primitiveCoreIDs = map[string]int{}
+var primitiveIDs = map[string]int{}
+
+// primitiveIDSign, primitiveIDBlind and primitiveIDXor are the IDs of the
+// primitives that rewrite rules and injection construct themselves.
+var primitiveIDSign, primitiveIDBlind, primitiveIDXor int
+
+func init() {
+	for i, v := range primitiveCoreSpecs {
+		primitiveCoreIDs[v.Name] = i
+	}
+	for i, v := range primitiveSpecs {
+		primitiveIDs[v.Name] = i
+	}
+	primitiveIDSign = PrimitiveID("SIGN")
+	primitiveIDBlind = PrimitiveID("BLIND")
+	primitiveIDXor = PrimitiveID("XOR")
+}
+
+// PrimitiveID returns the ID of the primitive with the given name, or -1
+// if there is no such primitive. Primitives are given their ID as they are
+// parsed, and models that are built without being parsed must do the same.
+func PrimitiveID(name string) int {
+	ids := primitiveIDs
+	if primitiveIsCorePrim(name) {
+		ids = primitiveCoreIDs
+	}
+	if id, ok := ids[name]; ok {
+		return id
+	}
+	return -1
+}
+
+func primitiveCoreGet(p Primitive) (PrimitiveCoreSpec, error) {
+	if p.ID >= 0 && p.ID < len(primitiveCoreSpecs) && primitiveCoreSpecs[p.ID].Name == p.Name {
+		return primitiveCoreSpecs[p.ID], nil
 	}
-	err := fmt.Errorf("unknown primitive (%s)", name)
+	err := fmt.Errorf("unknown primitive (%s)", p.Name)
 	return PrimitiveCoreSpec{}, err
 }
 
-func primitiveGet(name string) (PrimitiveSpec, error) {
-	for _, v := range primitiveSpecs {
-		if v.Name == name {
-			return v, nil
-		}
+func primitiveGet(p Primitive) (PrimitiveSpec, error) {
+	if p.ID >= 0 && p.ID < len(primitiveSpecs) && primitiveSpecs[p.ID].Name == p.Name {
+		return primitiveSpecs[p.ID], nil
 	}
-	err := fmt.Errorf("unknown primitive (%s)", name)
+	err := fmt.Errorf("unknown primitive (%s)", p.Name)
 	return PrimitiveSpec{}, err
 }
 
+func primitiveArityRange(min int, max int) []int {
+	arity := []int{}
+	for i := min; i <= max; i++ {
+		arity = append(arity, i)
+	}
+	return arity
+}
+
+// primitiveShamirThreshold returns how many shares of a SHAMIR_SPLIT are
+// needed to recover its secret, which is given as an optional number
+// literal and defaults to two.
+func primitiveShamirThreshold(p Primitive) int {
+	if len(p.Arguments) < 2 || p.Arguments[1].Kind != ValueKindConstant {
+		return 2
+	}
+	t, err := strconv.Atoi(p.Arguments[1].Constant.Name)
+	if err != nil {
+		return 2
+	}
+	return t
+}
+
+// primitiveZKPStatement reports whether a statement is one that ZKP_VERIFY
+// accepts a proof of knowing the witness for: either G^witness or
+// HASH(witness), matching the reductions of the ProVerif translation.
+func primitiveZKPStatement(statement Value, witness Value) bool {
+	switch statement.Kind {
+	case ValueKindEquation:
+		values := statement.Equation.Values
+		return len(values) == 2 &&
+			valueEquivalentValues(values[0], valueG, true) &&
+			valueEquivalentValues(values[1], witness, true)
+	case ValueKindPrimitive:
+		return statement.Primitive.Name == "HASH" &&
+			len(statement.Primitive.Arguments) == 1 &&
+			valueEquivalentValues(statement.Primitive.Arguments[0], witness, true)
+	}
+	return false
+}
+
 func primitiveGetArity(p Primitive) ([]int, error) {
 	if primitiveIsCorePrim(p.Name) {
-		prim, err := primitiveCoreGet(p.Name)
+		prim, err := primitiveCoreGet(p)
 		if err != nil {
 			return []int{}, err
 		}
 		return prim.Arity, nil
 	}
-	prim, err := primitiveGet(p.Name)
+	prim, err := primitiveGet(p)
 	if err != nil {
 		return []int{}, err
 	}
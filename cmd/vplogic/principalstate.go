@@ -0,0 +1,61 @@
+/* SPDX-FileCopyrightText: © 2019-2020 Nadim Kobeissi <nadim@symbolic.software>
+ * SPDX-License-Identifier: GPL-3.0-only */
+// 00000000000000000000000000000000
+
+package vplogic
+
+import (
+	"hash/fnv"
+)
+
+// principalStateHash hashes what an analysis of valPrincipalState depends
+// on, such that two states with the same hash would lead Attacker to the same
+// conclusions.
+func principalStateHash(valPrincipalState PrincipalState) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(valPrincipalState.Name))
+	valueHashWrite(h,
+		uint64(valPrincipalState.AttackerKnown),
+		uint64(len(valPrincipalState.Constants)),
+	)
+	for i := range valPrincipalState.Constants {
+		flags := []byte{0, 0}
+		if valPrincipalState.Mutated[i] {
+			flags[0] = 1
+		}
+		if valPrincipalState.Rewritten[i] {
+			flags[1] = 1
+		}
+		h.Write(flags)
+		h.Write([]byte(valPrincipalState.Sender[i]))
+		valueHashWrite(h,
+			valueHash(valPrincipalState.Assigned[i]),
+			valueHash(valPrincipalState.BeforeRewrite[i]),
+		)
+	}
+	return h.Sum64()
+}
+
+// principalStateEquivalent compares everything that principalStateHash
+// hashes, telling us whether an analysis of a would lead Attacker to the same
+// conclusions as an analysis of b.
+func principalStateEquivalent(a PrincipalState, b PrincipalState) bool {
+	if a.Name != b.Name || a.AttackerKnown != b.AttackerKnown ||
+		len(a.Constants) != len(b.Constants) {
+		return false
+	}
+	for i := range a.Constants {
+		switch {
+		case a.Constants[i].Name != b.Constants[i].Name,
+			a.Mutated[i] != b.Mutated[i],
+			a.Rewritten[i] != b.Rewritten[i],
+			a.Sender[i] != b.Sender[i]:
+			return false
+		}
+		if !valueEquivalentValues(a.Assigned[i], b.Assigned[i], true) ||
+			!valueEquivalentValues(a.BeforeRewrite[i], b.BeforeRewrite[i], true) {
+			return false
+		}
+	}
+	return true
+}
@@ -47,6 +47,12 @@ func pvConstantPrefix(valKnowledgeMap KnowledgeMap, principal string, c Constant
 }
 
 func pvConstant(valKnowledgeMap KnowledgeMap, principal string, c Constant, valType string) string {
+	if valueConstantIsLiteral(c) {
+		return pvLiteral(c)
+	}
+	if valueConstantIsInternal(c) {
+		return fmt.Sprintf("internal_%s", c.Name[1:])
+	}
 	prefix := pvConstantPrefix(valKnowledgeMap, principal, c)
 	t := ""
 	if len(valType) > 0 {
@@ -55,6 +61,30 @@ func pvConstant(valKnowledgeMap KnowledgeMap, principal string, c Constant, valT
 	return fmt.Sprintf("%s_%s%s", prefix, c.Name, t)
 }
 
+// pvLiteral names the ProVerif constant standing for a literal, keeping
+// letters and digits and escaping every other byte so that distinct
+// literals always map to distinct names.
+func pvLiteral(c Constant) string {
+	prefix := "num"
+	name := c.Name
+	if strings.HasPrefix(name, "\"") {
+		prefix = "str"
+		name = name[1 : len(name)-1]
+	}
+	escaped := ""
+	for _, b := range []byte(name) {
+		switch {
+		case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z', b >= '0' && b <= '9':
+			escaped = escaped + string(b)
+		case b == '_':
+			escaped = escaped + "__"
+		default:
+			escaped = fmt.Sprintf("%s_%02x", escaped, b)
+		}
+	}
+	return fmt.Sprintf("literal_%s_%s", prefix, escaped)
+}
+
 func pvConstants(valKnowledgeMap KnowledgeMap, principal string, c []Constant, valType string) string {
 	consts := ""
 	for i, v := range c {
@@ -75,13 +105,15 @@ func pvPrimitive(valKnowledgeMap KnowledgeMap, principal string, p Primitive, ch
 	switch check {
 	case true:
 		switch p.Name {
-		case "AEAD_DEC":
+		case "AEAD_DEC", "OPEN":
 			checksuffix = "_check"
 		}
 	}
 	switch p.Name {
 	case "HASH", "CONCAT":
 		pname = fmt.Sprintf("%s%d", p.Name, len(p.Arguments))
+	case "KEM_ENCAPS":
+		pname = "KEM_ENCAPS_R"
 	}
 	prim := fmt.Sprintf("%s%s(", pname, checksuffix)
 	for i, arg := range p.Arguments {
@@ -105,23 +137,25 @@ func pvEquation(valKnowledgeMap KnowledgeMap, principal string, e Equation) stri
 			"G(%s)",
 			pvValue(valKnowledgeMap, principal, e.Values[0]),
 		)
-	case 2:
-		eq = fmt.Sprintf(
-			"exp(%s, %s)",
-			pvValue(valKnowledgeMap, principal, e.Values[1]),
-			pvValue(valKnowledgeMap, principal, e.Values[0]),
-		)
+	default:
+		eq = pvValue(valKnowledgeMap, principal, e.Values[0])
+		for _, v := range e.Values[1:] {
+			eq = fmt.Sprintf(
+				"exp(%s, %s)",
+				pvValue(valKnowledgeMap, principal, v), eq,
+			)
+		}
 	}
 	return eq
 }
 
 func pvValue(valKnowledgeMap KnowledgeMap, principal string, a Value) string {
 	switch a.Kind {
-	case "constant":
+	case ValueKindConstant:
 		return pvConstant(valKnowledgeMap, principal, a.Constant, "")
-	case "primitive":
+	case ValueKindPrimitive:
 		return pvPrimitive(valKnowledgeMap, principal, a.Primitive, false)
-	case "equation":
+	case ValueKindEquation:
 		return pvEquation(valKnowledgeMap, principal, a.Equation)
 	}
 	return ""
@@ -130,11 +164,14 @@ func pvValue(valKnowledgeMap KnowledgeMap, principal string, a Value) string {
 func pvQuery(valKnowledgeMap KnowledgeMap, query Query) (string, error) {
 	output := ""
 	switch query.Kind {
-	case "confidentiality":
+	case QueryKindConfidentiality:
 		i := valueGetKnowledgeMapIndexFromConstant(valKnowledgeMap, query.Constants[0])
 		resolved, _ := valueResolveValueInternalValuesFromKnowledgeMap(valKnowledgeMap.Assigned[i], valKnowledgeMap)
-		output = fmt.Sprintf("query attacker(%s).", pvValue(valKnowledgeMap, "attacker", resolved))
-	case "authentication":
+		output = fmt.Sprintf(
+			"query attacker(%s).",
+			pvValue(valKnowledgeMap, "attacker", pvQueryEncapsulations(resolved)),
+		)
+	case QueryKindAuthentication:
 		output = fmt.Sprintf("%s ==> %s.",
 			fmt.Sprintf("query event(RecvMsg(principal_%s, principal_%s, phase_%d, %s))",
 				query.Message.Sender, query.Message.Recipient, 0,
@@ -145,9 +182,9 @@ func pvQuery(valKnowledgeMap KnowledgeMap, query Query) (string, error) {
 				pvConstant(valKnowledgeMap, "attacker", query.Message.Constants[0], ""),
 			),
 		)
-	case "freshness":
+	case QueryKindFreshness:
 		return "", fmt.Errorf("freshness queries are not yet supported in ProVerif model generation")
-	case "unlinkability":
+	case QueryKindUnlinkability:
 		return "", fmt.Errorf("unlinkability queries are not yet supported in ProVerif model generation")
 	}
 	if len(query.Options) > 0 {
@@ -156,6 +193,31 @@ func pvQuery(valKnowledgeMap KnowledgeMap, query Query) (string, error) {
 	return output, nil
 }
 
+// pvQueryEncapsulations replaces every KEM_ENCAPS within a value by the
+// ciphertext or shared secret that it outputs, since the letfun computing
+// both at once cannot be used within a query.
+func pvQueryEncapsulations(a Value) Value {
+	switch a.Kind {
+	case ValueKindPrimitive:
+		p := a.Primitive
+		p.Arguments = make([]Value, len(a.Primitive.Arguments))
+		for i, aa := range a.Primitive.Arguments {
+			p.Arguments[i] = pvQueryEncapsulations(aa)
+		}
+		if p.Name == "KEM_ENCAPS" {
+			p.Name = []string{"kem_ct", "kem_ss"}[p.Output]
+		}
+		a.Primitive = p
+	case ValueKindEquation:
+		values := make([]Value, len(a.Equation.Values))
+		for i, aa := range a.Equation.Values {
+			values[i] = pvQueryEncapsulations(aa)
+		}
+		a.Equation = Equation{Values: values}
+	}
+	return a
+}
+
 func pvPrincipal(
 	valKnowledgeMap KnowledgeMap, block Block,
 	procs string, consts string, pc int, cc int,
@@ -166,14 +228,14 @@ func pvPrincipal(
 	)
 	for _, expression := range block.Principal.Expressions {
 		switch expression.Kind {
-		case "leaks":
+		case ExpressionKindLeaks:
 			for _, c := range expression.Constants {
 				procs = fmt.Sprintf(
 					"%s\tout(pub, (%s));\n",
 					procs, pvConstant(valKnowledgeMap, block.Principal.Name, c, ""),
 				)
 			}
-		case "assignment":
+		case ExpressionKindAssignment:
 			c := valueGetConstantsFromValue(expression.Right)
 			get := ""
 			for _, cc := range c {
@@ -198,11 +260,16 @@ func pvPrincipal(
 					procs, get,
 				)
 			}
+			if expression.Right.Kind == ValueKindPrimitive &&
+				expression.Right.Primitive.Name == "KEM_ENCAPS" &&
+				len(expression.Right.Primitive.Arguments) == 1 {
+				expression.Right = pvEncapsulation(expression)
+			}
 			valType := "bitstring"
 			switch expression.Right.Kind {
-			case "primitive":
+			case ValueKindPrimitive:
 				switch expression.Right.Primitive.Name {
-				case "SIGNVERIF", "RINGSIGNVERIF":
+				case "SIGNVERIF", "RINGSIGNVERIF", "ZKP_VERIFY":
 					valType = "bool"
 				}
 				switch expression.Right.Primitive.Check {
@@ -238,6 +305,38 @@ func pvPrincipal(
 	return procs, consts, pc, cc
 }
 
+// pvEncapsulation gives a KEM_ENCAPS without randomness of its own the
+// randomness that the knowledge map declares for it, so that queries on its
+// outputs can name them.
+func pvEncapsulation(expression Expression) Value {
+	p := expression.Right.Primitive
+	p.Arguments = append(append([]Value{}, p.Arguments...), Value{
+		Kind: ValueKindConstant,
+		Constant: Constant{
+			Name: fmt.Sprintf("#encaps_%s", expression.Left[0].Name),
+		},
+	})
+	return Value{Kind: ValueKindPrimitive, Primitive: p}
+}
+
+// pvMessageChannel reports how a message constant travels between
+// processes: "guarded" constants are published and delivered over a
+// private channel, "private" constants are only delivered over a private
+// channel, "confidential" constants are delivered over a private channel
+// that Attacker may also write to, and "public" constants go over the
+// attacker-controlled channel.
+func pvMessageChannel(msg Message, c Constant) string {
+	switch {
+	case constructMessageChannelConfidential(msg) && constructMessageChannelAuthenticated(msg):
+		return "private"
+	case constructMessageChannelConfidential(msg):
+		return "confidential"
+	case c.Guard, constructMessageChannelAuthenticated(msg):
+		return "guarded"
+	}
+	return "public"
+}
+
 func pvMessage(
 	valKnowledgeMap KnowledgeMap, block Block,
 	procs string, pc int,
@@ -262,18 +361,27 @@ func pvMessage(
 		)
 	}
 	for _, c := range block.Message.Constants {
-		switch c.Guard {
-		case true:
+		channel := pvMessageChannel(block.Message, c)
+		if channel == "guarded" {
 			procs = fmt.Sprintf(
 				"%s\tout(pub, %s);\n",
 				procs, pvConstant(valKnowledgeMap, block.Message.Sender, c, ""),
 			)
+		}
+		switch channel {
+		case "guarded", "private":
 			procs = fmt.Sprintf(
 				"%s\tout(chan_%s_to_%s_private, (%s));\n",
 				procs, block.Message.Sender, block.Message.Recipient,
 				pvConstant(valKnowledgeMap, block.Message.Sender, c, ""),
 			)
-		case false:
+		case "confidential":
+			procs = fmt.Sprintf(
+				"%s\tout(chan_%s_to_%s_conf, (%s));\n",
+				procs, block.Message.Sender, block.Message.Recipient,
+				pvConstant(valKnowledgeMap, block.Message.Sender, c, ""),
+			)
+		case "public":
 			procs = fmt.Sprintf(
 				"%s\tout(chan_%s_to_%s, (%s));\n",
 				procs, block.Message.Sender, block.Message.Recipient,
@@ -288,14 +396,20 @@ func pvMessage(
 		procs, block.Message.Recipient, block.Message.Sender, pc,
 	)
 	for _, c := range block.Message.Constants {
-		switch c.Guard {
-		case true:
+		switch pvMessageChannel(block.Message, c) {
+		case "guarded", "private":
 			procs = fmt.Sprintf(
 				"%s\tin(chan_%s_to_%s_private, (%s));\n",
 				procs, block.Message.Sender, block.Message.Recipient,
 				pvConstant(valKnowledgeMap, block.Message.Sender, c, "bitstring"),
 			)
-		case false:
+		case "confidential":
+			procs = fmt.Sprintf(
+				"%s\tin(chan_%s_to_%s_conf, (%s));\n",
+				procs, block.Message.Sender, block.Message.Recipient,
+				pvConstant(valKnowledgeMap, block.Message.Sender, c, "bitstring"),
+			)
+		case "public":
 			procs = fmt.Sprintf(
 				"%s\tin(chan_%s_to_%s, (%s));\n",
 				procs, block.Message.Sender, block.Message.Recipient,
@@ -327,21 +441,100 @@ func pvPhase(block Block) (string, error) {
 	// return fmt.Sprintf("phase %d;", block.Phase.Number)
 }
 
+// pvExpandBroadcasts splits messages with several recipients into one
+// message per recipient, each of which becomes its own pair of processes.
+func pvExpandBroadcasts(blocks []Block) []Block {
+	expanded := []Block{}
+	for _, block := range blocks {
+		switch block.Kind {
+		case BlockKindMessage:
+			for _, recipient := range constructMessageRecipients(block.Message) {
+				b := block
+				b.Message.Recipient = recipient
+				b.Message.Recipients = []string{recipient}
+				expanded = append(expanded, b)
+			}
+		default:
+			expanded = append(expanded, block)
+		}
+	}
+	return expanded
+}
+
+// pvPrimitiveSupported refuses the primitive forms that the ProVerif
+// prelude does not declare: ring signatures over rings other than three
+// public keys, and Shamir sharing other than two-of-three, including the
+// threshold signatures built on it.
+func pvPrimitiveSupported(a Value, outputs int) error {
+	switch a.Kind {
+	case ValueKindPrimitive:
+		p := a.Primitive
+		switch {
+		case p.Name == "RINGSIGN" && len(p.Arguments) != 4,
+			p.Name == "RINGSIGNVERIF" && len(p.Arguments) != 5:
+			return fmt.Errorf(
+				"%s over a ring of other than 3 public keys is not yet supported in ProVerif model generation",
+				p.Name,
+			)
+		case p.Name == "SHAMIR_SPLIT" && (len(p.Arguments) != 1 || outputs != 3),
+			p.Name == "SHAMIR_JOIN" && len(p.Arguments) != 2:
+			return fmt.Errorf(
+				"%s with other than a 2-of-3 split is not yet supported in ProVerif model generation",
+				p.Name,
+			)
+		case p.Name == "THRESHOLD_SIGN", p.Name == "THRESHOLD_VERIF":
+			return fmt.Errorf(
+				"%s is not yet supported in ProVerif model generation",
+				p.Name,
+			)
+		}
+		for _, arg := range p.Arguments {
+			err := pvPrimitiveSupported(arg, 1)
+			if err != nil {
+				return err
+			}
+		}
+	case ValueKindEquation:
+		for _, v := range a.Equation.Values {
+			err := pvPrimitiveSupported(v, 1)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func pvModel(m Model, valKnowledgeMap KnowledgeMap) (string, error) {
 	pv := ""
 	procs := ""
 	consts := ""
 	pc := 0
 	cc := 0
-	for _, block := range m.Blocks {
+	blocks := pvExpandBroadcasts(m.Blocks)
+	for _, block := range blocks {
+		if block.Kind != BlockKindPrincipal {
+			continue
+		}
+		for _, expression := range block.Principal.Expressions {
+			if expression.Kind != ExpressionKindAssignment {
+				continue
+			}
+			err := pvPrimitiveSupported(expression.Right, len(expression.Left))
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+	for _, block := range blocks {
 		switch block.Kind {
-		case "principal":
+		case BlockKindPrincipal:
 			procs, consts, pc, cc = pvPrincipal(
 				valKnowledgeMap, block, procs, consts, pc, cc,
 			)
-		case "message":
+		case BlockKindMessage:
 			procs, pc = pvMessage(valKnowledgeMap, block, procs, pc)
-		case "phase":
+		case BlockKindPhase:
 			pvp, err := pvPhase(block)
 			if err != nil {
 				return "", err
@@ -353,7 +546,12 @@ func pvModel(m Model, valKnowledgeMap KnowledgeMap) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	pv = pv + libpv.Parameters(m.Attacker)
+	attacker := "active"
+	switch m.Attacker {
+	case "passive":
+		attacker = "passive"
+	}
+	pv = pv + libpv.Parameters(attacker)
 	pv = pv + libpv.Types()
 	pv = pv + libpv.Constants(valKnowledgeMap, consts)
 	pv = pv + libpv.CorePrims()
@@ -361,6 +559,6 @@ func pvModel(m Model, valKnowledgeMap KnowledgeMap) (string, error) {
 	pv = pv + libpv.Channels(valKnowledgeMap)
 	pv = pv + queries
 	pv = pv + procs
-	pv = pv + libpv.TopLevel(m.Blocks)
+	pv = pv + libpv.TopLevel(blocks)
 	return pv, nil
 }
@@ -13,13 +13,13 @@ func queryStart(
 ) {
 	valAttackerState := attackerStateGetRead()
 	switch query.Kind {
-	case "confidentiality":
+	case QueryKindConfidentiality:
 		queryConfidentiality(query, valKnowledgeMap, valPrincipalState, valAttackerState)
-	case "authentication":
+	case QueryKindAuthentication:
 		queryAuthentication(query, valKnowledgeMap, valPrincipalState)
-	case "freshness":
+	case QueryKindFreshness:
 		queryFreshness(query, valPrincipalState, valAttackerState)
-	case "unlinkability":
+	case QueryKindUnlinkability:
 		queryUnlinkability(query, valPrincipalState, valAttackerState)
 	}
 }
@@ -35,26 +35,29 @@ func queryConfidentiality(
 		Options:  []QueryOptionResult{},
 	}
 	v, _ := valueResolveValueInternalValuesFromKnowledgeMap(Value{
-		Kind:     "constant",
+		Kind:     ValueKindConstant,
 		Constant: query.Constants[0],
 	}, valKnowledgeMap)
-	ii := valueEquivalentValueInValues(v, valAttackerState.Known)
+	ii := attackerStateKnows(valAttackerState, v)
 	if ii < 0 {
 		return result
 	}
-	mutatedInfo := queryGetMutatedInfo(valPrincipalState)
-	result.Resolved = true
-	result.Summary = infoVerifyResultSummary(mutatedInfo, fmt.Sprintf(
+	mutated := queryGetMutated(valPrincipalState)
+	derivation := fmt.Sprintf(
 		"%s (%s) is obtained by Attacker.",
 		prettyConstant(query.Constants[0]),
 		prettyValue(valAttackerState.Known[ii]),
-	), result.Options)
+	)
+	result.Resolved = true
+	result.Summary = infoVerifyResultSummary(queryGetMutatedInfo(mutated), derivation, result.Options)
+	result.Attacks = queryAttack(mutated, derivation, result.Summary, valPrincipalState)
 	result = queryPrecondition(result, valPrincipalState)
 	written := verifyResultsPutWrite(result)
 	if written {
-		InfoMessage(fmt.Sprintf(
-			"%s: %s", prettyQuery(query), result.Summary,
-		), "result", true)
+		eventEmit(EventQueryResolved{
+			Result:   result,
+			Analysis: verifyAnalysisCountGet(),
+		})
 	}
 	return result
 }
@@ -77,11 +80,11 @@ func queryAuthentication(
 	)
 	for f, index := range indices {
 		b := valPrincipalState.BeforeRewrite[index]
-		mutatedInfo := queryGetMutatedInfo(valPrincipalState)
+		mutated := queryGetMutated(valPrincipalState)
 		if passes[f] && (query.Message.Sender != sender) {
 			result.Resolved = true
 			result = queryPrecondition(result, valPrincipalState)
-			return queryAuthenticationHandlePass(result, c, b, mutatedInfo, sender, valPrincipalState)
+			return queryAuthenticationHandlePass(result, c, b, mutated, sender, valPrincipalState)
 		}
 	}
 	return result
@@ -109,7 +112,7 @@ func queryAuthenticationGetPassIndices(
 			continue
 		}
 		switch a.Kind {
-		case "constant", "equation":
+		case ValueKindConstant, ValueKindEquation:
 			continue
 		}
 		if !valueFindConstantInPrimitive(c, a.Primitive, valPrincipalState) {
@@ -121,10 +124,10 @@ func queryAuthenticationGetPassIndices(
 		}
 		b := valPrincipalState.BeforeRewrite[iiii]
 		if primitiveIsCorePrim(b.Primitive.Name) {
-			prim, _ := primitiveCoreGet(b.Primitive.Name)
+			prim, _ := primitiveCoreGet(b.Primitive)
 			hasRule = prim.HasRule
 		} else {
-			prim, _ := primitiveGet(b.Primitive.Name)
+			prim, _ := primitiveGet(b.Primitive)
 			hasRule = prim.Rewrite.HasRule
 		}
 		if !hasRule {
@@ -142,20 +145,23 @@ func queryAuthenticationGetPassIndices(
 }
 
 func queryAuthenticationHandlePass(
-	result VerifyResult, c Constant, b Value, mutated string, sender string,
+	result VerifyResult, c Constant, b Value, mutated []string, sender string,
 	valPrincipalState PrincipalState,
 ) VerifyResult {
 	cc := valueResolveConstant(c, valPrincipalState)
-	result.Summary = infoVerifyResultSummary(mutated, fmt.Sprintf(
+	derivation := fmt.Sprintf(
 		"%s (%s), sent by %s and not by %s, is successfully used in %s within %s's state.",
 		prettyConstant(c), prettyValue(cc), sender, result.Query.Message.Sender,
 		prettyValue(b), result.Query.Message.Recipient,
-	), result.Options)
+	)
+	result.Summary = infoVerifyResultSummary(queryGetMutatedInfo(mutated), derivation, result.Options)
+	result.Attacks = queryAttack(mutated, derivation, result.Summary, valPrincipalState)
 	written := verifyResultsPutWrite(result)
 	if written {
-		InfoMessage(fmt.Sprintf(
-			"%s: %s", prettyQuery(result.Query), result.Summary,
-		), "result", true)
+		eventEmit(EventQueryResolved{
+			Result:   result,
+			Analysis: verifyAnalysisCountGet(),
+		})
 	}
 	return result
 }
@@ -170,25 +176,28 @@ func queryFreshness(
 		Options:  []QueryOptionResult{},
 	}
 	freshnessFound := valueContainsFreshValues(Value{
-		Kind:     "constant",
+		Kind:     ValueKindConstant,
 		Constant: query.Constants[0],
 	}, query.Constants[0], valPrincipalState, valAttackerState)
 	if freshnessFound {
 		return result
 	}
-	mutatedInfo := queryGetMutatedInfo(valPrincipalState)
-	result.Resolved = true
-	result.Summary = infoVerifyResultSummary(mutatedInfo, fmt.Sprintf(
+	mutated := queryGetMutated(valPrincipalState)
+	derivation := fmt.Sprintf(
 		"%s (%s) is not a fresh value. If used as a message, it could be replayed, leading to potential replay attacks.",
 		prettyConstant(query.Constants[0]),
 		prettyValue(valueResolveConstant(query.Constants[0], valPrincipalState)),
-	), result.Options)
+	)
+	result.Resolved = true
+	result.Summary = infoVerifyResultSummary(queryGetMutatedInfo(mutated), derivation, result.Options)
+	result.Attacks = queryAttack(mutated, derivation, result.Summary, valPrincipalState)
 	result = queryPrecondition(result, valPrincipalState)
 	written := verifyResultsPutWrite(result)
 	if written {
-		InfoMessage(fmt.Sprintf(
-			"%s: %s", prettyQuery(query), result.Summary,
-		), "result", true)
+		eventEmit(EventQueryResolved{
+			Result:   result,
+			Analysis: verifyAnalysisCountGet(),
+		})
 	}
 	return result
 }
@@ -213,26 +222,29 @@ func queryUnlinkability(
 	noFreshness := []Constant{}
 	for _, c := range query.Constants {
 		if !valueContainsFreshValues(Value{
-			Kind:     "constant",
+			Kind:     ValueKindConstant,
 			Constant: c,
 		}, c, valPrincipalState, valAttackerState) {
 			noFreshness = append(noFreshness, c)
 		}
 	}
 	if len(noFreshness) > 0 {
-		mutatedInfo := queryGetMutatedInfo(valPrincipalState)
-		result.Resolved = true
-		result.Summary = infoVerifyResultSummary(mutatedInfo, fmt.Sprintf(
+		mutated := queryGetMutated(valPrincipalState)
+		derivation := fmt.Sprintf(
 			"%s (%s) cannot be a suitable unlinkability candidate since it does not satisfy freshness.",
 			prettyConstant(noFreshness[0]),
 			prettyValue(valueResolveConstant(noFreshness[0], valPrincipalState)),
-		), result.Options)
+		)
+		result.Resolved = true
+		result.Summary = infoVerifyResultSummary(queryGetMutatedInfo(mutated), derivation, result.Options)
+		result.Attacks = queryAttack(mutated, derivation, result.Summary, valPrincipalState)
 		result = queryPrecondition(result, valPrincipalState)
 		written := verifyResultsPutWrite(result)
 		if written {
-			InfoMessage(fmt.Sprintf(
-				"%s: %s", prettyQuery(query), result.Summary,
-			), "result", true)
+			eventEmit(EventQueryResolved{
+				Result:   result,
+				Analysis: verifyAnalysisCountGet(),
+			})
 		}
 		return result
 	}
@@ -253,7 +265,7 @@ func queryUnlinkability(
 			}
 			obtainable := false
 			switch a.Kind {
-			case "primitive":
+			case ValueKindPrimitive:
 				ok0, _ := possibleToReconstructPrimitive(a.Primitive, valAttackerState)
 				ok1, _, _ := possibleToRecomposePrimitive(a.Primitive, valAttackerState)
 				obtainable = ok0 || ok1
@@ -261,20 +273,23 @@ func queryUnlinkability(
 			if !obtainable {
 				continue
 			}
-			mutatedInfo := queryGetMutatedInfo(valPrincipalState)
-			result.Resolved = true
-			result.Summary = infoVerifyResultSummary(mutatedInfo, fmt.Sprintf(
+			mutated := queryGetMutated(valPrincipalState)
+			derivation := fmt.Sprintf(
 				"%s and %s %s (%s), %s.",
 				prettyConstant(constants[i]), prettyConstant(constants[ii]),
 				"are not unlinkable since they are the output of the same primitive",
 				prettyValue(a), "which can be obtained by Attacker",
-			), result.Options)
+			)
+			result.Resolved = true
+			result.Summary = infoVerifyResultSummary(queryGetMutatedInfo(mutated), derivation, result.Options)
+			result.Attacks = queryAttack(mutated, derivation, result.Summary, valPrincipalState)
 			result = queryPrecondition(result, valPrincipalState)
 			written := verifyResultsPutWrite(result)
 			if written {
-				InfoMessage(fmt.Sprintf(
-					"%s: %s", prettyQuery(query), result.Summary,
-				), "result", true)
+				eventEmit(EventQueryResolved{
+					Result:   result,
+					Analysis: verifyAnalysisCountGet(),
+				})
 			}
 			return result
 		}
@@ -324,18 +339,44 @@ func queryPrecondition(
 	return result
 }
 
-func queryGetMutatedInfo(valPrincipalState PrincipalState) string {
-	mutatedInfo := ""
+func queryGetMutated(valPrincipalState PrincipalState) []string {
+	mutated := []string{}
 	for i := range valPrincipalState.Constants {
 		if !valPrincipalState.Mutated[i] {
 			continue
 		}
-		mutatedInfo = fmt.Sprintf("%s\n %s%s → %s (originally %s)",
-			mutatedInfo, "           ",
-			prettyConstant(valPrincipalState.Constants[i]),
-			prettyValue(valPrincipalState.Assigned[i]),
-			prettyValue(valPrincipalState.BeforeMutate[i]),
+		mutated = append(mutated, queryGetMutatedValue(valPrincipalState, i))
+	}
+	return mutated
+}
+
+func queryGetMutatedValue(valPrincipalState PrincipalState, i int) string {
+	return fmt.Sprintf("%s → %s (originally %s)",
+		prettyConstant(valPrincipalState.Constants[i]),
+		prettyValue(valPrincipalState.Assigned[i]),
+		prettyValue(valPrincipalState.BeforeMutate[i]),
+	)
+}
+
+func queryGetMutatedInfo(mutated []string) string {
+	mutatedInfo := ""
+	for _, m := range mutated {
+		mutatedInfo = fmt.Sprintf("%s\n %s%s",
+			mutatedInfo, "           ", m,
 		)
 	}
 	return mutatedInfo
 }
+
+func queryAttack(
+	mutated []string, derivation string, summary string, valPrincipalState PrincipalState,
+) []QueryAttack {
+	return []QueryAttack{{
+		Mutated:    mutated,
+		Minimal:    []string{},
+		Derivation: derivation,
+		Summary:    summary,
+		state:      valPrincipalState,
+		reduced:    len(mutated) == 0,
+	}}
+}
@@ -0,0 +1,172 @@
+/* SPDX-FileCopyrightText: © 2019-2020 Nadim Kobeissi <nadim@symbolic.software>
+ * SPDX-License-Identifier: GPL-3.0-only */
+// 00000000000000000000000000000000
+
+package vplogic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// repeatUnroll replaces every `repeat` block with as many copies of its
+// blocks as it has iterations, evaluating index expressions such as
+// ck_{i+1} in constant and principal names along the way. Each unrolled
+// block remembers the repeat block it came from so that the compact form
+// can be printed back.
+func repeatUnroll(m Model) (Model, error) {
+	counter := 0
+	blocks, err := repeatUnrollBlocks(m.Blocks, map[string]int{}, &counter)
+	if err != nil {
+		return Model{}, err
+	}
+	for _, query := range m.Queries {
+		err = repeatCheckQuery(query)
+		if err != nil {
+			return Model{}, err
+		}
+	}
+	m.Blocks = blocks
+	return m, nil
+}
+
+func repeatUnrollBlocks(
+	blocks []Block, indices map[string]int, counter *int,
+) ([]Block, error) {
+	unrolled := []Block{}
+	for _, blck := range blocks {
+		if blck.Kind != BlockKindRepeat {
+			b, err := repeatInstantiateBlock(blck, indices)
+			if err != nil {
+				return []Block{}, fragmentOriginError(blck, err)
+			}
+			unrolled = append(unrolled, b)
+			continue
+		}
+		r := blck.Repeat
+		if r.Count < 1 {
+			return []Block{}, fragmentOriginError(blck, fmt.Errorf(
+				"repeat block must have at least one iteration (%d)", r.Count,
+			))
+		}
+		if _, ok := indices[r.Index]; ok {
+			return []Block{}, fragmentOriginError(blck, fmt.Errorf(
+				"repeat block reuses index of enclosing repeat block (%s)", r.Index,
+			))
+		}
+		*counter = *counter + 1
+		r.ID = *counter
+		for i := 0; i < r.Count; i++ {
+			iterationIndices := map[string]int{r.Index: i}
+			for k, v := range indices {
+				iterationIndices[k] = v
+			}
+			rb, err := repeatUnrollBlocks(r.Blocks, iterationIndices, counter)
+			if err != nil {
+				return []Block{}, err
+			}
+			for _, b := range rb {
+				b.Repeat = r
+				unrolled = append(unrolled, b)
+			}
+		}
+	}
+	return unrolled, nil
+}
+
+func repeatInstantiateBlock(blck Block, indices map[string]int) (Block, error) {
+	var err error
+	instantiate := func(name string) string {
+		n, e := repeatInstantiateName(name, indices)
+		if e != nil && err == nil {
+			err = e
+		}
+		return n
+	}
+	b := fragmentMapBlock(blck, instantiate, instantiate)
+	return b, err
+}
+
+func repeatCheckQuery(query Query) error {
+	names := []string{query.Message.Sender, query.Message.Recipient}
+	for _, c := range query.Constants {
+		names = append(names, c.Name)
+	}
+	for _, c := range query.Message.Constants {
+		names = append(names, c.Name)
+	}
+	for _, option := range query.Options {
+		names = append(names, option.Message.Sender, option.Message.Recipient)
+		for _, c := range option.Message.Constants {
+			names = append(names, c.Name)
+		}
+	}
+	for _, name := range names {
+		if strings.Contains(name, "{") {
+			return fmt.Errorf(
+				"query (%s) cannot use index expressions",
+				prettyQuery(query),
+			)
+		}
+	}
+	return nil
+}
+
+// repeatInstantiateName replaces each index expression in a name, such
+// as the {i+1} in ck_{i+1}, with its value for the current iteration.
+func repeatInstantiateName(name string, indices map[string]int) (string, error) {
+	instantiated := ""
+	for {
+		start := strings.Index(name, "{")
+		if start < 0 {
+			return instantiated + name, nil
+		}
+		end := strings.Index(name[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated index expression (%s)", name)
+		}
+		end = start + end
+		n, err := repeatEvaluateIndex(name[start+1:end], indices)
+		if err != nil {
+			return "", fmt.Errorf("%v (%s)", err, name)
+		}
+		instantiated = instantiated + name[:start] + strconv.Itoa(n)
+		name = name[end+1:]
+	}
+}
+
+func repeatEvaluateIndex(expr string, indices map[string]int) (int, error) {
+	expr = strings.ToLower(strings.ReplaceAll(expr, " ", ""))
+	total := 0
+	sign := 1
+	term := ""
+	for i := 0; i <= len(expr); i++ {
+		if i < len(expr) && expr[i] != '+' && expr[i] != '-' {
+			term = term + string(expr[i])
+			continue
+		}
+		if len(term) == 0 {
+			return 0, fmt.Errorf("invalid index expression")
+		}
+		v, err := strconv.Atoi(term)
+		if err != nil {
+			var ok bool
+			v, ok = indices[term]
+			if !ok {
+				return 0, fmt.Errorf("unknown index variable %s", term)
+			}
+		}
+		total = total + (sign * v)
+		term = ""
+		if i < len(expr) && expr[i] == '-' {
+			sign = -1
+		} else {
+			sign = 1
+		}
+	}
+	if total < 0 {
+		return 0, fmt.Errorf("index expression evaluates to a negative number")
+	}
+	return total, nil
+}
@@ -9,8 +9,19 @@ import (
 	"strings"
 )
 
+// SanityCheck reports the first problem that would prevent a model from
+// being verified, such as an undeclared constant or a malformed query.
+func SanityCheck(m Model) error {
+	_, _, err := sanity(m)
+	return err
+}
+
 func sanity(m Model) (KnowledgeMap, []PrincipalState, error) {
-	err := sanityPhases(m)
+	err := sanityAttacker(m)
+	if err != nil {
+		return KnowledgeMap{}, []PrincipalState{}, err
+	}
+	err = sanityPhases(m)
 	if err != nil {
 		return KnowledgeMap{}, []PrincipalState{}, err
 	}
@@ -30,22 +41,51 @@ func sanity(m Model) (KnowledgeMap, []PrincipalState, error) {
 	return valKnowledgeMap, valPrincipalStates, nil
 }
 
+func sanityAttacker(m Model) error {
+	switch m.Attacker {
+	case "passive", "active", "replay-only", "no-inject":
+		return nil
+	case "bounded-depth":
+		if m.AttackerDepth < 1 {
+			return fmt.Errorf(
+				"bounded-depth attacker must have a depth of at least 1 (%d)",
+				m.AttackerDepth,
+			)
+		}
+		return nil
+	case "per-channel":
+		if len(m.AttackerChannels) == 0 {
+			return fmt.Errorf("per-channel attacker must specify at least one channel")
+		}
+		for _, c := range m.AttackerChannels {
+			if c.Sender == c.Recipient {
+				return fmt.Errorf(
+					"per-channel attacker channel (%s -> %s) must be between two different principals",
+					c.Sender, c.Recipient,
+				)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid attacker (%s)", m.Attacker)
+}
+
 func sanityPhases(m Model) error {
 	phase := 0
 	for _, blck := range m.Blocks {
 		switch blck.Kind {
-		case "phase":
+		case BlockKindPhase:
 			switch {
 			case blck.Phase.Number <= phase:
-				return fmt.Errorf(
+				return fragmentOriginError(blck, fmt.Errorf(
 					"phase being declared (%d) must be superior to last declared phase (%d)",
 					blck.Phase.Number, phase,
-				)
+				))
 			case blck.Phase.Number != phase+1:
-				return fmt.Errorf(
+				return fragmentOriginError(blck, fmt.Errorf(
 					"phase being declared (%d) skips phases since last declared phase (%d)",
 					blck.Phase.Number, phase,
-				)
+				))
 			default:
 				phase = blck.Phase.Number
 			}
@@ -58,7 +98,7 @@ func sanityAssignmentConstants(
 	right Value, constants []Constant, valKnowledgeMap KnowledgeMap,
 ) ([]Constant, error) {
 	switch right.Kind {
-	case "constant":
+	case ValueKindConstant:
 		unique := true
 		for _, c := range constants {
 			if right.Constant.Name == c.Name {
@@ -69,7 +109,7 @@ func sanityAssignmentConstants(
 		if unique {
 			constants = append(constants, right.Constant)
 		}
-	case "primitive":
+	case ValueKindPrimitive:
 		sacfp, err := sanityAssignmentConstantsFromPrimitive(
 			right, constants, valKnowledgeMap,
 		)
@@ -77,7 +117,7 @@ func sanityAssignmentConstants(
 			return []Constant{}, err
 		}
 		constants = append(constants, sacfp...)
-	case "equation":
+	case ValueKindEquation:
 		constants = append(constants, sanityAssignmentConstantsFromEquation(
 			right, constants,
 		)...)
@@ -107,7 +147,7 @@ func sanityAssignmentConstantsFromPrimitive(
 	}
 	for _, a := range right.Primitive.Arguments {
 		switch a.Kind {
-		case "constant":
+		case ValueKindConstant:
 			unique := true
 			for _, c := range constants {
 				if a.Constant.Name == c.Name {
@@ -118,12 +158,12 @@ func sanityAssignmentConstantsFromPrimitive(
 			if unique {
 				constants = append(constants, a.Constant)
 			}
-		case "primitive":
+		case ValueKindPrimitive:
 			constants, err = sanityAssignmentConstants(a, constants, valKnowledgeMap)
 			if err != nil {
 				return []Constant{}, err
 			}
-		case "equation":
+		case ValueKindEquation:
 			constants, err = sanityAssignmentConstants(a, constants, valKnowledgeMap)
 			if err != nil {
 				return []Constant{}, err
@@ -153,11 +193,11 @@ func sanityPrimitive(p Primitive, outputs []Constant) error {
 	output := 0
 	check := false
 	if primitiveIsCorePrim(p.Name) {
-		prim, _ := primitiveCoreGet(p.Name)
+		prim, _ := primitiveCoreGet(p)
 		output = prim.Output
 		check = prim.Check
 	} else {
-		prim, err := primitiveGet(p.Name)
+		prim, err := primitiveGet(p)
 		if err != nil {
 			return err
 		}
@@ -180,6 +220,35 @@ func sanityPrimitive(p Primitive, outputs []Constant) error {
 			p.Name,
 		)
 	}
+	if p.Name == "SHAMIR_SPLIT" {
+		return sanityShamirSplit(p, outputs)
+	}
+	return nil
+}
+
+func sanityShamirSplit(p Primitive, outputs []Constant) error {
+	if len(outputs) < 2 {
+		return fmt.Errorf(
+			"primitive %s has %d outputs, expecting at least 2",
+			p.Name, len(outputs),
+		)
+	}
+	if len(p.Arguments) > 1 {
+		t := p.Arguments[1]
+		if t.Kind != ValueKindConstant || strings.Trim(t.Constant.Name, "0123456789") != "" {
+			return fmt.Errorf(
+				"primitive %s takes its threshold as a number (%s)",
+				p.Name, prettyValue(t),
+			)
+		}
+	}
+	t := primitiveShamirThreshold(p)
+	if t < 1 || t > len(outputs) {
+		return fmt.Errorf(
+			"primitive %s has a threshold of %d, expecting between 1 and %d",
+			p.Name, t, len(outputs),
+		)
+	}
 	return nil
 }
 
@@ -187,13 +256,13 @@ func sanityQueries(m Model, valKnowledgeMap KnowledgeMap) error {
 	var err error
 	for _, query := range m.Queries {
 		switch query.Kind {
-		case "confidentiality":
+		case QueryKindConfidentiality:
 			err = sanityQueriesConfidentiality(query, valKnowledgeMap)
-		case "authentication":
+		case QueryKindAuthentication:
 			err = sanityQueriesAuthentication(query, valKnowledgeMap)
-		case "freshness":
+		case QueryKindFreshness:
 			err = sanityQueriesFreshness(query, valKnowledgeMap)
-		case "unlinkability":
+		case QueryKindUnlinkability:
 			err = sanityQueriesUnlinkability(query, valKnowledgeMap)
 		default:
 			return fmt.Errorf("invalid query kind")
@@ -228,6 +297,18 @@ func sanityQueriesAuthentication(query Query, valKnowledgeMap KnowledgeMap) erro
 			prettyQuery(query),
 		)
 	}
+	if len(constructMessageRecipients(query.Message)) != 1 {
+		return fmt.Errorf(
+			"authentication query (%s) has more than one recipient",
+			prettyQuery(query),
+		)
+	}
+	if len(query.Message.Channel) > 0 {
+		return fmt.Errorf(
+			"authentication query (%s) cannot specify a channel",
+			prettyQuery(query),
+		)
+	}
 	c := query.Message.Constants[0]
 	i := valueGetKnowledgeMapIndexFromConstant(valKnowledgeMap, c)
 	if i < 0 {
@@ -295,13 +376,25 @@ func sanityQueriesUnlinkability(query Query, valKnowledgeMap KnowledgeMap) error
 func sanityQueryOptions(query Query) error {
 	for _, option := range query.Options {
 		switch option.Kind {
-		case "precondition":
+		case QueryOptionKindPrecondition:
 			if len(option.Message.Constants) != 1 {
 				return fmt.Errorf(
 					"precondition option message (%s) has more than one constant",
 					prettyQuery(query),
 				)
 			}
+			if len(constructMessageRecipients(option.Message)) != 1 {
+				return fmt.Errorf(
+					"precondition option message (%s) has more than one recipient",
+					prettyQuery(query),
+				)
+			}
+			if len(option.Message.Channel) > 0 {
+				return fmt.Errorf(
+					"precondition option message (%s) cannot specify a channel",
+					prettyQuery(query),
+				)
+			}
 		default:
 			return fmt.Errorf(
 				"invalid query option kind (%s)", option.Kind,
@@ -346,25 +439,33 @@ func sanityDeclaredPrincipals(m Model) ([]string, error) {
 	principals := []string{}
 	for _, block := range m.Blocks {
 		switch block.Kind {
-		case "principal":
+		case BlockKindPrincipal:
 			principals, _ = appendUniqueString(principals, block.Principal.Name)
 			declared, _ = appendUniqueString(declared, block.Principal.Name)
 		}
 	}
 	for _, block := range m.Blocks {
 		switch block.Kind {
-		case "message":
-			principals, _ = appendUniqueString(principals, block.Message.Sender)
-			principals, _ = appendUniqueString(principals, block.Message.Recipient)
+		case BlockKindMessage:
+			for _, p := range append([]string{block.Message.Sender}, constructMessageRecipients(block.Message)...) {
+				if !strInSlice(p, declared) {
+					return []string{}, fragmentOriginError(block, fmt.Errorf("principal does not exist (%s)", p))
+				}
+				principals, _ = appendUniqueString(principals, p)
+			}
 		}
 	}
 	for _, query := range m.Queries {
 		switch query.Kind {
-		case "authentication":
+		case QueryKindAuthentication:
 			principals, _ = appendUniqueString(principals, query.Message.Sender)
 			principals, _ = appendUniqueString(principals, query.Message.Recipient)
 		}
 	}
+	for _, c := range m.AttackerChannels {
+		principals, _ = appendUniqueString(principals, c.Sender)
+		principals, _ = appendUniqueString(principals, c.Recipient)
+	}
 	for _, p := range principals {
 		if !strInSlice(p, declared) {
 			return []string{}, fmt.Errorf("principal does not exist (%s)", p)
@@ -390,12 +491,6 @@ func sanityFailOnFailedCheckedPrimitiveRewrite(failedRewrites []Primitive) error
 }
 
 func sanityCheckEquationRootGenerator(e Equation) error {
-	if len(e.Values) > 3 {
-		return fmt.Errorf(
-			"too many layers in equation (%s), maximum is 2",
-			prettyEquation(e),
-		)
-	}
 	for i, c := range e.Values {
 		if i == 0 {
 			if strings.ToLower(c.Constant.Name) != "g" {
@@ -420,19 +515,19 @@ func sanityCheckEquationRootGenerator(e Equation) error {
 func sanityCheckEquationGenerators(a Value, valPrincipalState PrincipalState) error {
 	var err error
 	switch a.Kind {
-	case "primitive":
+	case ValueKindPrimitive:
 		for _, va := range a.Primitive.Arguments {
 			switch va.Kind {
-			case "primitive":
+			case ValueKindPrimitive:
 				err = sanityCheckEquationGenerators(va, valPrincipalState)
-			case "equation":
+			case ValueKindEquation:
 				err = sanityCheckEquationRootGenerator(va.Equation)
 			}
 			if err != nil {
 				return err
 			}
 		}
-	case "equation":
+	case ValueKindEquation:
 		err = sanityCheckEquationRootGenerator(a.Equation)
 		if err != nil {
 			return err
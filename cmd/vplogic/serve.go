@@ -0,0 +1,282 @@
+/* SPDX-FileCopyrightText: © 2019-2020 Nadim Kobeissi <nadim@symbolic.software>
+ * SPDX-License-Identifier: GPL-3.0-only */
+// 00000000000000000000000000000000
+
+package vplogic
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+const (
+	serveErrorParse          = -32700
+	serveErrorInvalidRequest = -32600
+	serveErrorMethodNotFound = -32601
+	serveErrorInvalidParams  = -32602
+	serveErrorModel          = -32000
+	serveErrorCancelled      = -32800
+)
+
+type serveRequest struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params"`
+}
+
+type serveError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type serveModelParams struct {
+	Model      string `json:"model"`
+	Exhaustive bool   `json:"exhaustive"`
+}
+
+type serveValueParams struct {
+	Value Value `json:"value"`
+}
+
+type serveQueryParams struct {
+	Query Query `json:"query"`
+}
+
+type serveCancelParams struct {
+	ID json.RawMessage `json:"id"`
+}
+
+type serveVerifyResult struct {
+	Results []VerifyResult `json:"results"`
+	Code    string         `json:"code"`
+}
+
+type serveProgress struct {
+	ID    json.RawMessage `json:"id"`
+	Event string          `json:"event"`
+	Data  Event           `json:"data"`
+}
+
+// serveEventSink relays the events emitted while verifying as progress
+// notifications for the request with the given id, if any.
+type serveEventSink struct {
+	s  *serveState
+	id *json.RawMessage
+}
+
+type serveState struct {
+	w         io.Writer
+	writeLock sync.Mutex
+	busy      sync.Mutex
+	cancels   map[string]context.CancelFunc
+	cancelsMu sync.Mutex
+}
+
+// Serve answers JSON-RPC 2.0 requests read from r, writing responses and
+// notifications to w, until r is closed. Messages are framed as in the
+// Language Server Protocol, each preceded by a Content-Length header.
+// Requests use the same names as the internal-json subcommand, and may be
+// cancelled with a $/cancelRequest notification. While a verify request
+// runs, the events it emits are sent as verify/progress notifications.
+func Serve(r io.Reader, w io.Writer) error {
+	s := &serveState{
+		w:       w,
+		cancels: map[string]context.CancelFunc{},
+	}
+	eventSinkSaved := SetEventSink(serveEventSink{s: s})
+	defer SetEventSink(eventSinkSaved)
+	reader := bufio.NewReader(r)
+	var requestsGroup sync.WaitGroup
+	for {
+		body, err := serveReadMessage(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		req := serveRequest{}
+		err = json.Unmarshal(body, &req)
+		if err != nil {
+			s.reply(json.RawMessage("null"), nil, &serveError{
+				Code: serveErrorParse, Message: err.Error(),
+			})
+			continue
+		}
+		if req.Method == "$/cancelRequest" {
+			s.cancel(req.Params)
+			continue
+		}
+		if req.ID == nil {
+			continue
+		}
+		if req.JSONRPC != "2.0" || len(req.Method) == 0 {
+			s.reply(*req.ID, nil, &serveError{
+				Code: serveErrorInvalidRequest, Message: "invalid request",
+			})
+			continue
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		s.cancelsMu.Lock()
+		s.cancels[string(*req.ID)] = cancel
+		s.cancelsMu.Unlock()
+		requestsGroup.Add(1)
+		go func(req serveRequest) {
+			result, rErr := s.handle(ctx, req)
+			s.cancelsMu.Lock()
+			delete(s.cancels, string(*req.ID))
+			s.cancelsMu.Unlock()
+			cancel()
+			s.reply(*req.ID, result, rErr)
+			requestsGroup.Done()
+		}(req)
+	}
+	requestsGroup.Wait()
+	return nil
+}
+
+func serveReadMessage(reader *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(reader).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return []byte{}, io.EOF
+		}
+		return []byte{}, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return []byte{}, fmt.Errorf("invalid Content-Length header")
+	}
+	body := make([]byte, length)
+	_, err = io.ReadFull(reader, body)
+	return body, err
+}
+
+func (s *serveState) write(message interface{}) {
+	j, _ := json.Marshal(message)
+	s.writeLock.Lock()
+	fmt.Fprintf(s.w, "Content-Length: %d\r\n\r\n%s", len(j), j)
+	s.writeLock.Unlock()
+}
+
+func (s *serveState) reply(id json.RawMessage, result interface{}, err *serveError) {
+	response := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+	}
+	if err != nil {
+		response["error"] = err
+	} else {
+		response["result"] = result
+	}
+	s.write(response)
+}
+
+func (s *serveState) notify(method string, params interface{}) {
+	s.write(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+	})
+}
+
+func (e serveEventSink) Event(ev Event) {
+	if e.id == nil {
+		return
+	}
+	e.s.notify("verify/progress", serveProgress{
+		ID:    *e.id,
+		Event: EventName(ev),
+		Data:  ev,
+	})
+}
+
+func (s *serveState) cancel(params json.RawMessage) {
+	p := serveCancelParams{}
+	if json.Unmarshal(params, &p) != nil {
+		return
+	}
+	s.cancelsMu.Lock()
+	if cancel, ok := s.cancels[string(p.ID)]; ok {
+		cancel()
+	}
+	s.cancelsMu.Unlock()
+}
+
+// handle runs one request at a time, since the verification engine keeps
+// its state in package-level variables.
+func (s *serveState) handle(ctx context.Context, req serveRequest) (interface{}, *serveError) {
+	s.busy.Lock()
+	defer s.busy.Unlock()
+	if ctx.Err() != nil {
+		return nil, &serveError{Code: serveErrorCancelled, Message: "request cancelled"}
+	}
+	switch req.Method {
+	case "prettyValue":
+		p := serveValueParams{}
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nil, &serveError{Code: serveErrorInvalidParams, Message: err.Error()}
+		}
+		return prettyValue(p.Value), nil
+	case "prettyQuery":
+		p := serveQueryParams{}
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nil, &serveError{Code: serveErrorInvalidParams, Message: err.Error()}
+		}
+		return prettyQuery(p.Query), nil
+	case "knowledgeMap", "principalStates", "prettyPrint", "prettyDiagram", "verify":
+		p := serveModelParams{}
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nil, &serveError{Code: serveErrorInvalidParams, Message: err.Error()}
+		}
+		m, err := ParseModel("model.vp", []byte(p.Model))
+		if err != nil {
+			return nil, &serveError{Code: serveErrorModel, Message: err.Error()}
+		}
+		return s.handleModel(ctx, req, p, m)
+	}
+	return nil, &serveError{
+		Code: serveErrorMethodNotFound, Message: fmt.Sprintf("unknown method (%s)", req.Method),
+	}
+}
+
+func (s *serveState) handleModel(
+	ctx context.Context, req serveRequest, p serveModelParams, m Model,
+) (interface{}, *serveError) {
+	var result interface{}
+	var err error
+	switch req.Method {
+	case "knowledgeMap":
+		result, _, err = sanity(m)
+	case "principalStates":
+		_, result, err = sanity(m)
+	case "prettyPrint":
+		result, err = PrettyModel(m)
+	case "prettyDiagram":
+		result, err = PrettyDiagram(m)
+	case "verify":
+		SetEventSink(serveEventSink{s: s, id: req.ID})
+		VerifyExhaustiveShared = p.Exhaustive
+		valVerifyResults, resultsCode, vErr := VerifyModel(ctx, m)
+		VerifyExhaustiveShared = false
+		SetEventSink(serveEventSink{s: s})
+		if ctx.Err() != nil {
+			return nil, &serveError{Code: serveErrorCancelled, Message: "request cancelled"}
+		}
+		result, err = serveVerifyResult{
+			Results: valVerifyResults,
+			Code:    resultsCode,
+		}, vErr
+	}
+	if err != nil {
+		return nil, &serveError{Code: serveErrorModel, Message: err.Error()}
+	}
+	return result, nil
+}
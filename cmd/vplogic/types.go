@@ -4,25 +4,94 @@
 
 package vplogic
 
+import (
+	"sync"
+)
+
 // Model is the main parsed representation of the Verifpal model.
 type Model struct {
-	FileName string
-	Attacker string
-	Blocks   []Block
-	Queries  []Query
+	FileName         string
+	Attacker         string
+	AttackerDepth    int
+	AttackerChannels []Message
+	Imports          []Import
+	Blocks           []Block
+	Queries          []Query
 }
+
+type ValueKind uint8
+type ExpressionKind uint8
+type BlockKind uint8
+type QueryKind uint8
+type QueryOptionKind uint8
+
 type VerifyResult struct {
 	Query    Query
 	Resolved bool
 	Summary  string
 	Options  []QueryOptionResult
+	Attacks  []QueryAttack
+}
+
+// QueryAttack is one way in which a query was found to be contradicted:
+// the values that Attacker mutated, and what was then derived as a result.
+// Minimal lists the fewest of these mutations found to be enough for the
+// attack, whenever that is fewer than all of them.
+type QueryAttack struct {
+	Mutated    []string
+	Minimal    []string
+	Derivation string
+	Summary    string
+	state      PrincipalState
+	reduced    bool
 }
 
 type Block struct {
-	Kind      string
+	Kind      BlockKind
 	Principal Principal
 	Message   Message
 	Phase     Phase
+	Fragment  Fragment
+	Use       Use
+	Repeat    Repeat
+	Origin    *BlockOrigin
+}
+
+type BlockOrigin struct {
+	Fragment string
+	File     string
+	Line     int
+	Column   int
+	Parent   *BlockOrigin
+}
+
+type Import struct {
+	Path   string
+	Line   int
+	Column int
+}
+
+type Fragment struct {
+	Name       string
+	Parameters []string
+	Blocks     []Block
+	File       string
+	Line       int
+	Column     int
+}
+
+type Repeat struct {
+	ID     int
+	Index  string
+	Count  int
+	Blocks []Block
+}
+
+type Use struct {
+	Name      string
+	Arguments []string
+	Line      int
+	Column    int
 }
 
 type Principal struct {
@@ -31,9 +100,11 @@ type Principal struct {
 }
 
 type Message struct {
-	Sender    string
-	Recipient string
-	Constants []Constant
+	Sender     string
+	Recipient  string
+	Recipients []string
+	Channel    string
+	Constants  []Constant
 }
 
 type Phase struct {
@@ -41,14 +112,15 @@ type Phase struct {
 }
 
 type Query struct {
-	Kind      string
+	ID        int
+	Kind      QueryKind
 	Constants []Constant
 	Message   Message
 	Options   []QueryOption
 }
 
 type QueryOption struct {
-	Kind    string
+	Kind    QueryOptionKind
 	Message Message
 }
 
@@ -59,7 +131,7 @@ type QueryOptionResult struct {
 }
 
 type Expression struct {
-	Kind      string
+	Kind      ExpressionKind
 	Qualifier string
 	Constants []Constant
 	Left      []Constant
@@ -67,7 +139,7 @@ type Expression struct {
 }
 
 type Value struct {
-	Kind      string
+	Kind      ValueKind
 	Constant  Constant
 	Primitive Primitive
 	Equation  Equation
@@ -83,7 +155,10 @@ type Constant struct {
 }
 
 type Primitive struct {
-	Name      string
+	Name string
+	// ID is the index of the primitive's specification within
+	// primitiveCoreSpecs or primitiveSpecs, or -1 if there is none.
+	ID        int
 	Arguments []Value
 	Output    int
 	Check     bool
@@ -108,6 +183,7 @@ type DecomposeRule struct {
 	HasRule bool
 	Given   []int
 	Reveal  int
+	To      func(Primitive) Value
 	Filter  func(Primitive, Value, int) (Value, bool)
 }
 
@@ -176,13 +252,51 @@ type PrincipalState struct {
 	MutatableTo   [][]string
 	BeforeMutate  []Value
 	Phase         [][]int
+	Confidential  []bool
 	Lock          int
+	AttackerKnown int
 }
 
 type AttackerState struct {
 	Active       bool
+	Profile      string
+	Depth        int
+	Channels     []Message
 	CurrentPhase int
 	Known        []Value
+	knownIndex   *sync.Map
+}
+
+// EstimateResult describes the search space that active analysis of a model
+// starts out with, as computed by EstimateModel.
+type EstimateResult struct {
+	Stages       []EstimateStage
+	Primitives   []EstimatePrimitive
+	Dominant     []EstimateValue
+	Combinations float64
+	Cost         string
+}
+
+type EstimateStage struct {
+	Phase        int
+	Stage        int
+	Principal    string
+	Values       int
+	Combinations float64
+}
+
+type EstimatePrimitive struct {
+	Name  string
+	Arity int
+	Count int
+}
+
+type EstimateValue struct {
+	Principal string
+	Constant  Constant
+	Phase     int
+	Stage     int
+	Mutations int
 }
 
 type MutationMap struct {
@@ -193,6 +307,56 @@ type MutationMap struct {
 	Mutations      [][]Value
 	Combination    []Value
 	DepthIndex     []int
+	// Position numbers the combination among those tried so far, when
+	// the progress of the analysis is being saved to a checkpoint.
+	Position int
+}
+
+// EventSink receives the events emitted as a model is parsed and verified.
+// Events may be emitted from several goroutines at once.
+type EventSink interface {
+	Event(e Event)
+}
+
+// Event is implemented by each of the Event types below.
+type Event interface {
+	eventName() string
+}
+
+type EventParseStarted struct {
+	FileName string
+}
+
+type EventPhaseStarted struct {
+	Phase  int
+	Active bool
+}
+
+type EventStageStarted struct {
+	Stage int
+}
+
+type EventAnalysis struct {
+	Stage    int
+	Analysis int
+}
+
+type EventQueryResolved struct {
+	Result   VerifyResult
+	Analysis int
+}
+
+type EventVerificationFinished struct {
+	FileName    string
+	Results     []VerifyResult
+	ResultsCode string
+	Pruned      int
+}
+
+type EventMessage struct {
+	Kind     string
+	Message  string
+	Analysis int
 }
 
 type PvTemplate struct {
@@ -5,11 +5,15 @@
 package vplogic
 
 import (
+	"encoding/binary"
+	"hash/fnv"
+	"io"
+	"sort"
 	"strings"
 )
 
 var valueG = Value{
-	Kind: "constant",
+	Kind: ValueKindConstant,
 	Constant: Constant{
 		Name:        "g",
 		Guard:       false,
@@ -21,7 +25,7 @@ var valueG = Value{
 }
 
 var valueN = Value{
-	Kind: "constant",
+	Kind: ValueKindConstant,
 	Constant: Constant{
 		Name:        "nil",
 		Guard:       false,
@@ -33,7 +37,7 @@ var valueN = Value{
 }
 
 var valueGN = Value{
-	Kind: "equation",
+	Kind: ValueKindEquation,
 	Equation: Equation{
 		Values: []Value{valueG, valueN},
 	},
@@ -47,6 +51,29 @@ func valueIsGOrNil(c Constant) bool {
 	return false
 }
 
+// valueConstantIsInternal reports whether a constant was introduced by
+// Verifpal itself rather than declared in the model, such as the randomness
+// drawn by a key encapsulation. Its name starts with a character that no
+// identifier can contain, so it never clashes with the model's constants.
+func valueConstantIsInternal(c Constant) bool {
+	return strings.HasPrefix(c.Name, "#")
+}
+
+// valueConstantIsLiteral reports whether a constant stands for a string
+// or number literal written directly into the model, such as
+// "noise_ik_v1" or 0x01, rather than for a named value.
+func valueConstantIsLiteral(c Constant) bool {
+	switch {
+	case len(c.Name) == 0:
+		return false
+	case strings.HasPrefix(c.Name, "\""):
+		return true
+	case strings.HasPrefix(c.Name, "0x"):
+		return len(c.Name) > 2 && strings.Trim(c.Name[2:], "0123456789abcdefABCDEF") == ""
+	}
+	return strings.Trim(c.Name, "0123456789") == ""
+}
+
 func valueGetKnowledgeMapIndexFromConstant(valKnowledgeMap KnowledgeMap, c Constant) int {
 	for i := range valKnowledgeMap.Constants {
 		if valKnowledgeMap.Constants[i].Name == c.Name {
@@ -68,11 +95,11 @@ func valueGetPrincipalStateIndexFromConstant(valPrincipalState PrincipalState, c
 func valueGetConstantsFromValue(v Value) []Constant {
 	c := []Constant{}
 	switch v.Kind {
-	case "constant":
+	case ValueKindConstant:
 		c = append(c, v.Constant)
-	case "primitive":
+	case ValueKindPrimitive:
 		c = append(c, valueGetConstantsFromPrimitive(v.Primitive)...)
-	case "equation":
+	case ValueKindEquation:
 		c = append(c, valueGetConstantsFromEquation(v.Equation)...)
 	}
 	return c
@@ -82,11 +109,11 @@ func valueGetConstantsFromPrimitive(p Primitive) []Constant {
 	c := []Constant{}
 	for _, a := range p.Arguments {
 		switch a.Kind {
-		case "constant":
+		case ValueKindConstant:
 			c = append(c, a.Constant)
-		case "primitive":
+		case ValueKindPrimitive:
 			c = append(c, valueGetConstantsFromPrimitive(a.Primitive)...)
-		case "equation":
+		case ValueKindEquation:
 			c = append(c, valueGetConstantsFromEquation(a.Equation)...)
 		}
 	}
@@ -97,11 +124,11 @@ func valueGetConstantsFromEquation(e Equation) []Constant {
 	c := []Constant{}
 	for _, a := range e.Values {
 		switch a.Kind {
-		case "constant":
+		case ValueKindConstant:
 			c = append(c, a.Constant)
-		case "primitive":
+		case ValueKindPrimitive:
 			c = append(c, valueGetConstantsFromPrimitive(a.Primitive)...)
-		case "equation":
+		case ValueKindEquation:
 			c = append(c, valueGetConstantsFromEquation(a.Equation)...)
 		}
 	}
@@ -109,37 +136,41 @@ func valueGetConstantsFromEquation(e Equation) []Constant {
 }
 
 func valueEquivalentValues(a1 Value, a2 Value, considerOutput bool) bool {
+	if (a1.Primitive.Name == "XOR" && a1.Kind == ValueKindPrimitive) ||
+		(a2.Primitive.Name == "XOR" && a2.Kind == ValueKindPrimitive) {
+		return valueEquivalentXor(a1, a2)
+	}
 	switch a1.Kind {
-	case "constant":
+	case ValueKindConstant:
 		switch a2.Kind {
-		case "constant":
+		case ValueKindConstant:
 			if a1.Constant.Name != a2.Constant.Name {
 				return false
 			}
-		case "primitive":
+		case ValueKindPrimitive:
 			return false
-		case "equation":
+		case ValueKindEquation:
 			return false
 		}
-	case "primitive":
+	case ValueKindPrimitive:
 		switch a2.Kind {
-		case "constant":
+		case ValueKindConstant:
 			return false
-		case "primitive":
+		case ValueKindPrimitive:
 			equivPrim, _, _ := valueEquivalentPrimitives(
 				a1.Primitive, a2.Primitive, considerOutput,
 			)
 			return equivPrim
-		case "equation":
+		case ValueKindEquation:
 			return false
 		}
-	case "equation":
+	case ValueKindEquation:
 		switch a2.Kind {
-		case "constant":
+		case ValueKindConstant:
 			return false
-		case "primitive":
+		case ValueKindPrimitive:
 			return false
-		case "equation":
+		case ValueKindEquation:
 			return valueEquivalentEquations(
 				a1.Equation, a2.Equation,
 			)
@@ -154,6 +185,13 @@ func valueEquivalentPrimitives(
 	if p1.Name != p2.Name {
 		return false, 0, 0
 	}
+	if p1.Name == "XOR" {
+		equiv := valueEquivalentXor(
+			Value{Kind: ValueKindPrimitive, Primitive: p1},
+			Value{Kind: ValueKindPrimitive, Primitive: p2},
+		)
+		return equiv, p1.Output, p2.Output
+	}
 	if len(p1.Arguments) != len(p2.Arguments) {
 		return false, 0, 0
 	}
@@ -170,35 +208,114 @@ func valueEquivalentPrimitives(
 }
 
 func valueEquivalentEquations(e1 Equation, e2 Equation) bool {
-	e1Base := e1.Values[0].Equation.Values
-	e2Base := e2.Values[0].Equation.Values
-	e1Kind := e1.Values[0].Kind
-	e2Kind := e2.Values[0].Kind
+	v1 := valueFlattenEquation(e1).Values
+	v2 := valueFlattenEquation(e2).Values
 	switch {
-	case len(e1.Values) != len(e2.Values), len(e1.Values) == 0:
+	case len(v1) != len(v2), len(v1) == 0:
 		return false
-	case e1Kind == "equation" && e2Kind == "equation":
-		return valueEquivalentEquationsRule(
-			e1Base[1], e2Base[1], e1.Values[1], e2.Values[1],
-		) || valueEquivalentEquationsRule(
-			e1Base[1], e2.Values[1], e1.Values[1], e2Base[1],
-		)
-	case len(e1.Values) > 2:
-		return valueEquivalentEquationsRule(
-			e1.Values[1], e2.Values[1], e1.Values[2], e2.Values[2],
-		) || valueEquivalentEquationsRule(
-			e1.Values[1], e2.Values[2], e1.Values[2], e2.Values[1],
-		)
-	default:
-		return valueEquivalentEquationsRule(
-			e1.Values[0], e2.Values[1], e1.Values[1], e2.Values[0],
-		)
+	case !valueEquivalentValues(v1[0], v2[0], true):
+		return false
+	case len(v1) == 2:
+		return valueEquivalentValues(v1[1], v2[1], true)
+	case len(v1) == 3:
+		return (valueEquivalentValues(v1[1], v2[1], true) &&
+			valueEquivalentValues(v1[2], v2[2], true)) ||
+			(valueEquivalentValues(v1[1], v2[2], true) &&
+				valueEquivalentValues(v1[2], v2[1], true))
+	}
+	found, _ := valueRemoveValues(v1[1:], v2[1:])
+	return found
+}
+
+func valueIsXor(a Value) bool {
+	return a.Kind == ValueKindPrimitive && a.Primitive.Name == "XOR"
+}
+
+// valueXorTerms flattens nested XOR primitives into the terms they
+// combine, dropping the nil neutral element and cancelling out every pair
+// of equivalent terms, so that XOR(XOR(k, m), k) yields only m.
+func valueXorTerms(a Value) []Value {
+	if !valueIsXor(a) {
+		if a.Kind == ValueKindConstant && a.Constant.Name == "nil" {
+			return []Value{}
+		}
+		return []Value{a}
+	}
+	terms := []Value{}
+	for _, aa := range a.Primitive.Arguments {
+		for _, t := range valueXorTerms(aa) {
+			i := valueEquivalentValueInValues(t, terms)
+			if i >= 0 {
+				terms = append(terms[:i], terms[i+1:]...)
+			} else {
+				terms = append(terms, t)
+			}
+		}
+	}
+	return terms
+}
+
+func valueEquivalentXor(a1 Value, a2 Value) bool {
+	t1 := valueXorTerms(a1)
+	t2 := valueXorTerms(a2)
+	if len(t1) != len(t2) {
+		return false
+	}
+	found, _ := valueRemoveValues(t1, t2)
+	return found
+}
+
+// valueFlattenEquation rewrites an equation whose base is itself an
+// equation, such as (G^a)^b, into a single exponent chain, G^a^b.
+func valueFlattenEquation(e Equation) Equation {
+	if len(e.Values) == 0 || e.Values[0].Kind != ValueKindEquation {
+		return e
+	}
+	base := valueFlattenEquation(e.Values[0].Equation)
+	values := make([]Value, len(base.Values), len(base.Values)+len(e.Values)-1)
+	copy(values, base.Values)
+	return Equation{
+		Values: append(values, e.Values[1:]...),
+	}
+}
+
+// valueRemoveValues removes each of the given values from a list, such
+// as an exponent chain, regardless of their order, and returns whether all
+// of them were found along with the values left over.
+func valueRemoveValues(values []Value, remove []Value) (bool, []Value) {
+	remaining := make([]Value, len(values))
+	copy(remaining, values)
+	for _, r := range remove {
+		i := valueEquivalentValueInValues(r, remaining)
+		if i < 0 {
+			return false, remaining
+		}
+		remaining = append(remaining[:i], remaining[i+1:]...)
 	}
+	return true, remaining
 }
 
-func valueEquivalentEquationsRule(base1 Value, base2 Value, exp1 Value, exp2 Value) bool {
-	return (valueEquivalentValues(base1, exp2, true) &&
-		valueEquivalentValues(exp1, base2, true))
+// valueContainsValue reports whether v occurs anywhere within a, such as
+// the x within G^x or HASH(x, y), including as a itself.
+func valueContainsValue(a Value, v Value) bool {
+	if valueEquivalentValues(a, v, true) {
+		return true
+	}
+	switch a.Kind {
+	case ValueKindPrimitive:
+		for _, aa := range a.Primitive.Arguments {
+			if valueContainsValue(aa, v) {
+				return true
+			}
+		}
+	case ValueKindEquation:
+		for _, aa := range a.Equation.Values {
+			if valueContainsValue(aa, v) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func valueFindConstantInPrimitive(
@@ -207,19 +324,19 @@ func valueFindConstantInPrimitive(
 	a := valueResolveConstant(c, valPrincipalState)
 	for _, aa := range p.Arguments {
 		switch aa.Kind {
-		case "constant":
+		case ValueKindConstant:
 			if c.Name == aa.Constant.Name {
 				return true
 			}
 			switch a.Kind {
-			case "constant":
+			case ValueKindConstant:
 				if a.Constant.Name == aa.Constant.Name {
 					return true
 				}
 			}
-		case "primitive":
+		case ValueKindPrimitive:
 			switch a.Kind {
-			case "primitive":
+			case ValueKindPrimitive:
 				equivPrim, _, _ := valueEquivalentPrimitives(
 					a.Primitive, aa.Primitive, true,
 				)
@@ -230,7 +347,7 @@ func valueFindConstantInPrimitive(
 			if valueFindConstantInPrimitive(c, aa.Primitive, valPrincipalState) {
 				return true
 			}
-		case "equation":
+		case ValueKindEquation:
 			if valueFindConstantInEquation(c, aa.Equation, valPrincipalState) {
 				return true
 			}
@@ -244,19 +361,19 @@ func valueFindConstantInEquation(
 ) bool {
 	a := valueResolveConstant(c, valPrincipalState)
 	switch a.Kind {
-	case "equation":
+	case ValueKindEquation:
 		if valueEquivalentEquations(a.Equation, e) {
 			return true
 		}
 	}
 	for _, ee := range e.Values {
 		switch ee.Kind {
-		case "constant":
+		case ValueKindConstant:
 			if c.Name == ee.Constant.Name {
 				return true
 			}
 			switch a.Kind {
-			case "constant":
+			case ValueKindConstant:
 				if a.Constant.Name == ee.Constant.Name {
 					return true
 				}
@@ -266,6 +383,65 @@ func valueFindConstantInEquation(
 	return false
 }
 
+// valueHash returns a structural hash of a value, such that equivalent
+// values always share the same hash: the exponents of an equation and the
+// terms of an XOR are hashed regardless of their order, and the output
+// index of a primitive is taken into account.
+func valueHash(a Value) uint64 {
+	h := fnv.New64a()
+	switch a.Kind {
+	case ValueKindConstant:
+		h.Write([]byte{'c'})
+		h.Write([]byte(a.Constant.Name))
+	case ValueKindPrimitive:
+		if valueIsXor(a) {
+			terms := valueXorTerms(a)
+			switch len(terms) {
+			case 0:
+				return valueHash(valueN)
+			case 1:
+				return valueHash(terms[0])
+			}
+			h.Write([]byte{'x'})
+			valueHashWrite(h, valueHashSorted(terms)...)
+			break
+		}
+		h.Write([]byte{'p'})
+		h.Write([]byte(a.Primitive.Name))
+		valueHashWrite(h, uint64(a.Primitive.Output))
+		for _, aa := range a.Primitive.Arguments {
+			valueHashWrite(h, valueHash(aa))
+		}
+	case ValueKindEquation:
+		h.Write([]byte{'e'})
+		values := valueFlattenEquation(a.Equation).Values
+		if len(values) > 0 {
+			valueHashWrite(h, valueHash(values[0]))
+			valueHashWrite(h, valueHashSorted(values[1:])...)
+		}
+	}
+	return h.Sum64()
+}
+
+func valueHashSorted(a []Value) []uint64 {
+	hashes := make([]uint64, len(a))
+	for i, aa := range a {
+		hashes[i] = valueHash(aa)
+	}
+	sort.Slice(hashes, func(i int, ii int) bool {
+		return hashes[i] < hashes[ii]
+	})
+	return hashes
+}
+
+func valueHashWrite(h io.Writer, hashes ...uint64) {
+	b := make([]byte, 8)
+	for _, hh := range hashes {
+		binary.LittleEndian.PutUint64(b, hh)
+		h.Write(b)
+	}
+}
+
 func valueEquivalentValueInValues(v Value, a []Value) int {
 	index := -1
 	for i, aa := range a {
@@ -304,7 +480,7 @@ func valuePerformPrimitiveRewrite(
 			rewrites, rIndex, pi, rebuild, valPrincipalState,
 		)
 		switch rebuild.Kind {
-		case "constant", "equation":
+		case ValueKindConstant, ValueKindEquation:
 			return failedRewrites, rewritten, rewrites[rIndex]
 		}
 	}
@@ -330,9 +506,10 @@ func valuePerformPrimitiveArgumentsRewrite(
 	p Primitive, rIndex int, valPrincipalState PrincipalState,
 ) ([]Value, []Primitive, bool) {
 	rewrites := []Value{{
-		Kind: "primitive",
+		Kind: ValueKindPrimitive,
 		Primitive: Primitive{
 			Name:      p.Name,
+			ID:        p.ID,
 			Arguments: make([]Value, len(p.Arguments)),
 			Output:    p.Output,
 			Check:     p.Check,
@@ -342,9 +519,9 @@ func valuePerformPrimitiveArgumentsRewrite(
 	rewritten := false
 	for i, a := range p.Arguments {
 		switch a.Kind {
-		case "constant":
+		case ValueKindConstant:
 			rewrites[rIndex].Primitive.Arguments[i] = p.Arguments[i]
-		case "primitive":
+		case ValueKindPrimitive:
 			pFailedRewrite, pRewritten, pRewrite := valuePerformPrimitiveRewrite(
 				a.Primitive, -1, valPrincipalState,
 			)
@@ -355,7 +532,7 @@ func valuePerformPrimitiveArgumentsRewrite(
 			}
 			rewrites[rIndex].Primitive.Arguments[i] = p.Arguments[i]
 			failedRewrites = append(failedRewrites, pFailedRewrite...)
-		case "equation":
+		case ValueKindEquation:
 			eFailedRewrite, eRewritten, eRewrite := valuePerformEquationRewrite(
 				a.Equation, -1, valPrincipalState,
 			)
@@ -377,22 +554,22 @@ func valuePerformEquationRewrite(
 	rewritten := false
 	failedRewrites := []Primitive{}
 	rewrite := Value{
-		Kind: "equation",
+		Kind: ValueKindEquation,
 		Equation: Equation{
 			Values: []Value{},
 		},
 	}
 	for i, a := range e.Values {
 		switch a.Kind {
-		case "constant":
+		case ValueKindConstant:
 			rewrite.Equation.Values = append(rewrite.Equation.Values, a)
-		case "primitive":
+		case ValueKindPrimitive:
 			hasRule := false
 			if primitiveIsCorePrim(a.Primitive.Name) {
-				prim, _ := primitiveCoreGet(a.Primitive.Name)
+				prim, _ := primitiveCoreGet(a.Primitive)
 				hasRule = prim.HasRule
 			} else {
-				prim, _ := primitiveGet(a.Primitive.Name)
+				prim, _ := primitiveGet(a.Primitive)
 				hasRule = prim.Rewrite.HasRule
 			}
 			if !hasRule {
@@ -408,14 +585,14 @@ func valuePerformEquationRewrite(
 			}
 			rewritten = true
 			switch pRewrite.Kind {
-			case "constant":
+			case ValueKindConstant:
 				rewrite.Equation.Values = append(rewrite.Equation.Values, pRewrite)
-			case "primitive":
+			case ValueKindPrimitive:
 				rewrite.Equation.Values = append(rewrite.Equation.Values, pRewrite)
-			case "equation":
+			case ValueKindEquation:
 				rewrite.Equation.Values = append(rewrite.Equation.Values, pRewrite.Equation.Values...)
 			}
-		case "equation":
+		case ValueKindEquation:
 			eFailedRewrite, eRewritten, eRewrite := valuePerformEquationRewrite(
 				a.Equation, -1, valPrincipalState,
 			)
@@ -443,7 +620,7 @@ func valuePerformAllRewrites(valPrincipalState PrincipalState) ([]Primitive, []i
 	failedRewriteIndices := []int{}
 	for i := range valPrincipalState.Assigned {
 		switch valPrincipalState.Assigned[i].Kind {
-		case "primitive":
+		case ValueKindPrimitive:
 			failedRewrite, _, _ := valuePerformPrimitiveRewrite(
 				valPrincipalState.Assigned[i].Primitive, i, valPrincipalState,
 			)
@@ -454,7 +631,7 @@ func valuePerformAllRewrites(valPrincipalState PrincipalState) ([]Primitive, []i
 			for range failedRewrite {
 				failedRewriteIndices = append(failedRewriteIndices, i)
 			}
-		case "equation":
+		case ValueKindEquation:
 			failedRewrite, _, _ := valuePerformEquationRewrite(
 				valPrincipalState.Assigned[i].Equation, i, valPrincipalState,
 			)
@@ -489,7 +666,7 @@ func valueShouldResolveToBeforeMutate(i int, valPrincipalState PrincipalState) b
 func valueResolveConstant(c Constant, valPrincipalState PrincipalState) Value {
 	i := valueGetPrincipalStateIndexFromConstant(valPrincipalState, c)
 	if i < 0 {
-		return Value{Kind: "constant", Constant: c}
+		return Value{Kind: ValueKindConstant, Constant: c}
 	}
 	if valueShouldResolveToBeforeMutate(i, valPrincipalState) {
 		return valPrincipalState.BeforeMutate[i]
@@ -502,7 +679,7 @@ func valueResolveValueInternalValuesFromKnowledgeMap(
 ) (Value, []Value) {
 	var v []Value
 	switch a.Kind {
-	case "constant":
+	case ValueKindConstant:
 		if valueEquivalentValueInValues(a, v) < 0 {
 			v = append(v, a)
 		}
@@ -510,15 +687,15 @@ func valueResolveValueInternalValuesFromKnowledgeMap(
 		a = valKnowledgeMap.Assigned[i]
 	}
 	switch a.Kind {
-	case "constant":
+	case ValueKindConstant:
 		return valueResolveConstantInternalValuesFromKnowledgeMap(
 			a, v, valKnowledgeMap,
 		)
-	case "primitive":
+	case ValueKindPrimitive:
 		return valueResolvePrimitiveInternalValuesFromKnowledgeMap(
 			a, v, valKnowledgeMap,
 		)
-	case "equation":
+	case ValueKindEquation:
 		return valueResolveEquationInternalValuesFromKnowledgeMap(
 			a, v, valKnowledgeMap,
 		)
@@ -536,9 +713,10 @@ func valueResolvePrimitiveInternalValuesFromKnowledgeMap(
 	a Value, v []Value, valKnowledgeMap KnowledgeMap,
 ) (Value, []Value) {
 	r := Value{
-		Kind: "primitive",
+		Kind: ValueKindPrimitive,
 		Primitive: Primitive{
 			Name:      a.Primitive.Name,
+			ID:        a.Primitive.ID,
 			Arguments: []Value{},
 			Output:    a.Primitive.Output,
 			Check:     a.Primitive.Check,
@@ -560,7 +738,7 @@ func valueResolveEquationInternalValuesFromKnowledgeMap(
 	a Value, v []Value, valKnowledgeMap KnowledgeMap,
 ) (Value, []Value) {
 	r := Value{
-		Kind: "equation",
+		Kind: ValueKindEquation,
 		Equation: Equation{
 			Values: []Value{},
 		},
@@ -572,19 +750,19 @@ func valueResolveEquationInternalValuesFromKnowledgeMap(
 	}
 	for aai := range aa {
 		switch aa[aai].Kind {
-		case "constant":
+		case ValueKindConstant:
 			i := valueGetKnowledgeMapIndexFromConstant(valKnowledgeMap, aa[aai].Constant)
 			aa[aai] = valKnowledgeMap.Assigned[i]
 		}
 	}
 	for aai := range aa {
 		switch aa[aai].Kind {
-		case "constant":
+		case ValueKindConstant:
 			r.Equation.Values = append(r.Equation.Values, aa[aai])
-		case "primitive":
+		case ValueKindPrimitive:
 			aaa, _ := valueResolveValueInternalValuesFromKnowledgeMap(aa[aai], valKnowledgeMap)
 			r.Equation.Values = append(r.Equation.Values, aaa)
-		case "equation":
+		case ValueKindEquation:
 			aaa, _ := valueResolveValueInternalValuesFromKnowledgeMap(aa[aai], valKnowledgeMap)
 			r.Equation.Values = append(r.Equation.Values, aaa)
 			if aai == 0 {
@@ -610,7 +788,7 @@ func valueResolveValueInternalValuesFromPrincipalState(
 	valPrincipalState PrincipalState, valAttackerState AttackerState, forceBeforeMutate bool,
 ) Value {
 	switch a.Kind {
-	case "constant":
+	case ValueKindConstant:
 		nextRootIndex := valueGetPrincipalStateIndexFromConstant(valPrincipalState, a.Constant)
 		switch nextRootIndex {
 		case rootIndex:
@@ -624,7 +802,7 @@ func valueResolveValueInternalValuesFromPrincipalState(
 			}
 		default:
 			switch rootValue.Kind {
-			case "primitive":
+			case ValueKindPrimitive:
 				if valPrincipalState.Creator[rootIndex] != valPrincipalState.Name {
 					forceBeforeMutate = true
 				}
@@ -642,13 +820,13 @@ func valueResolveValueInternalValuesFromPrincipalState(
 		}
 	}
 	switch a.Kind {
-	case "constant":
+	case ValueKindConstant:
 		return a
-	case "primitive":
+	case ValueKindPrimitive:
 		return valueResolvePrimitiveInternalValuesFromPrincipalState(
 			a, rootValue, rootIndex, valPrincipalState, valAttackerState, forceBeforeMutate,
 		)
-	case "equation":
+	case ValueKindEquation:
 		return valueResolveEquationInternalValuesFromPrincipalState(
 			a, rootValue, rootIndex, valPrincipalState, valAttackerState, forceBeforeMutate,
 		)
@@ -664,9 +842,10 @@ func valueResolvePrimitiveInternalValuesFromPrincipalState(
 		forceBeforeMutate = false
 	}
 	r := Value{
-		Kind: "primitive",
+		Kind: ValueKindPrimitive,
 		Primitive: Primitive{
 			Name:      a.Primitive.Name,
+			ID:        a.Primitive.ID,
 			Arguments: []Value{},
 			Output:    a.Primitive.Output,
 			Check:     a.Primitive.Check,
@@ -689,7 +868,7 @@ func valueResolveEquationInternalValuesFromPrincipalState(
 		forceBeforeMutate = false
 	}
 	r := Value{
-		Kind: "equation",
+		Kind: ValueKindEquation,
 		Equation: Equation{
 			Values: []Value{},
 		},
@@ -698,7 +877,7 @@ func valueResolveEquationInternalValuesFromPrincipalState(
 	aa = append(aa, a.Equation.Values...)
 	for aai := range aa {
 		switch aa[aai].Kind {
-		case "constant":
+		case ValueKindConstant:
 			if forceBeforeMutate {
 				i := valueGetPrincipalStateIndexFromConstant(valPrincipalState, aa[aai].Constant)
 				aa[aai] = valPrincipalState.BeforeMutate[i]
@@ -709,15 +888,15 @@ func valueResolveEquationInternalValuesFromPrincipalState(
 	}
 	for aai := range aa {
 		switch aa[aai].Kind {
-		case "constant":
+		case ValueKindConstant:
 			r.Equation.Values = append(r.Equation.Values, aa[aai])
-		case "primitive":
+		case ValueKindPrimitive:
 			aaa := valueResolveValueInternalValuesFromPrincipalState(
 				aa[aai], rootValue, rootIndex,
 				valPrincipalState, valAttackerState, forceBeforeMutate,
 			)
 			r.Equation.Values = append(r.Equation.Values, aaa)
-		case "equation":
+		case ValueKindEquation:
 			aaa := valueResolveEquationInternalValuesFromPrincipalState(
 				aa[aai], rootValue, rootIndex,
 				valPrincipalState, valAttackerState, forceBeforeMutate,
@@ -741,12 +920,12 @@ func valueConstantIsUsedByPrincipalInKnowledgeMap(
 			continue
 		}
 		switch a.Kind {
-		case "primitive", "equation":
+		case ValueKindPrimitive, ValueKindEquation:
 			_, v := valueResolveValueInternalValuesFromKnowledgeMap(a, valKnowledgeMap)
 			if valueEquivalentValueInValues(valKnowledgeMap.Assigned[i], v) >= 0 {
 				return true
 			}
-			if valueEquivalentValueInValues(Value{Kind: "constant", Constant: c}, v) >= 0 {
+			if valueEquivalentValueInValues(Value{Kind: ValueKindConstant, Constant: c}, v) >= 0 {
 				return true
 			}
 		}
@@ -760,7 +939,7 @@ func valueResolveAllPrincipalStateValues(
 	valPrincipalStateClone := constructPrincipalStateClone(valPrincipalState, false)
 	for i := range valPrincipalState.Assigned {
 		switch valPrincipalStateClone.Assigned[i].Kind {
-		case "primitive":
+		case ValueKindPrimitive:
 			if valPrincipalStateClone.Assigned[i].Primitive.Name != "SPLIT" {
 				continue
 			}
@@ -782,7 +961,7 @@ func valueResolveAllPrincipalStateValues(
 	}
 	for i := range valPrincipalState.Assigned {
 		switch valPrincipalStateClone.Assigned[i].Kind {
-		case "primitive":
+		case ValueKindPrimitive:
 			if valPrincipalStateClone.Assigned[i].Primitive.Name == "SPLIT" {
 				continue
 			}
@@ -5,8 +5,8 @@
 package vplogic
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"sync"
 	"time"
 )
@@ -18,35 +18,57 @@ func Verify(filePath string) ([]VerifyResult, string, error) {
 	if err != nil {
 		return []VerifyResult{}, "", err
 	}
-	return verifyModel(m)
+	return VerifyModel(context.Background(), m)
 }
 
-func verifyModel(m Model) ([]VerifyResult, string, error) {
+// VerifyModel runs the main verification engine on a model that has already
+// been parsed. Verification stops early once ctx is done, in which case the
+// context's error is returned.
+func VerifyModel(ctx context.Context, m Model) ([]VerifyResult, string, error) {
 	valKnowledgeMap, valPrincipalStates, err := sanity(m)
 	if err != nil {
 		return []VerifyResult{}, "", err
 	}
 	initiated := time.Now().Format("03:04:05 PM")
 	verifyAnalysisCountInit()
-	verifyResultsInit(m)
+	verifyActivePruneInit()
+	verifyResultsInit(ctx, m)
 	InfoMessage(fmt.Sprintf(
 		"Verification initiated for '%s' at %s.", m.FileName, initiated,
 	), "verifpal", false)
+	if cachedResults, _, ok := cacheGet(m); ok {
+		InfoMessage("Results for this model were found in the cache.", "info", false)
+		verifyResultsPutCached(cachedResults)
+		return verifyEnd(m)
+	}
+	err = checkpointInit(m)
+	if err != nil {
+		return []VerifyResult{}, "", err
+	}
 	switch m.Attacker {
 	case "passive":
-		err := verifyPassive(valKnowledgeMap, valPrincipalStates)
+		err := verifyPassive(m, valKnowledgeMap, valPrincipalStates)
 		if err != nil {
 			return []VerifyResult{}, "", err
 		}
-	case "active":
-		err := verifyActive(valKnowledgeMap, valPrincipalStates)
+	case "active", "replay-only", "no-inject", "bounded-depth", "per-channel":
+		err := verifyActive(m, valKnowledgeMap, valPrincipalStates)
 		if err != nil {
 			return []VerifyResult{}, "", err
 		}
 	default:
 		return []VerifyResult{}, "", fmt.Errorf("invalid attacker (%s)", m.Attacker)
 	}
-	fmt.Fprint(os.Stdout, "\n\n")
+	if ctx.Err() != nil {
+		return []VerifyResult{}, "", ctx.Err()
+	}
+	valVerifyResults, _ := verifyResultsGetRead()
+	err = cachePut(m, valVerifyResults, verifyGetResultsCode(valVerifyResults))
+	if err != nil {
+		InfoMessage(fmt.Sprintf(
+			"Could not cache results (%v).", err,
+		), "warning", false)
+	}
 	return verifyEnd(m)
 }
 
@@ -55,7 +77,7 @@ func verifyResolveQueries(
 ) {
 	valVerifyResults, _ := verifyResultsGetRead()
 	for _, verifyResult := range valVerifyResults {
-		if !verifyResult.Resolved {
+		if !verifyResult.Resolved || VerifyExhaustiveShared {
 			queryStart(verifyResult.Query, valKnowledgeMap, valPrincipalState)
 		}
 	}
@@ -88,21 +110,28 @@ func verifyStandardRun(valKnowledgeMap KnowledgeMap, valPrincipalStates []Princi
 	return err
 }
 
-func verifyPassive(valKnowledgeMap KnowledgeMap, valPrincipalStates []PrincipalState) error {
+func verifyPassive(m Model, valKnowledgeMap KnowledgeMap, valPrincipalStates []PrincipalState) error {
 	InfoMessage("Attacker is configured as passive.", "info", false)
 	phase := 0
 	for phase <= valKnowledgeMap.MaxPhase {
-		attackerStateInit(false)
+		if checkpointSkip(phase, 1) {
+			phase = phase + 1
+			continue
+		}
+		eventEmit(EventPhaseStarted{Phase: phase, Active: false})
+		attackerStateInit(m, false)
 		err := attackerStatePutPhaseUpdate(valPrincipalStates[0], phase)
 		if err != nil {
 			return err
 		}
+		checkpointPutStage(phase, 0)
 		err = verifyStandardRun(valKnowledgeMap, valPrincipalStates, 0)
 		if err != nil {
 			return err
 		}
 		phase = phase + 1
 	}
+	checkpointPutStage(phase, 0)
 	return nil
 }
 
@@ -112,13 +141,13 @@ func verifyGetResultsCode(valVerifyResults []VerifyResult) string {
 		q := ""
 		r := ""
 		switch verifyResult.Query.Kind {
-		case "confidentiality":
+		case QueryKindConfidentiality:
 			q = "c"
-		case "authentication":
+		case QueryKindAuthentication:
 			q = "a"
-		case "freshness":
+		case QueryKindFreshness:
 			q = "f"
-		case "unlinkability":
+		case QueryKindUnlinkability:
 			q = "u"
 		}
 		switch verifyResult.Resolved {
@@ -138,21 +167,13 @@ func verifyGetResultsCode(valVerifyResults []VerifyResult) string {
 func verifyEnd(m Model) ([]VerifyResult, string, error) {
 	var err error
 	valVerifyResults, fileName := verifyResultsGetRead()
-	for _, verifyResult := range valVerifyResults {
-		if verifyResult.Resolved {
-			InfoMessage(fmt.Sprintf(
-				"%s: %s",
-				prettyQuery(verifyResult.Query),
-				verifyResult.Summary,
-			), "result", false)
-		}
-	}
-	completed := time.Now().Format("03:04:05 PM")
-	InfoMessage(fmt.Sprintf(
-		"Verification completed for '%s' at %s.", fileName, completed,
-	), "verifpal", false)
-	InfoMessage("Thank you for using Verifpal.", "verifpal", false)
 	resultsCode := verifyGetResultsCode(valVerifyResults)
+	eventEmit(EventVerificationFinished{
+		FileName:    fileName,
+		Results:     valVerifyResults,
+		ResultsCode: resultsCode,
+		Pruned:      verifyActivePruneCountGet(),
+	})
 	if VerifHubScheduledShared {
 		err = VerifHub(m, fileName, resultsCode)
 	}
@@ -7,28 +7,68 @@ package vplogic
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
-func verifyActive(valKnowledgeMap KnowledgeMap, valPrincipalStates []PrincipalState) error {
-	InfoMessage("Attacker is configured as active.", "info", false)
+// VerifyPruneShared is set when mutation combinations that lead to a
+// principal state equivalent to one already analyzed in the same stage are
+// to be skipped.
+var VerifyPruneShared = true
+
+// verifyActivePruneSeen holds every principal state that has been analyzed
+// after being mutated in the current stage, keyed by its hash, so that the
+// many mutation combinations that lead to the same principal state are only
+// analyzed once. Combinations that only differ in values that are dropped
+// after a failed check lead to the same principal state, and so are among
+// those skipped.
+var verifyActivePruneSeen = &sync.Map{}
+var verifyActivePruneCount uint32
+
+// verifyActivePruneBucket holds the principal states that share a hash, so
+// that a state is only skipped once it is found to be fully equivalent to
+// one of them, and never because of a hash collision alone.
+type verifyActivePruneBucket struct {
+	mutex  sync.Mutex
+	states []PrincipalState
+}
+
+func verifyActive(m Model, valKnowledgeMap KnowledgeMap, valPrincipalStates []PrincipalState) error {
+	switch m.Attacker {
+	case "active":
+		InfoMessage("Attacker is configured as active.", "info", false)
+	default:
+		InfoMessage(fmt.Sprintf(
+			"Attacker is configured as active (%s).", prettyAttacker(m),
+		), "info", false)
+	}
 	phase := 0
 	for phase <= valKnowledgeMap.MaxPhase {
-		InfoMessage(fmt.Sprintf("Running at phase %d.", phase), "info", false)
-		attackerStateInit(true)
+		if checkpointSkip(phase, 5) {
+			phase = phase + 1
+			continue
+		}
+		eventEmit(EventPhaseStarted{Phase: phase, Active: true})
+		attackerStateInit(m, true)
 		err := attackerStatePutPhaseUpdate(valPrincipalStates[0], phase)
 		if err != nil {
 			return err
 		}
-		err = verifyStandardRun(valKnowledgeMap, valPrincipalStates, 0)
-		if err != nil {
-			return err
+		checkpointResumeKnown(phase)
+		if !checkpointSkip(phase, 0) {
+			checkpointPutStage(phase, 0)
+			err = verifyStandardRun(valKnowledgeMap, valPrincipalStates, 0)
+			if err != nil {
+				return err
+			}
 		}
 		verifyActiveStages(valKnowledgeMap, valPrincipalStates, 1)
 		verifyActiveStages(valKnowledgeMap, valPrincipalStates, 2)
 		verifyActiveStages(valKnowledgeMap, valPrincipalStates, 3)
 		verifyActiveStages(valKnowledgeMap, valPrincipalStates, 4)
+		verifyReduceAttacks(valKnowledgeMap, valPrincipalStates)
 		phase = phase + 1
 	}
+	checkpointPutStage(phase, 0)
 	return nil
 }
 
@@ -37,18 +77,33 @@ func verifyActiveStages(
 	stage int,
 ) {
 	var principalsGroup sync.WaitGroup
+	phase := attackerStateGetRead().CurrentPhase
+	if checkpointSkip(phase, stage) {
+		return
+	}
+	eventEmit(EventStageStarted{Stage: stage})
+	checkpointPutStage(phase, stage)
+	verifyActivePruneSeen = &sync.Map{}
 	for _, valPrincipalState := range valPrincipalStates {
+		if checkpointSkipPrincipal(phase, stage, valPrincipalState.Name) {
+			continue
+		}
+		valMutationMap, resumed := checkpointResumeScan(phase, stage, valPrincipalState.Name)
+		if !resumed {
+			valMutationMap = MutationMap{Initialized: false}
+		}
 		principalsGroup.Add(1)
-		go func(valPrincipalState PrincipalState, pg *sync.WaitGroup) {
+		go func(valPrincipalState PrincipalState, valMutationMap MutationMap, pg *sync.WaitGroup) {
 			var combinationsGroup sync.WaitGroup
 			combinationsGroup.Add(1)
 			verifyActiveScan(
-				valKnowledgeMap, valPrincipalState, MutationMap{Initialized: false},
+				valKnowledgeMap, valPrincipalState, valMutationMap,
 				stage, &combinationsGroup,
 			)
 			combinationsGroup.Wait()
+			checkpointPutPrincipal(valPrincipalState.Name)
 			pg.Done()
-		}(valPrincipalState, &principalsGroup)
+		}(valPrincipalState, valMutationMap, &principalsGroup)
 	}
 	principalsGroup.Wait()
 }
@@ -59,6 +114,7 @@ func verifyActiveScan(
 ) {
 	var scanGroup sync.WaitGroup
 	if verifyResultsAllResolved() {
+		checkpointScanFinish(valPrincipalState.Name, valMutationMap)
 		cg.Done()
 		return
 	}
@@ -75,7 +131,8 @@ func verifyActiveScan(
 			valKnowledgeMap, valPrincipalState, valAttackerState, stage,
 		)
 		verifyActiveScan(
-			valKnowledgeMap, valPrincipalState, mutationMapNext(valMutationMap),
+			valKnowledgeMap, valPrincipalState,
+			checkpointScanStart(valPrincipalState.Name, mutationMapNext(valMutationMap)),
 			stage, cg,
 		)
 		cg.Done()
@@ -85,6 +142,9 @@ func verifyActiveScan(
 		valKnowledgeMap, constructPrincipalStateClone(valPrincipalState, true),
 		valAttackerState, valMutationMap,
 	)
+	if isWorthwhileMutation && verifyActivePrune(valPrincipalStateMutated) {
+		isWorthwhileMutation = false
+	}
 	if isWorthwhileMutation {
 		scanGroup.Add(1)
 		go verifyAnalysis(valKnowledgeMap, valPrincipalStateMutated, stage, &scanGroup)
@@ -92,11 +152,13 @@ func verifyActiveScan(
 	if goodLock && !valMutationMap.OutOfMutations {
 		cg.Add(1)
 		go verifyActiveScan(
-			valKnowledgeMap, valPrincipalState, mutationMapNext(valMutationMap),
+			valKnowledgeMap, valPrincipalState,
+			checkpointScanStart(valPrincipalState.Name, mutationMapNext(valMutationMap)),
 			stage, cg,
 		)
 	}
 	scanGroup.Wait()
+	checkpointScanFinish(valPrincipalState.Name, valMutationMap)
 	cg.Done()
 }
 
@@ -105,6 +167,7 @@ func verifyActiveMutatePrincipalState(
 	valAttackerState AttackerState, valMutationMap MutationMap,
 ) (PrincipalState, bool) {
 	isWorthwhileMutation := false
+	valPrincipalState.AttackerKnown = len(valAttackerState.Known)
 	for i, c := range valMutationMap.Constants {
 		ii := valueGetPrincipalStateIndexFromConstant(valPrincipalState, c)
 		ac := valMutationMap.Combination[i]
@@ -116,7 +179,7 @@ func verifyActiveMutatePrincipalState(
 			continue
 		}
 		switch ar.Kind {
-		case "primitive":
+		case ValueKindPrimitive:
 			ac.Primitive.Output = ar.Primitive.Output
 			ac.Primitive.Check = ar.Primitive.Check
 		}
@@ -160,6 +223,39 @@ FailedRewritesLoop:
 	return valPrincipalState, isWorthwhileMutation
 }
 
+// verifyActivePrune tells us whether a principal state equivalent to
+// valPrincipalState has already been analyzed in this stage, and otherwise
+// records a copy of valPrincipalState, which its analysis goes on to write
+// to, as analyzed.
+func verifyActivePrune(valPrincipalState PrincipalState) bool {
+	if !VerifyPruneShared {
+		return false
+	}
+	b, _ := verifyActivePruneSeen.LoadOrStore(
+		principalStateHash(valPrincipalState), &verifyActivePruneBucket{},
+	)
+	bucket := b.(*verifyActivePruneBucket)
+	bucket.mutex.Lock()
+	defer bucket.mutex.Unlock()
+	for _, s := range bucket.states {
+		if principalStateEquivalent(s, valPrincipalState) {
+			atomic.AddUint32(&verifyActivePruneCount, 1)
+			return true
+		}
+	}
+	bucket.states = append(bucket.states, constructPrincipalStateClone(valPrincipalState, false))
+	return false
+}
+
+func verifyActivePruneInit() {
+	verifyActivePruneSeen = &sync.Map{}
+	atomic.StoreUint32(&verifyActivePruneCount, 0)
+}
+
+func verifyActivePruneCountGet() int {
+	return int(atomic.LoadUint32(&verifyActivePruneCount))
+}
+
 func verifyActiveDropPrincipalStateAfterIndex(valPrincipalState PrincipalState, f int) PrincipalState {
 	valPrincipalState.Constants = valPrincipalState.Constants[:f]
 	valPrincipalState.Assigned = valPrincipalState.Assigned[:f]
@@ -173,5 +269,6 @@ func verifyActiveDropPrincipalStateAfterIndex(valPrincipalState PrincipalState,
 	valPrincipalState.Mutated = valPrincipalState.Mutated[:f]
 	valPrincipalState.BeforeMutate = valPrincipalState.BeforeMutate[:f]
 	valPrincipalState.Phase = valPrincipalState.Phase[:f]
+	valPrincipalState.Confidential = valPrincipalState.Confidential[:f]
 	return valPrincipalState
 }
@@ -51,6 +51,10 @@ func verifyAnalysisCountGet() int {
 	return int(atomic.LoadUint32(&verifyAnalysisCount))
 }
 
+func verifyAnalysisCountSet(analysisCount int) {
+	atomic.StoreUint32(&verifyAnalysisCount, uint32(analysisCount))
+}
+
 func verifyAnalysisDecompose(
 	a Value, valAttackerState AttackerState, o int,
 ) int {
@@ -58,7 +62,7 @@ func verifyAnalysisDecompose(
 	revealed := Value{}
 	ar := []Value{}
 	switch a.Kind {
-	case "primitive":
+	case ValueKindPrimitive:
 		r, revealed, ar = possibleToDecomposePrimitive(a.Primitive, valAttackerState)
 	}
 	if r && attackerStatePutWrite(revealed) {
@@ -78,7 +82,7 @@ func verifyAnalysisRecompose(
 	revealed := Value{}
 	ar := []Value{}
 	switch a.Kind {
-	case "primitive":
+	case ValueKindPrimitive:
 		r, revealed, ar = possibleToRecomposePrimitive(a.Primitive, valAttackerState)
 	}
 	if r && attackerStatePutWrite(revealed) {
@@ -98,13 +102,18 @@ func verifyAnalysisReconstruct(
 	ar := []Value{}
 	isCorePrim := false
 	switch a.Kind {
-	case "primitive":
+	case ValueKindPrimitive:
 		isCorePrim = primitiveIsCorePrim(a.Primitive.Name)
 		r, ar = possibleToReconstructPrimitive(a.Primitive, valAttackerState)
 		for _, aa := range a.Primitive.Arguments {
 			verifyAnalysisReconstruct(aa, valPrincipalState, valAttackerState, o)
 		}
-	case "equation":
+		if r && valueIsXor(a) {
+			if terms := valueXorTerms(a); len(terms) == 1 {
+				a = terms[0]
+			}
+		}
+	case ValueKindEquation:
 		r, ar = possibleToReconstructEquation(a.Equation, valAttackerState)
 	}
 	if r && !isCorePrim && attackerStatePutWrite(a) {
@@ -119,7 +128,7 @@ func verifyAnalysisReconstruct(
 
 func verifyAnalysisEquivalize(a Value, valPrincipalState PrincipalState, o int) int {
 	switch a.Kind {
-	case "constant":
+	case ValueKindConstant:
 		a = valueResolveConstant(a.Constant, valPrincipalState)
 	}
 	for _, aa := range valPrincipalState.Assigned {
@@ -148,7 +157,7 @@ func verifyAnalysisPasswords(a Value, valPrincipalState PrincipalState, o int) i
 
 func verifyAnalysisConcat(a Value, o int) int {
 	switch a.Kind {
-	case "primitive":
+	case ValueKindPrimitive:
 		switch a.Primitive.Name {
 		case "CONCAT":
 			for _, revealed := range a.Primitive.Arguments {
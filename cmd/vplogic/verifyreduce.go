@@ -0,0 +1,149 @@
+/* SPDX-FileCopyrightText: © 2019-2020 Nadim Kobeissi <nadim@symbolic.software>
+ * SPDX-License-Identifier: GPL-3.0-only */
+// 00000000000000000000000000000000
+
+package vplogic
+
+import (
+	"sync"
+)
+
+// verifyReduceAttacks looks for a smaller set of mutations that still leads
+// to each attack found during the current phase, by replaying the analysis
+// of the attacked principal's state with one mutation dropped at a time.
+// Replaying swaps out the shared attacker state, results and analysis
+// count, so this must only run once every analysis of the phase has
+// completed.
+func verifyReduceAttacks(valKnowledgeMap KnowledgeMap, valPrincipalStates []PrincipalState) {
+	if verifyResultsContextShared.Err() != nil {
+		return
+	}
+	valVerifyResults, _ := verifyResultsGetRead()
+	for i, verifyResult := range valVerifyResults {
+		reduced := false
+		attacks := make([]QueryAttack, len(verifyResult.Attacks))
+		copy(attacks, verifyResult.Attacks)
+		for ii := range attacks {
+			if attacks[ii].reduced {
+				continue
+			}
+			attacks[ii] = verifyReduceAttack(
+				valKnowledgeMap, valPrincipalStates, verifyResult.Query, attacks[ii],
+			)
+			reduced = true
+		}
+		if reduced {
+			verifyResultsPutAttacks(i, attacks)
+		}
+	}
+}
+
+func verifyReduceAttack(
+	valKnowledgeMap KnowledgeMap, valPrincipalStates []PrincipalState,
+	query Query, attack QueryAttack,
+) QueryAttack {
+	attack.reduced = true
+	valPrincipalState := attack.state
+	valPrincipalStateBase := PrincipalState{}
+	for _, state := range valPrincipalStates {
+		if state.Name == valPrincipalState.Name {
+			valPrincipalStateBase = state
+		}
+	}
+	indices := []int{}
+	for i := range valPrincipalState.Constants {
+		if valPrincipalState.Mutated[i] {
+			indices = append(indices, i)
+		}
+	}
+	if !verifyReduceReplay(
+		valKnowledgeMap, valPrincipalStateBase, valPrincipalState, query, indices,
+	) {
+		return attack
+	}
+	minimal := indices
+	for i := 0; i < len(minimal); {
+		candidate := append(append([]int{}, minimal[:i]...), minimal[i+1:]...)
+		if verifyReduceReplay(
+			valKnowledgeMap, valPrincipalStateBase, valPrincipalState, query, candidate,
+		) {
+			minimal = candidate
+		} else {
+			i = i + 1
+		}
+	}
+	if len(minimal) == len(indices) {
+		return attack
+	}
+	for _, i := range minimal {
+		attack.Minimal = append(attack.Minimal, queryGetMutatedValue(valPrincipalState, i))
+	}
+	attack.Summary = infoVerifyResultSummaryMinimal(attack.Summary, attack.Minimal)
+	return attack
+}
+
+// verifyReduceReplay tells us whether applying only the mutations at the
+// given indices of valPrincipalState to valPrincipalStateBase still lets
+// Attacker contradict the query, starting from what Attacker knew when the
+// attack was first found.
+func verifyReduceReplay(
+	valKnowledgeMap KnowledgeMap, valPrincipalStateBase PrincipalState,
+	valPrincipalState PrincipalState, query Query, indices []int,
+) bool {
+	var scanGroup sync.WaitGroup
+	valMutationMap := MutationMap{
+		Initialized: true,
+		Constants:   []Constant{},
+		Mutations:   [][]Value{},
+		Combination: []Value{},
+		DepthIndex:  []int{},
+	}
+	for _, i := range indices {
+		valMutationMap.Constants = append(valMutationMap.Constants, valPrincipalState.Constants[i])
+		valMutationMap.Combination = append(valMutationMap.Combination, valPrincipalState.BeforeRewrite[i])
+	}
+	valAttackerState := attackerStateGetRead()
+	valAttackerState.Known = make([]Value, valPrincipalState.AttackerKnown)
+	copy(valAttackerState.Known, attackerStateGetRead().Known)
+	valAttackerState.knownIndex = attackerStateKnownIndexBuild(valAttackerState.Known)
+	attackerStateMutex.Lock()
+	attackerStateSaved := attackerStateShared
+	attackerStateShared = valAttackerState
+	attackerStateMutex.Unlock()
+	verifyResultsMutex.Lock()
+	verifyResultsSaved := verifyResultsShared
+	verifyResultsShared = make([]VerifyResult, len(verifyResultsSaved))
+	for i, verifyResult := range verifyResultsSaved {
+		verifyResultsShared[i] = VerifyResult{
+			Query:    verifyResult.Query,
+			Resolved: i != query.ID,
+			Summary:  "",
+			Options:  []QueryOptionResult{},
+			Attacks:  []QueryAttack{},
+		}
+	}
+	verifyResultsMutex.Unlock()
+	eventSinkSaved := eventSinkShared
+	eventSinkShared = eventSinkDiscard{}
+	exhaustiveSaved := VerifyExhaustiveShared
+	VerifyExhaustiveShared = false
+	analysisCountSaved := verifyAnalysisCountGet()
+	valPrincipalStateMutated, _ := verifyActiveMutatePrincipalState(
+		valKnowledgeMap, constructPrincipalStateClone(valPrincipalStateBase, true),
+		valAttackerState, valMutationMap,
+	)
+	scanGroup.Add(1)
+	verifyAnalysis(valKnowledgeMap, valPrincipalStateMutated, 0, &scanGroup)
+	scanGroup.Wait()
+	valVerifyResults, _ := verifyResultsGetRead()
+	verifyAnalysisCountSet(analysisCountSaved)
+	VerifyExhaustiveShared = exhaustiveSaved
+	eventSinkShared = eventSinkSaved
+	verifyResultsMutex.Lock()
+	verifyResultsShared = verifyResultsSaved
+	verifyResultsMutex.Unlock()
+	attackerStateMutex.Lock()
+	attackerStateShared = attackerStateSaved
+	attackerStateMutex.Unlock()
+	return valVerifyResults[query.ID].Resolved
+}
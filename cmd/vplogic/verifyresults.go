@@ -5,15 +5,25 @@
 package vplogic
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 )
 
 var verifyResultsShared []VerifyResult
 var verifyResultsFileNameShared string
+var verifyResultsContextShared = context.Background()
 var verifyResultsMutex sync.Mutex
 
-func verifyResultsInit(m Model) bool {
+// VerifyExhaustiveShared keeps the analysis going after queries are
+// resolved, so that every distinct attack on each query is recorded.
+var VerifyExhaustiveShared bool
+
+func verifyResultsInit(ctx context.Context, m Model) bool {
 	verifyResultsMutex.Lock()
+	verifyResultsContextShared = ctx
 	verifyResultsShared = make([]VerifyResult, len(m.Queries))
 	for i, q := range m.Queries {
 		verifyResultsShared[i] = VerifyResult{
@@ -21,6 +31,7 @@ func verifyResultsInit(m Model) bool {
 			Resolved: false,
 			Summary:  "",
 			Options:  []QueryOptionResult{},
+			Attacks:  []QueryAttack{},
 		}
 	}
 	verifyResultsFileNameShared = m.FileName
@@ -39,23 +50,88 @@ func verifyResultsGetRead() ([]VerifyResult, string) {
 
 func verifyResultsPutWrite(result VerifyResult) bool {
 	written := false
-	qw := prettyQuery(result.Query)
 	verifyResultsMutex.Lock()
-	for i, verifyResult := range verifyResultsShared {
-		qv := prettyQuery(verifyResult.Query)
-		if qw == qv && !verifyResultsShared[i].Resolved {
-			verifyResultsShared[i].Resolved = result.Resolved
-			verifyResultsShared[i].Summary = result.Summary
-			written = true
-		}
+	i := result.Query.ID
+	if !verifyResultsShared[i].Resolved {
+		verifyResultsShared[i].Resolved = result.Resolved
+		verifyResultsShared[i].Summary = result.Summary
+		verifyResultsShared[i].Attacks = result.Attacks
+		written = true
+	} else if VerifyExhaustiveShared {
+		verifyResultsShared[i].Attacks = verifyResultsAddAttacks(
+			verifyResultsShared[i].Attacks, result.Attacks,
+		)
+		verifyResultsShared[i].Summary = verifyResultsShared[i].Attacks[0].Summary
 	}
 	verifyResultsMutex.Unlock()
 	return written
 }
 
+func verifyResultsPutCached(valVerifyResults []VerifyResult) {
+	verifyResultsMutex.Lock()
+	verifyResultsShared = valVerifyResults
+	verifyResultsMutex.Unlock()
+}
+
+func verifyResultsPutAttacks(i int, attacks []QueryAttack) {
+	verifyResultsMutex.Lock()
+	verifyResultsShared[i].Attacks = attacks
+	verifyResultsShared[i].Summary = attacks[0].Summary
+	verifyResultsMutex.Unlock()
+}
+
+// verifyResultsAddAttacks adds the attacks that are not already known,
+// keeping them ranked from simplest to most complex: first by how many
+// values Attacker mutates, then by how long the attack is to describe.
+func verifyResultsAddAttacks(attacks []QueryAttack, added []QueryAttack) []QueryAttack {
+	for _, a := range added {
+		found := false
+		for _, aa := range attacks {
+			if verifyResultsAttackKey(a) == verifyResultsAttackKey(aa) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			attacks = append(attacks, a)
+		}
+	}
+	sort.SliceStable(attacks, func(i int, ii int) bool {
+		if len(attacks[i].Mutated) != len(attacks[ii].Mutated) {
+			return len(attacks[i].Mutated) < len(attacks[ii].Mutated)
+		}
+		ki := verifyResultsAttackKey(attacks[i])
+		kii := verifyResultsAttackKey(attacks[ii])
+		if len(ki) != len(kii) {
+			return len(ki) < len(kii)
+		}
+		return ki < kii
+	})
+	return attacks
+}
+
+func verifyResultsAttackKey(a QueryAttack) string {
+	mutated := make([]string, len(a.Mutated))
+	copy(mutated, a.Mutated)
+	sort.Strings(mutated)
+	return fmt.Sprintf("%s\n%s", strings.Join(mutated, "\n"), a.Derivation)
+}
+
+// verifyResultsAllResolved also reports true once verification has been
+// cancelled, so that every search which stops early on resolved queries
+// stops on cancellation as well. In exhaustive mode, it otherwise always
+// reports false, so that the search carries on to find further attacks.
 func verifyResultsAllResolved() bool {
 	allResolved := true
 	verifyResultsMutex.Lock()
+	if verifyResultsContextShared.Err() != nil {
+		verifyResultsMutex.Unlock()
+		return true
+	}
+	if VerifyExhaustiveShared {
+		verifyResultsMutex.Unlock()
+		return false
+	}
 	for _, verifyResult := range verifyResultsShared {
 		if !verifyResult.Resolved {
 			allResolved = false
@@ -260,6 +260,37 @@ func DEC(k []byte, ciphertext []byte) ([]byte, error) {
 	return plaintext, nil
 }
 
+func STREAM_ENC(k []byte, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return []byte{}, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	_, err = rand.Read(iv)
+	if err != nil {
+		return []byte{}, err
+	}
+	stream := cipher.NewCTR(block, iv)
+	ciphertext := make([]byte, len(plaintext))
+	stream.XORKeyStream(ciphertext, plaintext)
+	return append(iv, ciphertext...), nil
+}
+
+func STREAM_DEC(k []byte, ciphertext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return []byte{}, err
+	}
+	if len(ciphertext) < aes.BlockSize {
+		return []byte{}, fmt.Errorf("invalid ciphertext")
+	}
+	iv := ciphertext[:aes.BlockSize]
+	stream := cipher.NewCTR(block, iv)
+	plaintext := make([]byte, len(ciphertext[aes.BlockSize:]))
+	stream.XORKeyStream(plaintext, ciphertext[aes.BlockSize:])
+	return plaintext, nil
+}
+
 func AEAD_ENC(k []byte, plaintext []byte, ad []byte) ([]byte, error) {
 	nonce := make([]byte, chacha20poly1305.NonceSizeX)
 	_, err := rand.Read(nonce)
@@ -313,6 +344,33 @@ func PKE_DEC(k []byte, ciphertext []byte) ([]byte, error) {
 	return plaintext, err
 }
 
+func KEM_ENCAPS(pk []byte, r ...[]byte) ([]byte, []byte, error) {
+	esk, epk, err := ed25519Gen()
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+	if len(r) > 0 {
+		k := ed25519.NewKeyFromSeed(HASH(r[0]))
+		esk, epk = k, k.Public().(ed25519.PublicKey)
+	}
+	ss, err := x25519DhFromEd25519PublicKey(esk, pk)
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+	return epk, HASH(epk, ss), nil
+}
+
+func KEM_DECAPS(k []byte, ct []byte) ([]byte, error) {
+	if len(ct) != 32 {
+		return []byte{}, fmt.Errorf("invalid ciphertext")
+	}
+	ss, err := x25519DhFromEd25519PublicKey(k, ct)
+	if err != nil {
+		return []byte{}, err
+	}
+	return HASH(ct, ss), nil
+}
+
 func SIGN(k []byte, message []byte) []byte {
 	return ed25519.Sign(k, message)
 }
@@ -321,11 +379,19 @@ func SIGNVERIF(pk []byte, message []byte, signature []byte) bool {
 	return ed25519.Verify(pk, message, signature)
 }
 
-func RINGSIGN(ka []byte, kb []byte, kc []byte, message []byte) []byte {
+func RINGSIGN(k []byte, a ...[]byte) []byte {
 	return []byte{}
 }
 
-func RINGSIGNVERIF(pka []byte, pkb []byte, pkc []byte, message []byte, signature []byte) bool {
+func RINGSIGNVERIF(a ...[]byte) bool {
+	return false
+}
+
+func THRESHOLD_SIGN(share []byte, message []byte) []byte {
+	return []byte{}
+}
+
+func THRESHOLD_VERIF(pk []byte, message []byte, signatures ...[]byte) bool {
 	return false
 }
 
@@ -337,11 +403,46 @@ func UNBLIND(k []byte, message []byte, signature []byte) []byte {
 	return []byte{}
 }
 
-func SHAMIR_SPLIT(x []byte) []byte {
+func COMMIT(value []byte, randomness []byte) ([]byte, error) {
+	return MAC(randomness, value)
+}
+
+func OPEN(commitment []byte, value []byte, randomness []byte) ([]byte, error) {
+	c, err := COMMIT(value, randomness)
+	if err != nil {
+		return []byte{}, err
+	}
+	if !hmac.Equal(commitment, c) {
+		return []byte{}, fmt.Errorf("commitment opening failed")
+	}
+	return value, nil
+}
+
+func ZKP_PROVE(witness []byte, statement []byte) []byte {
+	return []byte{}
+}
+
+func ZKP_VERIFY(proof []byte, statement []byte) bool {
+	return false
+}
+
+func XOR(a []byte, b []byte) []byte {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	c := make([]byte, len(a))
+	copy(c, a)
+	for i := range b {
+		c[i] = c[i] ^ b[i]
+	}
+	return c
+}
+
+func SHAMIR_SPLIT(x []byte, t ...[]byte) []byte {
 	return []byte{}
 }
 
-func SHAMIR_JOIN(a []byte, b []byte, c []byte) []byte {
+func SHAMIR_JOIN(shares ...[]byte) []byte {
 	return []byte{}
 }
 
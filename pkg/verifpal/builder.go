@@ -0,0 +1,253 @@
+/* SPDX-FileCopyrightText: © 2019-2020 Nadim Kobeissi <nadim@symbolic.software>
+ * SPDX-License-Identifier: GPL-3.0-only */
+// 00000000000000000000000000000000
+
+package verifpal
+
+import (
+	"strings"
+
+	"verifpal.com/cmd/vplogic"
+)
+
+// G is the generator used as the base of Diffie-Hellman equations.
+var G = Const("g")
+
+// Nil is the empty value.
+var Nil = Const("nil")
+
+// Const returns a reference to a constant.
+func Const(name string) Value {
+	return Value{value: vplogic.Value{
+		Kind: vplogic.ValueKindConstant,
+		Constant: vplogic.Constant{
+			Name: strings.ToLower(name),
+		},
+	}}
+}
+
+// Prim returns a primitive applied to the given arguments, such as
+// Prim("HASH", Const("a"), Const("b")) for HASH(a, b).
+func Prim(name string, arguments ...Value) Value {
+	return Value{value: vplogic.Value{
+		Kind: vplogic.ValueKindPrimitive,
+		Primitive: vplogic.Primitive{
+			Name:      strings.ToUpper(name),
+			ID:        vplogic.PrimitiveID(strings.ToUpper(name)),
+			Arguments: builderValues(arguments),
+			Output:    0,
+			Check:     false,
+		},
+	}}
+}
+
+// Checked marks a primitive as checked, as with the trailing question mark
+// in SIGNVERIF(pk, m, s)?, so that the principal aborts if it fails.
+func Checked(p Value) Value {
+	p.value.Primitive.Check = true
+	return p
+}
+
+// Exp returns an exponentiation such as G^a or G^a^b.
+func Exp(base Value, exponents ...Value) Value {
+	return Value{value: vplogic.Value{
+		Kind: vplogic.ValueKindEquation,
+		Equation: vplogic.Equation{
+			Values: builderValues(append([]Value{base}, exponents...)),
+		},
+	}}
+}
+
+// Builder assembles a model block by block, in the order in which its
+// principals act and exchange messages.
+type Builder struct {
+	model vplogic.Model
+}
+
+// PrincipalBuilder adds expressions to a single principal block.
+type PrincipalBuilder struct {
+	b     *Builder
+	block int
+}
+
+// NewBuilder starts a model analyzed against the given attacker, such as
+// "active" or "passive".
+func NewBuilder(attacker string) *Builder {
+	return &Builder{
+		model: vplogic.Model{
+			FileName: "model.vp",
+			Attacker: attacker,
+			Blocks:   []vplogic.Block{},
+			Queries:  []vplogic.Query{},
+		},
+	}
+}
+
+// Principal starts a new block for a principal. As in model files, the
+// same principal may have several blocks between messages.
+func (b *Builder) Principal(name string) *PrincipalBuilder {
+	b.model.Blocks = append(b.model.Blocks, vplogic.Block{
+		Kind: vplogic.BlockKindPrincipal,
+		Principal: vplogic.Principal{
+			Name:        builderPrincipalName(name),
+			Expressions: []vplogic.Expression{},
+		},
+	})
+	return &PrincipalBuilder{b: b, block: len(b.model.Blocks) - 1}
+}
+
+func (p *PrincipalBuilder) add(e vplogic.Expression) *PrincipalBuilder {
+	block := &p.b.model.Blocks[p.block]
+	block.Principal.Expressions = append(block.Principal.Expressions, e)
+	return p
+}
+
+// Knows declares constants that the principal knows in advance, with a
+// qualifier of "public", "private" or "password".
+func (p *PrincipalBuilder) Knows(qualifier string, names ...string) *PrincipalBuilder {
+	return p.add(vplogic.Expression{
+		Kind:      vplogic.ExpressionKindKnows,
+		Qualifier: qualifier,
+		Constants: builderConstants(names),
+	})
+}
+
+// Generates declares fresh values generated by the principal.
+func (p *PrincipalBuilder) Generates(names ...string) *PrincipalBuilder {
+	return p.add(vplogic.Expression{
+		Kind:      vplogic.ExpressionKindGenerates,
+		Constants: builderConstants(names),
+	})
+}
+
+// Leaks reveals constants known to the principal to the attacker.
+func (p *PrincipalBuilder) Leaks(names ...string) *PrincipalBuilder {
+	return p.add(vplogic.Expression{
+		Kind:      vplogic.ExpressionKindLeaks,
+		Constants: builderConstants(names),
+	})
+}
+
+// Assign assigns a value to a constant, as in ga = G^a.
+func (p *PrincipalBuilder) Assign(name string, right Value) *PrincipalBuilder {
+	return p.AssignOutputs([]string{name}, right)
+}
+
+// AssignOutputs assigns each output of a primitive with several outputs,
+// such as HKDF or SPLIT, to its own constant. Outputs may be left unnamed
+// by passing "_".
+func (p *PrincipalBuilder) AssignOutputs(names []string, right Value) *PrincipalBuilder {
+	return p.add(vplogic.Expression{
+		Kind:  vplogic.ExpressionKindAssignment,
+		Left:  builderConstants(names),
+		Right: right.value,
+	})
+}
+
+// Send sends constants from one principal to another. A constant written
+// within brackets, such as "[ga]", is guarded against being tampered with.
+func (b *Builder) Send(sender string, recipient string, names ...string) *Builder {
+	constants := []vplogic.Constant{}
+	for _, name := range names {
+		guard := strings.HasPrefix(name, "[") && strings.HasSuffix(name, "]")
+		constants = append(constants, vplogic.Constant{
+			Name:  strings.ToLower(strings.Trim(name, "[]")),
+			Guard: guard,
+		})
+	}
+	recipient = builderPrincipalName(recipient)
+	b.model.Blocks = append(b.model.Blocks, vplogic.Block{
+		Kind: vplogic.BlockKindMessage,
+		Message: vplogic.Message{
+			Sender:     builderPrincipalName(sender),
+			Recipient:  recipient,
+			Recipients: []string{recipient},
+			Constants:  constants,
+		},
+	})
+	return b
+}
+
+// Phase starts a new phase, after which the attacker may learn values that
+// were secret during earlier phases.
+func (b *Builder) Phase(number int) *Builder {
+	b.model.Blocks = append(b.model.Blocks, vplogic.Block{
+		Kind:  vplogic.BlockKindPhase,
+		Phase: vplogic.Phase{Number: number},
+	})
+	return b
+}
+
+// Query adds a query to the model.
+func (b *Builder) Query(q Query) *Builder {
+	query := convertQueryToEngine(q)
+	query.ID = len(b.model.Queries)
+	b.model.Queries = append(b.model.Queries, query)
+	return b
+}
+
+// Confidentiality asks whether the attacker can obtain a constant.
+func (b *Builder) Confidentiality(name string) *Builder {
+	return b.Query(Query{
+		Kind:      QueryKindConfidentiality,
+		Constants: []string{name},
+	})
+}
+
+// Authentication asks whether a recipient can be made to use a constant
+// that was not sent by the given sender.
+func (b *Builder) Authentication(sender string, recipient string, name string) *Builder {
+	return b.Query(Query{
+		Kind:      QueryKindAuthentication,
+		Constants: []string{name},
+		Sender:    sender,
+		Recipient: recipient,
+	})
+}
+
+// Freshness asks whether a constant is derived from a fresh value.
+func (b *Builder) Freshness(name string) *Builder {
+	return b.Query(Query{
+		Kind:      QueryKindFreshness,
+		Constants: []string{name},
+	})
+}
+
+// Unlinkability asks whether the attacker can link constants together.
+func (b *Builder) Unlinkability(names ...string) *Builder {
+	return b.Query(Query{
+		Kind:      QueryKindUnlinkability,
+		Constants: names,
+	})
+}
+
+// Build returns the assembled model. The model is formatted and parsed
+// back, so that it is checked exactly as the same model read from a file
+// would be.
+func (b *Builder) Build() (Model, error) {
+	src, err := vplogic.PrettyModel(b.model)
+	if err != nil {
+		return Model{}, err
+	}
+	return Parse([]byte(src))
+}
+
+func builderConstants(names []string) []vplogic.Constant {
+	constants := make([]vplogic.Constant, len(names))
+	for i, name := range names {
+		constants[i] = vplogic.Constant{Name: strings.ToLower(name)}
+	}
+	return constants
+}
+
+func builderValues(values []Value) []vplogic.Value {
+	v := make([]vplogic.Value, len(values))
+	for i, value := range values {
+		v[i] = value.value
+	}
+	return v
+}
+
+func builderPrincipalName(name string) string {
+	return strings.Title(strings.ToLower(name))
+}
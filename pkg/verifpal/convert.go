@@ -0,0 +1,157 @@
+/* SPDX-FileCopyrightText: © 2019-2020 Nadim Kobeissi <nadim@symbolic.software>
+ * SPDX-License-Identifier: GPL-3.0-only */
+// 00000000000000000000000000000000
+
+package verifpal
+
+import (
+	"verifpal.com/cmd/vplogic"
+)
+
+// The types of this package are converted from and to those of the
+// verification engine at its boundary, so that the engine's own types may
+// change without breaking programs that use this package.
+
+var convertQueryKinds = map[vplogic.QueryKind]QueryKind{
+	vplogic.QueryKindConfidentiality: QueryKindConfidentiality,
+	vplogic.QueryKindAuthentication:  QueryKindAuthentication,
+	vplogic.QueryKindFreshness:       QueryKindFreshness,
+	vplogic.QueryKindUnlinkability:   QueryKindUnlinkability,
+}
+
+func convertQuery(q vplogic.Query) Query {
+	query := Query{
+		Kind:      convertQueryKinds[q.Kind],
+		Constants: []string{},
+	}
+	constants := q.Constants
+	if q.Kind == vplogic.QueryKindAuthentication {
+		constants = q.Message.Constants
+		query.Sender = q.Message.Sender
+		query.Recipient = q.Message.Recipient
+	}
+	for _, c := range constants {
+		query.Constants = append(query.Constants, c.Name)
+	}
+	return query
+}
+
+func convertQueryToEngine(q Query) vplogic.Query {
+	query := vplogic.Query{
+		Constants: []vplogic.Constant{},
+		Options:   []vplogic.QueryOption{},
+	}
+	for k, kk := range convertQueryKinds {
+		if kk == q.Kind {
+			query.Kind = k
+		}
+	}
+	if q.Kind != QueryKindAuthentication {
+		query.Constants = builderConstants(q.Constants)
+		return query
+	}
+	recipient := builderPrincipalName(q.Recipient)
+	query.Message = vplogic.Message{
+		Sender:     builderPrincipalName(q.Sender),
+		Recipient:  recipient,
+		Recipients: []string{recipient},
+		Constants:  builderConstants(q.Constants),
+	}
+	return query
+}
+
+func convertResult(r vplogic.VerifyResult) Result {
+	result := Result{
+		Query:    convertQuery(r.Query),
+		Resolved: r.Resolved,
+		Summary:  r.Summary,
+		Attacks:  []Attack{},
+	}
+	for _, a := range r.Attacks {
+		result.Attacks = append(result.Attacks, Attack{
+			Mutated:    a.Mutated,
+			Minimal:    a.Minimal,
+			Derivation: a.Derivation,
+			Summary:    a.Summary,
+		})
+	}
+	return result
+}
+
+func convertResults(r []vplogic.VerifyResult) []Result {
+	results := make([]Result, len(r))
+	for i, rr := range r {
+		results[i] = convertResult(rr)
+	}
+	return results
+}
+
+func convertEstimate(e vplogic.EstimateResult) EstimateResult {
+	estimate := EstimateResult{
+		Stages:       make([]EstimateStage, len(e.Stages)),
+		Primitives:   make([]EstimatePrimitive, len(e.Primitives)),
+		Dominant:     make([]EstimateValue, len(e.Dominant)),
+		Combinations: e.Combinations,
+		Cost:         e.Cost,
+	}
+	for i, s := range e.Stages {
+		estimate.Stages[i] = EstimateStage{
+			Phase:        s.Phase,
+			Stage:        s.Stage,
+			Principal:    s.Principal,
+			Values:       s.Values,
+			Combinations: s.Combinations,
+		}
+	}
+	for i, p := range e.Primitives {
+		estimate.Primitives[i] = EstimatePrimitive{
+			Name:  p.Name,
+			Arity: p.Arity,
+			Count: p.Count,
+		}
+	}
+	for i, v := range e.Dominant {
+		estimate.Dominant[i] = EstimateValue{
+			Principal: v.Principal,
+			Constant:  v.Constant.Name,
+			Phase:     v.Phase,
+			Stage:     v.Stage,
+			Mutations: v.Mutations,
+		}
+	}
+	return estimate
+}
+
+// convertEventSink passes the engine's events on to an EventSink, leaving
+// out those that this package does not expose.
+type convertEventSink struct {
+	sink EventSink
+}
+
+func (s convertEventSink) Event(e vplogic.Event) {
+	switch ev := e.(type) {
+	case vplogic.EventPhaseStarted:
+		s.sink.Event(EventPhaseStarted{Phase: ev.Phase, Active: ev.Active})
+	case vplogic.EventStageStarted:
+		s.sink.Event(EventStageStarted{Stage: ev.Stage})
+	case vplogic.EventAnalysis:
+		s.sink.Event(EventAnalysis{Stage: ev.Stage, Analysis: ev.Analysis})
+	case vplogic.EventQueryResolved:
+		s.sink.Event(EventQueryResolved{
+			Result:   convertResult(ev.Result),
+			Analysis: ev.Analysis,
+		})
+	case vplogic.EventVerificationFinished:
+		s.sink.Event(EventVerificationFinished{
+			Results:     convertResults(ev.Results),
+			ResultsCode: ev.ResultsCode,
+			Pruned:      ev.Pruned,
+		})
+	case vplogic.EventMessage:
+		s.sink.Event(EventMessage{
+			Kind:     ev.Kind,
+			Message:  ev.Message,
+			Analysis: ev.Analysis,
+		})
+	}
+}
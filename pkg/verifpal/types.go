@@ -0,0 +1,182 @@
+/* SPDX-FileCopyrightText: © 2019-2020 Nadim Kobeissi <nadim@symbolic.software>
+ * SPDX-License-Identifier: GPL-3.0-only */
+// 00000000000000000000000000000000
+
+package verifpal
+
+import (
+	"verifpal.com/cmd/vplogic"
+)
+
+// Model is a Verifpal model, as parsed from its source text or assembled
+// using a Builder. Its contents are only exposed through Format, so that
+// they may be represented differently by future versions of Verifpal.
+type Model struct {
+	model vplogic.Model
+}
+
+// Value is a constant, primitive or equation, as returned by Const, Prim
+// and Exp.
+type Value struct {
+	value vplogic.Value
+}
+
+// QueryKind tells apart the different kinds of Query.
+type QueryKind uint8
+
+const (
+	QueryKindConfidentiality QueryKind = iota + 1
+	QueryKindAuthentication
+	QueryKindFreshness
+	QueryKindUnlinkability
+)
+
+var queryKindNames = []string{"", "confidentiality", "authentication", "freshness", "unlinkability"}
+
+func (k QueryKind) String() string {
+	if int(k) < len(queryKindNames) {
+		return queryKindNames[k]
+	}
+	return ""
+}
+
+// Query is a security query asked of a model.
+type Query struct {
+	Kind QueryKind
+	// Constants are the constants that the query is about. Authentication
+	// queries are about the single constant sent from Sender to Recipient.
+	Constants []string
+	Sender    string
+	Recipient string
+}
+
+// Result is the outcome of a single query.
+type Result struct {
+	Query Query
+	// Resolved is set when the query was contradicted, such as when the
+	// attacker was found to obtain a constant asked about for confidentiality.
+	Resolved bool
+	Summary  string
+	Attacks  []Attack
+}
+
+// Attack is one way in which a query was found to be contradicted.
+type Attack struct {
+	// Mutated lists each value that the attacker replaced, as "a → b".
+	Mutated []string
+	// Minimal lists the fewest of these replacements with which the query
+	// is still contradicted.
+	Minimal    []string
+	Derivation string
+	Summary    string
+}
+
+// Results holds the outcome of every query of a model, in the order in
+// which the queries are declared, along with the results code that the
+// Verifpal test suite uses to summarize them, such as "c0a1".
+type Results struct {
+	Results []Result
+	Code    string
+}
+
+// EstimateResult describes the search space that active analysis of a model
+// starts out with.
+type EstimateResult struct {
+	Stages     []EstimateStage
+	Primitives []EstimatePrimitive
+	// Dominant lists the values that can be replaced in the most ways,
+	// starting with the value that can be replaced in the most ways.
+	Dominant     []EstimateValue
+	Combinations float64
+	// Cost is a rough class of how long analysis will take, such as
+	// "seconds" or "hours".
+	Cost string
+}
+
+// EstimateStage gives the number of values that a principal starts out
+// with that the attacker may mutate, at a given phase and stage.
+type EstimateStage struct {
+	Phase        int
+	Stage        int
+	Principal    string
+	Values       int
+	Combinations float64
+}
+
+// EstimatePrimitive counts the uses of an injectable primitive with a
+// given number of arguments.
+type EstimatePrimitive struct {
+	Name  string
+	Arity int
+	Count int
+}
+
+// EstimateValue gives the number of values that a value received by a
+// principal may be replaced with.
+type EstimateValue struct {
+	Principal string
+	Constant  string
+	Phase     int
+	Stage     int
+	Mutations int
+}
+
+// EventSink receives the events emitted during verification, such as the
+// start of each phase and each query being resolved.
+type EventSink interface {
+	Event(e Event)
+}
+
+// Event is one of the event types that an EventSink may receive.
+type Event interface {
+	event()
+}
+
+// EventPhaseStarted is emitted as the analysis of each phase begins, with
+// Active set when the attacker is active.
+type EventPhaseStarted struct {
+	Phase  int
+	Active bool
+}
+
+// EventStageStarted is emitted as each stage of active analysis begins.
+type EventStageStarted struct {
+	Stage int
+}
+
+// EventAnalysis is emitted as analysis proceeds, with the number of
+// principal states analyzed so far.
+type EventAnalysis struct {
+	Stage    int
+	Analysis int
+}
+
+// EventQueryResolved is emitted once a query is contradicted.
+type EventQueryResolved struct {
+	Result   Result
+	Analysis int
+}
+
+// EventVerificationFinished is emitted once every query has been decided,
+// with Pruned counting the principal states skipped as equivalent to ones
+// already analyzed.
+type EventVerificationFinished struct {
+	Results     []Result
+	ResultsCode string
+	Pruned      int
+}
+
+// EventMessage carries any other message of the analysis log, with Kind
+// naming its kind, such as "deduction" or "warning".
+type EventMessage struct {
+	Kind     string
+	Message  string
+	Analysis int
+}
+
+func (EventPhaseStarted) event()         {}
+func (EventStageStarted) event()         {}
+func (EventAnalysis) event()             {}
+func (EventQueryResolved) event()        {}
+func (EventVerificationFinished) event() {}
+func (EventMessage) event()              {}
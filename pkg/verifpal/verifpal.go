@@ -0,0 +1,97 @@
+/* SPDX-FileCopyrightText: © 2019-2020 Nadim Kobeissi <nadim@symbolic.software>
+ * SPDX-License-Identifier: GPL-3.0-only */
+// 00000000000000000000000000000000
+
+// Package verifpal lets Go programs build, check, verify and format
+// Verifpal models without going through model files or the command line.
+package verifpal
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"verifpal.com/cmd/vplogic"
+)
+
+// Options configures a call to Verify.
+type Options struct {
+	// Output receives the same analysis log that `verifpal verify` prints.
+	// The log is discarded when Output is nil.
+	Output io.Writer
+	// Events, when set, receives every event emitted during verification.
+	Events EventSink
+	// Exhaustive keeps the analysis going after each query is resolved,
+	// so that every distinct attack is listed in the result's Attacks.
+	Exhaustive bool
+}
+
+type verifySinks []vplogic.EventSink
+
+func (sinks verifySinks) Event(e vplogic.Event) {
+	for _, sink := range sinks {
+		sink.Event(e)
+	}
+}
+
+// The verification engine keeps its state in package-level variables, so
+// only one model can be verified at a time.
+var verifyMutex sync.Mutex
+
+// Parse parses a model from its source text, as found in a model file.
+func Parse(src []byte) (Model, error) {
+	m, err := vplogic.ParseModel("model.vp", src)
+	return Model{model: m}, err
+}
+
+// Check reports the first problem that would prevent a model from being
+// verified, such as a constant used before it is declared.
+func Check(m Model) error {
+	return vplogic.SanityCheck(m.model)
+}
+
+// Verify analyzes a model and returns the outcome of each of its queries.
+// If ctx is done before the analysis completes, Verify stops early and
+// returns the context's error. Calls to Verify are serialized.
+func Verify(ctx context.Context, m Model, opts Options) (Results, error) {
+	verifyMutex.Lock()
+	defer verifyMutex.Unlock()
+	sinks := verifySinks{}
+	if opts.Output != nil {
+		sinks = append(sinks, vplogic.TerminalSink{Output: opts.Output})
+	}
+	if opts.Events != nil {
+		sinks = append(sinks, convertEventSink{sink: opts.Events})
+	}
+	eventSinkSaved := vplogic.SetEventSink(sinks)
+	defer vplogic.SetEventSink(eventSinkSaved)
+	vplogic.VerifyExhaustiveShared = opts.Exhaustive
+	defer func() { vplogic.VerifyExhaustiveShared = false }()
+	results, code, err := vplogic.VerifyModel(ctx, m.model)
+	if err != nil {
+		return Results{}, err
+	}
+	return Results{
+		Results: convertResults(results),
+		Code:    code,
+	}, nil
+}
+
+// Estimate reports the size of the search space that active analysis of
+// a model starts out with, without analyzing it, as by `verifpal estimate`.
+// It shares the verification engine's state, so it is serialized with Verify.
+func Estimate(m Model) (EstimateResult, error) {
+	verifyMutex.Lock()
+	defer verifyMutex.Unlock()
+	valEstimate, err := vplogic.EstimateModel(m.model)
+	if err != nil {
+		return EstimateResult{}, err
+	}
+	return convertEstimate(valEstimate), nil
+}
+
+// Format returns the source text of a model, formatted in the same way as
+// by `verifpal pretty`.
+func Format(m Model) (string, error) {
+	return vplogic.PrettyModel(m.model)
+}
@@ -0,0 +1,295 @@
+/* SPDX-FileCopyrightText: © 2019-2020 Nadim Kobeissi <nadim@symbolic.software>
+ * SPDX-License-Identifier: GPL-3.0-only */
+// 00000000000000000000000000000000
+
+package verifpal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"verifpal.com/cmd/vplogic"
+)
+
+func builderSignedMessage(leak bool) *Builder {
+	b := NewBuilder("active")
+	alice := b.Principal("Alice").
+		Knows("private", "sk").
+		Generates("m").
+		Assign("pk", Exp(G, Const("sk"))).
+		Assign("s", Prim("SIGN", Const("sk"), Const("m")))
+	if leak {
+		alice.Leaks("sk")
+	}
+	b.Send("Alice", "Bob", "[pk]", "m", "s")
+	b.Principal("Bob").
+		AssignOutputs([]string{"_"}, Checked(Prim("SIGNVERIF", Const("pk"), Const("m"), Const("s"))))
+	return b.
+		Confidentiality("sk").
+		Authentication("Alice", "Bob", "m")
+}
+
+func TestBuilder(t *testing.T) {
+	tests := []struct {
+		leak        bool
+		resultsCode string
+	}{
+		{false, "c0a0"},
+		{true, "c1a1"},
+	}
+	for _, test := range tests {
+		m, err := builderSignedMessage(test.leak).Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		results, err := Verify(context.Background(), m, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if results.Code != test.resultsCode {
+			t.Errorf("leak: %v, expected %s, got %s", test.leak, test.resultsCode, results.Code)
+		}
+		if len(results.Results) != 2 || results.Results[0].Query.Kind != QueryKindConfidentiality {
+			t.Errorf("leak: %v, unexpected results %v", test.leak, results.Results)
+		}
+		if q := results.Results[1].Query; q.Sender != "Alice" || q.Recipient != "Bob" || q.Constants[0] != "m" {
+			t.Errorf("leak: %v, unexpected authentication query %+v", test.leak, q)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	src, err := ioutil.ReadFile(filepath.Join("..", "..", "examples", "test", "signal_small.vp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	formatted, err := Format(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mm, err := Parse([]byte(formatted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reformatted, err := Format(mm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if formatted != reformatted {
+		t.Errorf("formatting is not stable:\n%s\n%s", formatted, reformatted)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	b := NewBuilder("active")
+	b.Principal("Alice").Assign("h", Prim("HASH", Const("x")))
+	b.Confidentiality("h")
+	if _, err := b.Build(); err == nil {
+		t.Error("expected an error for an undeclared constant")
+	}
+	m, err := Parse([]byte("attacker[active]\nprincipal Alice[generates x]\nqueries[confidentiality? y]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Check(m) == nil {
+		t.Error("expected an error for a query on an undeclared constant")
+	}
+}
+
+func TestVerifyCancel(t *testing.T) {
+	m, err := builderSignedMessage(false).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Verify(ctx, m, Options{}); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestVerifyOutput(t *testing.T) {
+	tests := []struct {
+		attacker string
+		phase    bool
+	}{
+		{"active", true},
+		{"passive", false},
+	}
+	for _, test := range tests {
+		m, err := Parse([]byte(fmt.Sprintf(
+			"attacker[%s]\nprincipal Alice[generates x]\nprincipal Bob[knows public y]\nAlice -> Bob: x\nqueries[confidentiality? x]\n",
+			test.attacker,
+		)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sink := vplogic.TerminalSink{Output: ioutil.Discard}
+		eventSinkSaved := vplogic.SetEventSink(sink)
+		output := &bytes.Buffer{}
+		_, err = Verify(context.Background(), m, Options{Output: output})
+		if vplogic.SetEventSink(eventSinkSaved) != sink {
+			t.Errorf("%s: expected the previous event sink to be restored", test.attacker)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(output.String(), "Running at phase") != test.phase {
+			t.Errorf("%s: unexpected analysis log:\n%s", test.attacker, output)
+		}
+	}
+}
+
+type testEventSink struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (s *testEventSink) Event(e Event) {
+	s.mu.Lock()
+	s.events = append(s.events, e)
+	s.mu.Unlock()
+}
+
+func TestVerifyEvents(t *testing.T) {
+	m, err := builderSignedMessage(true).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := &testEventSink{}
+	if _, err := Verify(context.Background(), m, Options{Events: sink}); err != nil {
+		t.Fatal(err)
+	}
+	phases, resolved := 0, 0
+	for _, e := range sink.events {
+		switch e.(type) {
+		case EventPhaseStarted:
+			phases++
+		case EventQueryResolved:
+			resolved++
+		}
+	}
+	if phases != 1 || resolved != 2 {
+		t.Errorf("expected 1 phase and 2 resolved queries, got %d and %d", phases, resolved)
+	}
+	finished, ok := sink.events[len(sink.events)-1].(EventVerificationFinished)
+	if !ok || finished.ResultsCode != "c1a1" {
+		t.Errorf("expected verification to finish with c1a1, got %v", sink.events[len(sink.events)-1])
+	}
+}
+
+func TestVerifyExhaustive(t *testing.T) {
+	src, err := ioutil.ReadFile(filepath.Join("..", "..", "examples", "test", "challengeresponse.vp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := Verify(context.Background(), m, Options{Exhaustive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results.Code != "a0a1" {
+		t.Errorf("expected a0a1, got %s", results.Code)
+	}
+	attacks := results.Results[1].Attacks
+	if len(attacks) < 2 {
+		t.Fatalf("expected several attacks, got %d", len(attacks))
+	}
+	for i := 1; i < len(attacks); i++ {
+		if len(attacks[i].Mutated) < len(attacks[i-1].Mutated) {
+			t.Errorf("attacks are not ranked from simplest: %v", attacks)
+		}
+	}
+	if results.Results[1].Summary != attacks[0].Summary {
+		t.Error("expected the summary to describe the simplest attack")
+	}
+}
+
+func TestVerifyPruned(t *testing.T) {
+	src, err := ioutil.ReadFile(filepath.Join("..", "..", "examples", "test", "challengeresponse.vp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := &testEventSink{}
+	results, err := Verify(context.Background(), m, Options{Events: sink})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results.Code != "a0a1" {
+		t.Errorf("expected a0a1, got %s", results.Code)
+	}
+	finished, ok := sink.events[len(sink.events)-1].(EventVerificationFinished)
+	if !ok || finished.Pruned == 0 {
+		t.Errorf("expected equivalent mutations to be pruned, got %v", sink.events[len(sink.events)-1])
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	src, err := ioutil.ReadFile(filepath.Join("..", "..", "examples", "test", "challengeresponse.vp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	estimate, err := Estimate(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if estimate.Cost != "seconds" || estimate.Combinations == 0 || len(estimate.Stages) != 8 {
+		t.Errorf("unexpected estimate: %+v", estimate)
+	}
+	if len(estimate.Primitives) != 1 || estimate.Primitives[0].Name != "SIGN" {
+		t.Errorf("expected SIGN to be the only injectable primitive, got %+v", estimate.Primitives)
+	}
+	if len(estimate.Dominant) == 0 || estimate.Dominant[0].Mutations < estimate.Dominant[len(estimate.Dominant)-1].Mutations {
+		t.Errorf("dominant values are not ranked: %+v", estimate.Dominant)
+	}
+}
+
+// In hmac_unchecked_assert.vp, Bob receives ciphertext and then tag, and
+// every combination of mutations that is analyzed replaces the last of the
+// values that Bob receives. The attack on ciphertext is therefore always
+// found with tag replaced as well, although replacing ciphertext suffices.
+func TestVerifyMinimalAttack(t *testing.T) {
+	src, err := ioutil.ReadFile(filepath.Join("..", "..", "examples", "test", "hmac_unchecked_assert.vp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := Verify(context.Background(), m, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	attack := results.Results[1].Attacks[0]
+	if len(attack.Mutated) != 2 {
+		t.Fatalf("expected ciphertext and tag to be mutated, got %v", attack.Mutated)
+	}
+	if len(attack.Minimal) != 1 || !strings.HasPrefix(attack.Minimal[0], "ciphertext →") {
+		t.Errorf("expected only ciphertext to be needed, got %v", attack.Minimal)
+	}
+	if !strings.Contains(results.Results[1].Summary, "controlling only the following") {
+		t.Error("expected the summary to report the minimal attack")
+	}
+}